@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,6 +23,9 @@ type DBConfig struct {
 type AppConfig struct {
 	ApplicatonName string
 	AppPort        string
+	AppEnv         string
+	BaseCurrency   string
+	TaxCountry     string
 }
 
 type ApiConfig struct {
@@ -28,10 +34,57 @@ type ApiConfig struct {
 	AccessTokenLifeTime int
 }
 
+type StorageConfig struct {
+	StorageDriver    string
+	StorageLocalDir  string
+	StoragePublicURL string
+	S3Endpoint       string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKey      string
+	S3SecretKey      string
+}
+
+type PaymentConfig struct {
+	RefundProvider string
+}
+
+type InvoiceConfig struct {
+	InvoiceDir    string
+	SellerName    string
+	SellerAddress string
+	SellerTaxID   string
+}
+
+type ReturnConfig struct {
+	ReturnPhotoDir string
+}
+
+type CatalogConfig struct {
+	BackfillStock int
+}
+
+type CartConfig struct {
+	CartTTL          time.Duration
+	GuestCartTTL     time.Duration
+	CartCookieSecure bool
+}
+
+type OrderConfig struct {
+	PendingOrderTTL time.Duration
+}
+
 type Config struct {
 	DBConfig
 	AppConfig
 	ApiConfig
+	StorageConfig
+	PaymentConfig
+	InvoiceConfig
+	ReturnConfig
+	CatalogConfig
+	CartConfig
+	OrderConfig
 }
 
 func (cfg *Config) loadConfig() error {
@@ -43,6 +96,9 @@ func (cfg *Config) loadConfig() error {
 	cfg.AppConfig = AppConfig{
 		ApplicatonName: os.Getenv("APP_NAME"),
 		AppPort:        os.Getenv("APP_PORT"),
+		AppEnv:         os.Getenv("APP_ENV"),
+		BaseCurrency:   strings.ToUpper(os.Getenv("BASE_CURRENCY")),
+		TaxCountry:     strings.ToUpper(os.Getenv("TAX_COUNTRY")),
 	}
 
 	cfg.DBConfig = DBConfig{
@@ -60,6 +116,111 @@ func (cfg *Config) loadConfig() error {
 		AccessTokenLifeTime: 24,
 	}
 
+	cfg.StorageConfig = StorageConfig{
+		StorageDriver:    os.Getenv("STORAGE_DRIVER"),
+		StorageLocalDir:  os.Getenv("STORAGE_LOCAL_DIR"),
+		StoragePublicURL: os.Getenv("STORAGE_PUBLIC_URL"),
+		S3Endpoint:       os.Getenv("S3_ENDPOINT"),
+		S3Region:         os.Getenv("S3_REGION"),
+		S3Bucket:         os.Getenv("S3_BUCKET"),
+		S3AccessKey:      os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:      os.Getenv("S3_SECRET_KEY"),
+	}
+
+	cfg.PaymentConfig = PaymentConfig{
+		RefundProvider: os.Getenv("REFUND_PROVIDER"),
+	}
+
+	backfillStock, err := strconv.Atoi(os.Getenv("BACKFILL_VARIANT_STOCK"))
+	if err != nil || backfillStock < 0 {
+		backfillStock = 100
+	}
+
+	cfg.CatalogConfig = CatalogConfig{
+		BackfillStock: backfillStock,
+	}
+
+	cartTTL, err := time.ParseDuration(os.Getenv("CART_TTL"))
+	if err != nil || cartTTL <= 0 {
+		cartTTL = 30 * 24 * time.Hour
+	}
+
+	guestCartTTL, err := time.ParseDuration(os.Getenv("GUEST_CART_TTL"))
+	if err != nil || guestCartTTL <= 0 {
+		guestCartTTL = 7 * 24 * time.Hour
+	}
+
+	// The guest cart cookie is only sent over HTTPS unless said otherwise,
+	// or when running in development.
+	cartCookieSecure, err := strconv.ParseBool(os.Getenv("CART_COOKIE_SECURE"))
+	if err != nil {
+		cartCookieSecure = cfg.AppEnv != "development"
+	}
+
+	cfg.CartConfig = CartConfig{
+		CartTTL:          cartTTL,
+		GuestCartTTL:     guestCartTTL,
+		CartCookieSecure: cartCookieSecure,
+	}
+
+	// Orders not paid within this time are cancelled and their stock given
+	// back.
+	pendingOrderTTL, err := time.ParseDuration(os.Getenv("PENDING_ORDER_TTL"))
+	if err != nil || pendingOrderTTL <= 0 {
+		pendingOrderTTL = time.Hour
+	}
+
+	cfg.OrderConfig = OrderConfig{
+		PendingOrderTTL: pendingOrderTTL,
+	}
+
+	cfg.InvoiceConfig = InvoiceConfig{
+		InvoiceDir:    os.Getenv("INVOICE_DIR"),
+		SellerName:    os.Getenv("SELLER_NAME"),
+		SellerAddress: os.Getenv("SELLER_ADDRESS"),
+		SellerTaxID:   os.Getenv("SELLER_TAX_ID"),
+	}
+
+	if cfg.InvoiceDir == "" {
+		cfg.InvoiceDir = "invoices"
+	}
+
+	cfg.ReturnConfig = ReturnConfig{
+		ReturnPhotoDir: os.Getenv("RETURN_PHOTO_DIR"),
+	}
+
+	if cfg.ReturnPhotoDir == "" {
+		cfg.ReturnPhotoDir = "return-photos"
+	}
+
+	if cfg.SellerName == "" {
+		cfg.SellerName = cfg.ApplicatonName
+	}
+
+	if cfg.RefundProvider == "" {
+		cfg.RefundProvider = "fake"
+	}
+
+	if cfg.BaseCurrency == "" {
+		cfg.BaseCurrency = "IDR"
+	}
+
+	if cfg.TaxCountry == "" {
+		cfg.TaxCountry = "ID"
+	}
+
+	if cfg.StorageDriver == "" {
+		cfg.StorageDriver = "local"
+	}
+
+	if cfg.StorageLocalDir == "" {
+		cfg.StorageLocalDir = "uploads"
+	}
+
+	if cfg.StorageDriver == "local" && cfg.StoragePublicURL == "" {
+		cfg.StoragePublicURL = "/uploads"
+	}
+
 	if cfg.Host == "" || cfg.Port == "" || cfg.Database == "" || cfg.Username == "" || cfg.Password == "" || cfg.AppPort == "" || cfg.JwtSignatureKey == "" {
 		fmt.Println("config .env is required")
 	}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type addressController struct {
+	addressUsecase usecase.AddressUsecase
+}
+
+func (ac *addressController) CreateAddress(ctx *gin.Context) {
+	var req dto.CreateAddressRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	address, err := ac.addressUsecase.Create(ctx.GetInt("user_id"), req)
+	if ac.abortWithAddressError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created address",
+		Data:    address,
+	})
+}
+
+func (ac *addressController) GetAddresses(ctx *gin.Context) {
+	addresses, err := ac.addressUsecase.GetByUser(ctx.GetInt("user_id"))
+	if ac.abortWithAddressError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get all addresses",
+		Data:    addresses,
+	})
+}
+
+func (ac *addressController) GetAddressById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	address, err := ac.addressUsecase.GetById(id, ctx.GetInt("user_id"))
+	if ac.abortWithAddressError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get address",
+		Data:    address,
+	})
+}
+
+func (ac *addressController) UpdateAddress(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.UpdateAddressRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	address, err := ac.addressUsecase.Update(id, ctx.GetInt("user_id"), req)
+	if ac.abortWithAddressError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated address",
+		Data:    address,
+	})
+}
+
+func (ac *addressController) DeleteAddress(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = ac.addressUsecase.Delete(id, ctx.GetInt("user_id"))
+	if ac.abortWithAddressError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted address",
+	})
+}
+
+func (ac *addressController) SetDefaultAddress(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	address, err := ac.addressUsecase.SetDefault(id, ctx.GetInt("user_id"))
+	if ac.abortWithAddressError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully set default address",
+		Data:    address,
+	})
+}
+
+// abortWithAddressError maps the address usecase errors to a status code and
+// reports whether the request was aborted.
+func (ac *addressController) abortWithAddressError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrAddressNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewAddressController(au usecase.AddressUsecase, rg *gin.RouterGroup) *addressController {
+	controller := &addressController{addressUsecase: au}
+
+	rg.GET("/address", controller.GetAddresses)
+	rg.POST("/address", controller.CreateAddress)
+	rg.GET("/address/:id", controller.GetAddressById)
+	rg.PUT("/address/:id", controller.UpdateAddress)
+	rg.DELETE("/address/:id", controller.DeleteAddress)
+	rg.PUT("/address/:id/default", controller.SetDefaultAddress)
+
+	return controller
+}
@@ -1,10 +1,12 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
 	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
 	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
 )
@@ -12,6 +14,7 @@ import (
 type AuthController struct {
 	authUsecase usecase.AuthUsecase
 	validate *validator.Validate
+	secureCookie bool
 }
 
 func (ac *AuthController) Login(ctx *gin.Context) {
@@ -19,20 +22,28 @@ func (ac *AuthController) Login(ctx *gin.Context) {
 
 	err := ctx.ShouldBindJSON(&reqLogin)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		helper.AbortWithBindError(ctx, err)
 		return
 	}
 
 	err = ac.validate.Struct(reqLogin)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		helper.AbortWithBindError(ctx, err)
 		return
 	}
 
-	resp, err := ac.authUsecase.Login(reqLogin)
-	if err != nil {
+	cartToken := cartToken(ctx)
+	resp, err := ac.authUsecase.Login(ctx.Request.Context(), reqLogin, cartToken)
+	if errors.Is(err, usecase.ErrInvalidCredentials) {
 		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{Error: err.Error()})
 		return
+	} else if abortWithCartError(ctx, err) {
+		return
+	}
+
+	// the guest cart now lives in the user's cart
+	if cartToken != "" {
+		ctx.SetCookie(cartTokenCookie, "", -1, "/", "", ac.secureCookie, true)
 	}
 
 	ctx.JSON(http.StatusOK, dto.GeneralResponse{
@@ -41,10 +52,11 @@ func (ac *AuthController) Login(ctx *gin.Context) {
 	})
 }
 
-func NewAuthController(authUsecase usecase.AuthUsecase, rg *gin.RouterGroup) {
+func NewAuthController(authUsecase usecase.AuthUsecase, rg *gin.RouterGroup, secureCookie bool) {
 	authController :=  &AuthController{
 		authUsecase: authUsecase,
 		validate: validator.New(),
+		secureCookie: secureCookie,
 	}
 
 	rg.POST("/login", authController.Login)
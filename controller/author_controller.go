@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type authorController struct {
+	authorUsecase usecase.AuthorUsecase
+}
+
+func (ac *authorController) CreateAuthor(ctx *gin.Context) {
+	var req dto.CreateAuthorRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	createAuthor, err := ac.authorUsecase.Create(req)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created author",
+		Data: createAuthor,
+	})
+}
+
+func (ac *authorController) GetAllAuthors(ctx *gin.Context) {
+	authors, err := ac.authorUsecase.GetAll()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get all authors",
+		Data: authors,
+	})
+}
+
+func (ac *authorController) GetAuthorById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	author, err := ac.authorUsecase.GetById(id)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get author by id",
+		Data: author,
+	})
+}
+
+func (ac *authorController) GetAuthorBooks(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var filter dto.BookFilterRequest
+	err = ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	filter.Currency = ctx.GetString("currency")
+	books, paging, err := ac.authorUsecase.GetBooks(id, filter)
+	if errors.Is(err, usecase.ErrAuthorNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PagedResponse{
+		Message: "successfully get books by author",
+		Data: books,
+		Paging: *paging,
+	})
+}
+
+func (ac *authorController) UpdateAuthor(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var reqUpdate dto.UpdateAuthorRequest
+	err = ctx.ShouldBindJSON(&reqUpdate)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	updateAuthor, err := ac.authorUsecase.Update(id, &reqUpdate)
+	if errors.Is(err, usecase.ErrAuthorNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated author",
+		Data: updateAuthor,
+	})
+}
+
+func (ac *authorController) DeleteAuthor(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = ac.authorUsecase.Delete(id)
+	if errors.Is(err, usecase.ErrAuthorNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if errors.Is(err, usecase.ErrAuthorInUse) {
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted author",
+	})
+}
+
+func NewAuthorController(au usecase.AuthorUsecase, rg *gin.RouterGroup) *authorController {
+	controller := &authorController{authorUsecase: au}
+
+	// public routes
+	rg.GET("/author", controller.GetAllAuthors)
+	rg.GET("/author/:id", controller.GetAuthorById)
+	rg.GET("/author/:id/books", controller.GetAuthorBooks)
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin", "seller"))
+
+	protected.POST("/author", controller.CreateAuthor)
+	protected.PUT("/author/:id", controller.UpdateAuthor)
+	protected.DELETE("/author/:id", controller.DeleteAuthor)
+
+	return controller
+}
@@ -1,10 +1,16 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"path"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
 	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
 	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
 	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
@@ -19,12 +25,14 @@ func (bc *bookController) CreateBook(ctx *gin.Context) {
 
 	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		helper.AbortWithBindError(ctx, err)
 		return
 	}
 
 	createBook, err := bc.bookUsecase.Create(req)
-	if err != nil {
+	if bc.abortWithReferenceError(ctx, err) {
+		return
+	} else if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -36,18 +44,92 @@ func (bc *bookController) CreateBook(ctx *gin.Context) {
 }
 
 func (bc *bookController) GetAllBook(ctx *gin.Context) {
-	books, err := bc.bookUsecase.GetAll()
+	var filter dto.BookFilterRequest
+	err := ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	filter.Currency = ctx.GetString("currency")
+	_, paged := ctx.GetQuery("page")
+	_, limited := ctx.GetQuery("limit")
+	filter.Unpaged = !paged && !limited
+
+	books, paging, err := bc.bookUsecase.GetAll(filter)
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+	if paging == nil {
+		ctx.JSON(http.StatusOK, dto.GeneralResponse{
+			Message: "successfully get all books",
+			Data: books,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PagedResponse{
 		Message: "successfully get all books",
 		Data: books,
+		Paging: *paging,
 	})
 }
 
+// ExportBooks streams the books matching the listing filters as a file
+// download. Rows are flushed to the client as they are read, so large
+// catalogs start downloading right away.
+func (bc *bookController) ExportBooks(ctx *gin.Context) {
+	var req dto.BookExportRequest
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	exporter, err := helper.NewBookExporter(req.Format, ctx.Writer, ctx.Request.Host)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Type", exporter.ContentType())
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="books-%s%s"`, time.Now().Format("20060102"), exporter.FileExtension()))
+
+	req.Currency = ctx.GetString("currency")
+	written := 0
+	err = bc.bookUsecase.Export(req.BookFilterRequest, func(row dto.BookExportRow) error {
+		err := exporter.Write(row)
+		if err != nil {
+			return err
+		}
+
+		written++
+		if written%100 == 0 {
+			ctx.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = exporter.Close()
+	}
+
+	if err != nil {
+		if !ctx.Writer.Written() {
+			ctx.Writer.Header().Del("Content-Disposition")
+			ctx.Writer.Header().Del("Content-Type")
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		// the status line is already out, so the download just ends early
+		log.Printf("book export stopped after %d rows: %v\n", written, err)
+		ctx.Error(err)
+		ctx.Abort()
+	}
+}
+
 func (bc *bookController) GetBookById(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
@@ -55,7 +137,7 @@ func (bc *bookController) GetBookById(ctx *gin.Context) {
 		return
 	}
 
-	book, err := bc.bookUsecase.GetById(id)
+	book, err := bc.bookUsecase.GetById(id, ctx.GetString("currency"))
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
 		return
@@ -67,6 +149,39 @@ func (bc *bookController) GetBookById(ctx *gin.Context) {
 	})
 }
 
+func (bc *bookController) GetBookBySlug(ctx *gin.Context) {
+	book, err := bc.bookUsecase.GetBySlug(ctx.Param("slug"), ctx.GetString("currency"))
+	var moved *usecase.SlugMovedError
+	if errors.As(err, &moved) {
+		ctx.Redirect(http.StatusMovedPermanently, path.Join(path.Dir(ctx.Request.URL.Path), moved.Slug))
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get book by slug",
+		Data: book,
+	})
+}
+
+func (bc *bookController) GetBookByIsbn(ctx *gin.Context) {
+	book, err := bc.bookUsecase.GetByIsbn(ctx.Param("isbn"), ctx.GetString("currency"))
+	if errors.Is(err, usecase.ErrInvalidIsbn) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get book by isbn",
+		Data: book,
+	})
+}
+
 func (bc *bookController) UpdateBook(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
@@ -77,13 +192,15 @@ func (bc *bookController) UpdateBook(ctx *gin.Context) {
 	var reqUpdate dto.UpdateBookRequest
 	err = ctx.ShouldBindJSON(&reqUpdate)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		helper.AbortWithBindError(ctx, err)
 		return
 	}
 
 	
 	updateBook, err := bc.bookUsecase.Update(id, reqUpdate)
-	if err != nil {
+	if bc.abortWithReferenceError(ctx, err) {
+		return
+	} else if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -112,20 +229,129 @@ func (bc *bookController) DeleteBook(ctx *gin.Context) {
 	})
 }
 
+func (bc *bookController) UploadCover(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// leave room for the multipart envelope around the file itself
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, usecase.MaxCoverSize+1<<20)
+
+	fileHeader, err := ctx.FormFile("cover")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: usecase.ErrCoverTooLarge.Error()})
+		return
+	} else if err != nil {
+		helper.AbortWithFieldError(ctx, "cover", "required", "cover is required")
+		return
+	}
+
+	if fileHeader.Size > usecase.MaxCoverSize {
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: usecase.ErrCoverTooLarge.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	book, err := bc.bookUsecase.UploadCover(id, file, ctx.GetString("currency"))
+	switch {
+	case errors.Is(err, usecase.ErrBookNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, usecase.ErrCoverTooLarge):
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, usecase.ErrCoverType):
+		ctx.AbortWithStatusJSON(http.StatusUnsupportedMediaType, dto.ErrorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, usecase.ErrCoverInvalid):
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, dto.ErrorResponse{Error: err.Error()})
+		return
+	case err != nil:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully uploaded book cover",
+		Data: book,
+	})
+}
+
+func (bc *bookController) DeleteCover(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = bc.bookUsecase.DeleteCover(id)
+	if errors.Is(err, usecase.ErrBookNotFound) || errors.Is(err, usecase.ErrNoCover) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted book cover",
+	})
+}
+
+// abortWithReferenceError answers 422 when the book points at a category,
+// author or publisher that does not exist or carries a bad ISBN, 409 when the
+// ISBN or a SKU belongs to another book, and reports whether it aborted.
+func (bc *bookController) abortWithReferenceError(ctx *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, usecase.ErrCategoryNotFound):
+		helper.AbortWithFieldError(ctx, "category_id", "category_exists", err.Error())
+	case errors.Is(err, usecase.ErrAuthorNotFound):
+		helper.AbortWithFieldError(ctx, "authors", "author_exists", err.Error())
+	case errors.Is(err, usecase.ErrAuthorName):
+		helper.AbortWithFieldError(ctx, "author", "author_name", err.Error())
+	case errors.Is(err, usecase.ErrPublisherNotFound):
+		helper.AbortWithFieldError(ctx, "publisher_id", "publisher_exists", err.Error())
+	case errors.Is(err, usecase.ErrInvalidIsbn):
+		helper.AbortWithFieldError(ctx, "isbn", "isbn_any", err.Error())
+	case errors.Is(err, usecase.ErrVariantPrices):
+		helper.AbortWithFieldError(ctx, "variants", "prices", err.Error())
+	case errors.Is(err, usecase.ErrDuplicateIsbn), errors.Is(err, usecase.ErrDuplicateSku):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	default:
+		return false
+	}
+
+	return true
+}
+
 func NewBookController(bu usecase.BookUsecase, rg *gin.RouterGroup) *bookController{
 	controller := &bookController{bookUsecase: bu}
 
 	// public routes
 	rg.GET("/book", controller.GetAllBook)
 	rg.GET("/book/:id", controller.GetBookById)
+	rg.GET("/book/by-slug/:slug", controller.GetBookBySlug)
+	rg.GET("/book/isbn/:isbn", controller.GetBookByIsbn)
 
 	// allowed roles routes
 	protected := rg.Group("")
 	protected.Use(middleware.RoleMiddleware("admin", "seller"))
 
+	protected.GET("/book/export", controller.ExportBooks)
 	protected.POST("/book", controller.CreateBook)
 	protected.PUT("/book/:id", controller.UpdateBook)
 	protected.DELETE("/book/:id", controller.DeleteBook)
+	protected.POST("/book/:id/cover", controller.UploadCover)
+	protected.DELETE("/book/:id/cover", controller.DeleteCover)
 
 	return controller
 }
\ No newline at end of file
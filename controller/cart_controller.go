@@ -0,0 +1,355 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+const (
+	cartTokenCookie = "cart_token"
+	cartTokenHeader = "X-Cart-Token"
+)
+
+type cartController struct {
+	cartUsecase usecase.CartUsecase
+	guestCartTTL time.Duration
+	secureCookie bool
+}
+
+func (cc *cartController) GetCart(ctx *gin.Context) {
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	cart, err := cc.cartUsecase.GetCartFromUser(ctx.Request.Context(), owner, ctx.GetString("currency"))
+	if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get cart",
+		Data: cart,
+	})
+}
+
+func (cc *cartController) AddToCart(ctx *gin.Context) {
+	var req dto.RequestAddToCart
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	cart, err := cc.cartUsecase.AddToCart(ctx.Request.Context(), owner, model.Item{
+		VariantId: req.VariantId,
+		Qty: req.Qty,
+	}, ctx.GetString("currency"))
+	if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully added item to cart",
+		Data: cart,
+	})
+}
+
+func (cc *cartController) UpdateQty(ctx *gin.Context) {
+	var req dto.RequestUpdateQtyFromItem
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	cart, err := cc.cartUsecase.UpdateQtyFromItem(ctx.Request.Context(), owner, req, ctx.GetString("currency"))
+	if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated cart item",
+		Data: cart,
+	})
+}
+
+func (cc *cartController) RemoveItem(ctx *gin.Context) {
+	variantId, err := strconv.Atoi(ctx.Param("variantId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	cart, err := cc.cartUsecase.RemoveItemFromCart(ctx.Request.Context(), owner, variantId, ctx.GetString("currency"))
+	if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully removed item from cart",
+		Data: cart,
+	})
+}
+
+func (cc *cartController) ClearCart(ctx *gin.Context) {
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	err := cc.cartUsecase.ClearAllItemFromCart(ctx.Request.Context(), owner)
+	if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully cleared cart",
+	})
+}
+
+func (cc *cartController) ApplyCoupon(ctx *gin.Context) {
+	var req dto.ApplyCouponRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	cart, err := cc.cartUsecase.ApplyCoupon(ctx.Request.Context(), owner, req, ctx.GetString("currency"))
+	if usecase.IsCouponRejection(err) {
+		helper.AbortWithFieldError(ctx, "code", "coupon", err.Error())
+		return
+	} else if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully applied coupon to cart",
+		Data: cart,
+	})
+}
+
+func (cc *cartController) RemoveCoupon(ctx *gin.Context) {
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	cart, err := cc.cartUsecase.RemoveCoupon(ctx.Request.Context(), owner, ctx.GetString("currency"))
+	if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully removed coupon from cart",
+		Data: cart,
+	})
+}
+
+func (cc *cartController) SetTaxLocation(ctx *gin.Context) {
+	var req dto.SetTaxLocationRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	cart, err := cc.cartUsecase.SetTaxLocation(ctx.Request.Context(), owner, req, ctx.GetString("currency"))
+	if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully set cart tax location",
+		Data: cart,
+	})
+}
+
+func (cc *cartController) SetAddress(ctx *gin.Context) {
+	var req dto.SetCartAddressRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	cart, err := cc.cartUsecase.SetAddress(ctx.Request.Context(), owner, req, ctx.GetString("currency"))
+	if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully set cart address",
+		Data: cart,
+	})
+}
+
+func (cc *cartController) SetShippingMethod(ctx *gin.Context) {
+	var req dto.SetShippingMethodRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	cart, err := cc.cartUsecase.SetShippingMethod(ctx.Request.Context(), owner, req, ctx.GetString("currency"))
+	if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully set cart shipping method",
+		Data: cart,
+	})
+}
+
+// ReviewCart confirms the customer has seen what changed in the cart.
+func (cc *cartController) ReviewCart(ctx *gin.Context) {
+	owner, ok := cc.cartOwner(ctx)
+	if !ok {
+		return
+	}
+
+	cart, err := cc.cartUsecase.ReviewCart(ctx.Request.Context(), owner, ctx.GetString("currency"))
+	if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully reviewed cart",
+		Data: cart,
+	})
+}
+
+// cartOwner is the signed in user or, for a guest, the cart token sent in
+// the cart_token cookie or X-Cart-Token header. A guest without a valid token
+// is handed a new one. The token goes back in both on every response, which
+// keeps the cookie alive as long as the cart.
+func (cc *cartController) cartOwner(ctx *gin.Context) (model.CartOwner, bool) {
+	userId := ctx.GetInt("user_id")
+	if userId != 0 {
+		return model.CartOwner{UserId: userId}, true
+	}
+
+	token := cartToken(ctx)
+	if token == "" {
+		var err error
+		token, err = helper.NewCartToken()
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "failed to create cart token"})
+			return model.CartOwner{}, false
+		}
+	}
+
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(cartTokenCookie, token, int(cc.guestCartTTL.Seconds()), "/", "", cc.secureCookie, true)
+	ctx.Header(cartTokenHeader, token)
+
+	return model.CartOwner{Token: token}, true
+}
+
+// cartToken reads the guest cart token from the cookie, or else the header,
+// and ignores one that is malformed.
+func cartToken(ctx *gin.Context) string {
+	token, err := ctx.Cookie(cartTokenCookie)
+	if err != nil || token == "" {
+		token = ctx.GetHeader(cartTokenHeader)
+	}
+
+	if !helper.ValidCartToken(token) {
+		return ""
+	}
+
+	return token
+}
+
+// abortWithCartError maps the errors a cart mutation can end in to a status
+// code and reports whether the request was aborted. It is shared with the
+// controllers that put items in the cart on the user's behalf.
+func abortWithCartError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrVariantNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrAddressNotFound), errors.Is(err, usecase.ErrShippingMethodNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrCartQtyLimit):
+		helper.AbortWithFieldError(ctx, "qty", "max", err.Error())
+	case errors.Is(err, usecase.ErrInsufficientStock), errors.Is(err, usecase.ErrCartBusy):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrGuestCart):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrShippingMethodUnavailable):
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, dto.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+// NewCartController registers the cart routes on rg, which lets guests
+// through; their cart cookie lasts as long as guestCartTTL and is only sent
+// over HTTPS when secureCookie is set.
+func NewCartController(cu usecase.CartUsecase, rg *gin.RouterGroup, guestCartTTL time.Duration, secureCookie bool) *cartController {
+	controller := &cartController{cartUsecase: cu, guestCartTTL: guestCartTTL, secureCookie: secureCookie}
+
+	rg.GET("/cart", controller.GetCart)
+	rg.POST("/cart/items", controller.AddToCart)
+	rg.PUT("/cart/items", controller.UpdateQty)
+	rg.DELETE("/cart/items/:variantId", controller.RemoveItem)
+	rg.DELETE("/cart", controller.ClearCart)
+	rg.POST("/cart/coupon", controller.ApplyCoupon)
+	rg.DELETE("/cart/coupon", controller.RemoveCoupon)
+	rg.PUT("/cart/tax-location", controller.SetTaxLocation)
+	rg.PUT("/cart/address", controller.SetAddress)
+	rg.PUT("/cart/shipping-method", controller.SetShippingMethod)
+	rg.PUT("/cart/review", controller.ReviewCart)
+
+	return controller
+}
@@ -1,10 +1,13 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"path"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
 	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
 	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
 	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
@@ -19,12 +22,15 @@ func (cc *categoryController) CreateCategory(ctx *gin.Context) {
 
 	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		helper.AbortWithBindError(ctx, err)
 		return
 	}
 
 	createCategory, err := cc.categoryUsecase.Create(req)
-	if err != nil {
+	if errors.Is(err, usecase.ErrCategoryParent) {
+		helper.AbortWithFieldError(ctx, "parent_id", "category_exists", err.Error())
+		return
+	} else if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -48,6 +54,19 @@ func (cc *categoryController) GetAllCategories(ctx *gin.Context) {
 	})
 }
 
+func (cc *categoryController) GetCategoryTree(ctx *gin.Context) {
+	tree, err := cc.categoryUsecase.GetTree()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get category tree",
+		Data: tree,
+	})
+}
+
 func (cc *categoryController) GetCategoryById(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
@@ -67,6 +86,54 @@ func (cc *categoryController) GetCategoryById(ctx *gin.Context) {
 	})
 }
 
+func (cc *categoryController) GetCategoryBySlug(ctx *gin.Context) {
+	category, err := cc.categoryUsecase.GetBySlug(ctx.Param("slug"))
+	var moved *usecase.SlugMovedError
+	if errors.As(err, &moved) {
+		ctx.Redirect(http.StatusMovedPermanently, path.Join(path.Dir(ctx.Request.URL.Path), moved.Slug))
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: "category not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get category by slug",
+		Data: category,
+	})
+}
+
+func (cc *categoryController) GetCategoryBooks(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var filter dto.BookFilterRequest
+	err = ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	filter.Currency = ctx.GetString("currency")
+	books, paging, err := cc.categoryUsecase.GetBooks(id, filter)
+	if errors.Is(err, usecase.ErrCategoryNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PagedResponse{
+		Message: "successfully get books by category",
+		Data: books,
+		Paging: *paging,
+	})
+}
+
 func (cc *categoryController) UpdateCategory(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
@@ -78,12 +145,21 @@ func (cc *categoryController) UpdateCategory(ctx *gin.Context) {
 
 	err = ctx.ShouldBindJSON(&reqUpdate)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		helper.AbortWithBindError(ctx, err)
 		return
 	}
 
 	updateCategory, err := cc.categoryUsecase.Update(id, &reqUpdate)
-	if err != nil {
+	if errors.Is(err, usecase.ErrCategoryNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if errors.Is(err, usecase.ErrCategoryParent) {
+		helper.AbortWithFieldError(ctx, "parent_id", "category_exists", err.Error())
+		return
+	} else if errors.Is(err, usecase.ErrCategoryCycle) {
+		helper.AbortWithFieldError(ctx, "parent_id", "no_cycle", err.Error())
+		return
+	} else if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -101,14 +177,31 @@ func (cc *categoryController) DeleteCategory(ctx *gin.Context) {
 		return
 	}
 
-	err = cc.categoryUsecase.Delete(id) 
+	var req dto.DeleteCategoryRequest
+	err = ctx.ShouldBindQuery(&req)
 	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	deleted, err := cc.categoryUsecase.Delete(id, req)
+	if errors.Is(err, usecase.ErrCategoryNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if errors.Is(err, usecase.ErrCategoryTarget) {
+		helper.AbortWithFieldError(ctx, "target_id", "category_exists", err.Error())
+		return
+	} else if errors.Is(err, usecase.ErrCategoryInUse) {
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
-		return 
+		return
 	}
 
-	ctx.JSON(http.StatusNoContent, dto.GeneralResponse{
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
 		Message: "successfully deleted category",
+		Data: deleted,
 	})
 }
 
@@ -117,7 +210,10 @@ func NewCategoryController(cu usecase.CategoryUsecase, rg *gin.RouterGroup) *cat
 
 	// public routes
 	rg.GET("/category", controller.GetAllCategories)
+	rg.GET("/category/tree", controller.GetCategoryTree)
 	rg.GET("/category/:id", controller.GetCategoryById)
+	rg.GET("/category/:id/books", controller.GetCategoryBooks)
+	rg.GET("/category/by-slug/:slug", controller.GetCategoryBySlug)
 
 	// routes with middleware protected
 	protected := rg.Group("")
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type couponController struct {
+	promotionUsecase usecase.PromotionUsecase
+}
+
+func (cc *couponController) CreateCoupon(ctx *gin.Context) {
+	var req dto.CreateCouponRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	coupon, err := cc.promotionUsecase.CreateCoupon(req)
+	if cc.abortWithCouponError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created coupon",
+		Data: coupon,
+	})
+}
+
+func (cc *couponController) GetCoupons(ctx *gin.Context) {
+	var filter dto.CouponFilterRequest
+	err := ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	coupons, paging, err := cc.promotionUsecase.GetCoupons(filter)
+	if cc.abortWithCouponError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PagedResponse{
+		Message: "successfully get coupons",
+		Data: coupons,
+		Paging: *paging,
+	})
+}
+
+func (cc *couponController) GetCouponById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	coupon, err := cc.promotionUsecase.GetCouponById(id)
+	if cc.abortWithCouponError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get coupon by id",
+		Data: coupon,
+	})
+}
+
+func (cc *couponController) UpdateCoupon(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var reqUpdate dto.UpdateCouponRequest
+	err = ctx.ShouldBindJSON(&reqUpdate)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	coupon, err := cc.promotionUsecase.UpdateCoupon(id, reqUpdate)
+	if cc.abortWithCouponError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated coupon",
+		Data: coupon,
+	})
+}
+
+func (cc *couponController) DeleteCoupon(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = cc.promotionUsecase.DeleteCoupon(id)
+	if cc.abortWithCouponError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted coupon",
+	})
+}
+
+func (cc *couponController) abortWithCouponError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrCouponNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrDuplicateCouponCode):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrCouponValue):
+		helper.AbortWithFieldError(ctx, "value", "max", err.Error())
+	case errors.Is(err, usecase.ErrCouponWindow):
+		helper.AbortWithFieldError(ctx, "ends_at", "gtfield", err.Error())
+	case errors.Is(err, usecase.ErrCategoryNotFound):
+		helper.AbortWithFieldError(ctx, "category_ids", "category_exists", err.Error())
+	case errors.Is(err, usecase.ErrBookNotFound):
+		helper.AbortWithFieldError(ctx, "book_ids", "exists", err.Error())
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewCouponController(pu usecase.PromotionUsecase, rg *gin.RouterGroup) *couponController {
+	controller := &couponController{promotionUsecase: pu}
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin"))
+
+	protected.GET("/coupon", controller.GetCoupons)
+	protected.POST("/coupon", controller.CreateCoupon)
+	protected.GET("/coupon/:id", controller.GetCouponById)
+	protected.PUT("/coupon/:id", controller.UpdateCoupon)
+	protected.DELETE("/coupon/:id", controller.DeleteCoupon)
+
+	return controller
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type currencyController struct {
+	currencyUsecase usecase.CurrencyUsecase
+}
+
+func (cc *currencyController) GetCurrencies(ctx *gin.Context) {
+	currencies, err := cc.currencyUsecase.GetCurrencies()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get currencies",
+		Data:    currencies,
+	})
+}
+
+func (cc *currencyController) SetRate(ctx *gin.Context) {
+	var req dto.SetExchangeRateRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	rate, err := cc.currencyUsecase.SetRate(strings.ToUpper(ctx.Param("code")), req)
+	if cc.abortWithCurrencyError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully set exchange rate",
+		Data:    rate,
+	})
+}
+
+func (cc *currencyController) DeleteRate(ctx *gin.Context) {
+	err := cc.currencyUsecase.DeleteRate(strings.ToUpper(ctx.Param("code")))
+	if cc.abortWithCurrencyError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted exchange rate",
+	})
+}
+
+// abortWithCurrencyError maps the currency usecase errors to a status code
+// and reports whether the request was aborted.
+func (cc *currencyController) abortWithCurrencyError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrUnsupportedCurrency):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrInvalidCurrency), errors.Is(err, usecase.ErrBaseCurrencyRate):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewCurrencyController(cu usecase.CurrencyUsecase, rg *gin.RouterGroup) *currencyController {
+	controller := &currencyController{currencyUsecase: cu}
+
+	// public routes
+	rg.GET("/currency", controller.GetCurrencies)
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin"))
+
+	protected.PUT("/currency/:code", controller.SetRate)
+	protected.DELETE("/currency/:code", controller.DeleteRate)
+
+	return controller
+}
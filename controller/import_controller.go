@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type importController struct {
+	importUsecase usecase.ImportUsecase
+}
+
+var importFormatsByExtension = map[string]string{
+	".csv":    model.ImportFormatCSV,
+	".ndjson": model.ImportFormatNDJSON,
+	".jsonl":  model.ImportFormatNDJSON,
+}
+
+func (ic *importController) ImportBooks(ctx *gin.Context) {
+	var req dto.ImportBookRequest
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	// leave room for the multipart envelope around the file itself
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, usecase.MaxImportSize+1<<20)
+
+	fileHeader, err := ctx.FormFile("file")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: usecase.ErrImportTooLarge.Error()})
+		return
+	} else if err != nil {
+		helper.AbortWithFieldError(ctx, "file", "required", "file is required")
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = importFormatsByExtension[strings.ToLower(filepath.Ext(fileHeader.Filename))]
+	}
+
+	if format == "" {
+		helper.AbortWithFieldError(ctx, "format", "required", "format is required when the file is not named .csv, .ndjson or .jsonl")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	job, err := ic.importUsecase.Start(ctx.GetInt("user_id"), format, req.DryRun, file)
+	switch {
+	case errors.Is(err, usecase.ErrImportTooLarge):
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, usecase.ErrImportFormat), errors.Is(err, usecase.ErrInvalidImportFile):
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, dto.ErrorResponse{Error: err.Error()})
+		return
+	case err != nil:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.Header("Location", fmt.Sprintf("%s/import/%d", strings.TrimSuffix(path.Dir(path.Dir(ctx.Request.URL.Path)), "/"), job.ID))
+	ctx.JSON(http.StatusAccepted, dto.GeneralResponse{
+		Message: "book import has been queued",
+		Data: job,
+	})
+}
+
+func (ic *importController) GetImportById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	job, err := ic.importUsecase.GetById(id, ctx.GetInt("user_id"), ctx.GetString("role"))
+	if errors.Is(err, usecase.ErrImportNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if job.FailedRows > 0 {
+		job.ErrorReportURL = path.Join(ctx.Request.URL.Path, "errors")
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get import job",
+		Data: job,
+	})
+}
+
+// GetImportErrors downloads the error report of a job as CSV, one line per
+// problem with the row number it was found on.
+func (ic *importController) GetImportErrors(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	rowErrors, err := ic.importUsecase.GetErrors(id, ctx.GetInt("user_id"), ctx.GetString("role"))
+	if errors.Is(err, usecase.ErrImportNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%d-errors.csv"`, id))
+	ctx.Status(http.StatusOK)
+
+	writer := csv.NewWriter(ctx.Writer)
+	writer.Write([]string{"row", "field", "rule", "message"})
+	for _, rowError := range rowErrors {
+		writer.Write([]string{strconv.Itoa(rowError.Row), rowError.Field, rowError.Rule, rowError.Message})
+	}
+	writer.Flush()
+}
+
+func NewImportController(iu usecase.ImportUsecase, rg *gin.RouterGroup) *importController {
+	controller := &importController{importUsecase: iu}
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin", "seller"))
+
+	protected.POST("/book/import", controller.ImportBooks)
+	protected.GET("/import/:id", controller.GetImportById)
+	protected.GET("/import/:id/errors", controller.GetImportErrors)
+
+	return controller
+}
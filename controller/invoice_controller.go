@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type invoiceController struct {
+	invoiceUsecase usecase.InvoiceUsecase
+}
+
+// GetOrderInvoice downloads the PDF invoice of a paid order.
+func (ic *invoiceController) GetOrderInvoice(ctx *gin.Context) {
+	orderId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	invoice, data, err := ic.invoiceUsecase.GetOrderInvoicePDF(orderId, ctx.GetInt("user_id"), ctx.GetString("role"))
+	if ic.abortWithInvoiceError(ctx, err) {
+		return
+	}
+
+	ic.sendPDF(ctx, invoice, data)
+}
+
+// GetOrderInvoices lists the invoice and credit notes of an order.
+func (ic *invoiceController) GetOrderInvoices(ctx *gin.Context) {
+	orderId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	invoices, err := ic.invoiceUsecase.GetByOrder(orderId, ctx.GetInt("user_id"), ctx.GetString("role"))
+	if ic.abortWithInvoiceError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get order invoices",
+		Data:    invoices,
+	})
+}
+
+// GetInvoicePDF downloads an invoice or credit note by id.
+func (ic *invoiceController) GetInvoicePDF(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	invoice, data, err := ic.invoiceUsecase.GetPDF(id, ctx.GetInt("user_id"), ctx.GetString("role"))
+	if ic.abortWithInvoiceError(ctx, err) {
+		return
+	}
+
+	ic.sendPDF(ctx, invoice, data)
+}
+
+func (ic *invoiceController) sendPDF(ctx *gin.Context, invoice *model.Invoice, data []byte) {
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, invoice.Number))
+	ctx.Data(http.StatusOK, "application/pdf", data)
+}
+
+func (ic *invoiceController) abortWithInvoiceError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrOrderNotFound), errors.Is(err, usecase.ErrInvoiceNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrOrderNotInvoiced):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewInvoiceController(iu usecase.InvoiceUsecase, rg *gin.RouterGroup) *invoiceController {
+	controller := &invoiceController{invoiceUsecase: iu}
+
+	rg.GET("/orders/:id/invoice", controller.GetOrderInvoice)
+	rg.GET("/orders/:id/invoices", controller.GetOrderInvoices)
+	rg.GET("/invoice/:id/pdf", controller.GetInvoicePDF)
+
+	return controller
+}
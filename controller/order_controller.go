@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type orderController struct {
+	orderUsecase usecase.OrderUsecase
+}
+
+func (oc *orderController) Checkout(ctx *gin.Context) {
+	order, err := oc.orderUsecase.Checkout(ctx.Request.Context(), ctx.GetInt("user_id"), ctx.GetString("currency"))
+	if oc.abortWithOrderError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully placed order",
+		Data: order,
+	})
+}
+
+func (oc *orderController) GetOrders(ctx *gin.Context) {
+	var filter dto.OrderFilterRequest
+	err := ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	orders, paging, err := oc.orderUsecase.GetByUser(ctx.GetInt("user_id"), filter)
+	if oc.abortWithOrderError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PagedResponse{
+		Message: "successfully get orders",
+		Data: orders,
+		Paging: *paging,
+	})
+}
+
+func (oc *orderController) GetOrderById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	order, err := oc.orderUsecase.GetById(id, ctx.GetInt("user_id"))
+	if oc.abortWithOrderError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get order by id",
+		Data: order,
+	})
+}
+
+func (oc *orderController) MarkOrderPaid(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	order, err := oc.orderUsecase.MarkPaid(id)
+	if oc.abortWithOrderError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully marked order as paid",
+		Data: order,
+	})
+}
+
+func (oc *orderController) CancelOrder(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	order, err := oc.orderUsecase.Cancel(id, ctx.GetInt("user_id"), ctx.GetString("role"))
+	if oc.abortWithOrderError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully cancelled order",
+		Data: order,
+	})
+}
+
+func (oc *orderController) abortWithOrderError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrOrderNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrCartEmpty), errors.Is(err, usecase.ErrShippingAddressRequired), errors.Is(err, usecase.ErrShippingMethodRequired):
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrCouponRejected), errors.Is(err, usecase.ErrInsufficientStock), errors.Is(err, usecase.ErrShippingMethodUnavailable), errors.Is(err, usecase.ErrOrderNotPending), errors.Is(err, usecase.ErrCartChanged):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewOrderController(ou usecase.OrderUsecase, rg *gin.RouterGroup) *orderController {
+	controller := &orderController{orderUsecase: ou}
+
+	rg.POST("/checkout", controller.Checkout)
+	rg.GET("/order", controller.GetOrders)
+	rg.GET("/order/:id", controller.GetOrderById)
+	rg.POST("/order/:id/cancel", controller.CancelOrder)
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin"))
+
+	protected.POST("/order/:id/paid", controller.MarkOrderPaid)
+
+	return controller
+}
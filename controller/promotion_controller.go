@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type promotionController struct {
+	promotionUsecase usecase.PromotionUsecase
+}
+
+func (pc *promotionController) CreatePromotion(ctx *gin.Context) {
+	var req dto.CreatePromotionRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	promotion, err := pc.promotionUsecase.CreatePromotion(req)
+	if pc.abortWithPromotionError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created promotion",
+		Data: promotion,
+	})
+}
+
+func (pc *promotionController) GetPromotions(ctx *gin.Context) {
+	var filter dto.PromotionFilterRequest
+	err := ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	promotions, paging, err := pc.promotionUsecase.GetPromotions(filter)
+	if pc.abortWithPromotionError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PagedResponse{
+		Message: "successfully get promotions",
+		Data: promotions,
+		Paging: *paging,
+	})
+}
+
+func (pc *promotionController) GetPromotionById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	promotion, err := pc.promotionUsecase.GetPromotionById(id)
+	if pc.abortWithPromotionError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get promotion by id",
+		Data: promotion,
+	})
+}
+
+func (pc *promotionController) UpdatePromotion(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var reqUpdate dto.UpdatePromotionRequest
+	err = ctx.ShouldBindJSON(&reqUpdate)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	promotion, err := pc.promotionUsecase.UpdatePromotion(id, reqUpdate)
+	if pc.abortWithPromotionError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated promotion",
+		Data: promotion,
+	})
+}
+
+func (pc *promotionController) DeletePromotion(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = pc.promotionUsecase.DeletePromotion(id)
+	if pc.abortWithPromotionError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted promotion",
+	})
+}
+
+func (pc *promotionController) abortWithPromotionError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrPromotionNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrPromotionValue):
+		helper.AbortWithFieldError(ctx, "value", "promotion_value", err.Error())
+	case errors.Is(err, usecase.ErrPromotionQty):
+		helper.AbortWithFieldError(ctx, "buy_qty", "promotion_qty", err.Error())
+	case errors.Is(err, usecase.ErrPromotionWindow):
+		helper.AbortWithFieldError(ctx, "ends_at", "gtfield", err.Error())
+	case errors.Is(err, usecase.ErrCategoryNotFound):
+		helper.AbortWithFieldError(ctx, "category_ids", "category_exists", err.Error())
+	case errors.Is(err, usecase.ErrBookNotFound):
+		helper.AbortWithFieldError(ctx, "book_ids", "exists", err.Error())
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewPromotionController(pu usecase.PromotionUsecase, rg *gin.RouterGroup) *promotionController {
+	controller := &promotionController{promotionUsecase: pu}
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin"))
+
+	protected.GET("/promotion", controller.GetPromotions)
+	protected.POST("/promotion", controller.CreatePromotion)
+	protected.GET("/promotion/:id", controller.GetPromotionById)
+	protected.PUT("/promotion/:id", controller.UpdatePromotion)
+	protected.DELETE("/promotion/:id", controller.DeletePromotion)
+
+	return controller
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type publisherController struct {
+	publisherUsecase usecase.PublisherUsecase
+}
+
+func (pc *publisherController) CreatePublisher(ctx *gin.Context) {
+	var req dto.CreatePublisherRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	createPublisher, err := pc.publisherUsecase.Create(req)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created publisher",
+		Data: createPublisher,
+	})
+}
+
+func (pc *publisherController) GetAllPublishers(ctx *gin.Context) {
+	publishers, err := pc.publisherUsecase.GetAll()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get all publishers",
+		Data: publishers,
+	})
+}
+
+func (pc *publisherController) GetPublisherById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	publisher, err := pc.publisherUsecase.GetById(id)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get publisher by id",
+		Data: publisher,
+	})
+}
+
+func (pc *publisherController) UpdatePublisher(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var reqUpdate dto.UpdatePublisherRequest
+	err = ctx.ShouldBindJSON(&reqUpdate)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	updatePublisher, err := pc.publisherUsecase.Update(id, &reqUpdate)
+	if errors.Is(err, usecase.ErrPublisherNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated publisher",
+		Data: updatePublisher,
+	})
+}
+
+func (pc *publisherController) DeletePublisher(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = pc.publisherUsecase.Delete(id)
+	if errors.Is(err, usecase.ErrPublisherNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if errors.Is(err, usecase.ErrPublisherInUse) {
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted publisher",
+	})
+}
+
+func NewPublisherController(pu usecase.PublisherUsecase, rg *gin.RouterGroup) *publisherController {
+	controller := &publisherController{publisherUsecase: pu}
+
+	// public routes
+	rg.GET("/publisher", controller.GetAllPublishers)
+	rg.GET("/publisher/:id", controller.GetPublisherById)
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin", "seller"))
+
+	protected.POST("/publisher", controller.CreatePublisher)
+	protected.PUT("/publisher/:id", controller.UpdatePublisher)
+	protected.DELETE("/publisher/:id", controller.DeletePublisher)
+
+	return controller
+}
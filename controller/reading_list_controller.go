@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type readingListController struct {
+	readingListUsecase usecase.ReadingListUsecase
+	sharedPath         string
+}
+
+func (rlc *readingListController) CreateReadingList(ctx *gin.Context) {
+	var req dto.CreateReadingListRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	list, err := rlc.readingListUsecase.Create(ctx.GetInt("user_id"), req, ctx.GetString("currency"))
+	if rlc.abortWithReadingListError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created reading list",
+		Data: rlc.withShareURL(list),
+	})
+}
+
+func (rlc *readingListController) GetReadingLists(ctx *gin.Context) {
+	lists, err := rlc.readingListUsecase.GetByUser(ctx.GetInt("user_id"), ctx.GetString("currency"))
+	if rlc.abortWithReadingListError(ctx, err) {
+		return
+	}
+
+	for i := range lists {
+		rlc.withShareURL(&lists[i])
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get reading lists",
+		Data: lists,
+	})
+}
+
+func (rlc *readingListController) GetReadingListById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	list, err := rlc.readingListUsecase.GetById(id, ctx.GetInt("user_id"), ctx.GetString("currency"))
+	if rlc.abortWithReadingListError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get reading list",
+		Data: rlc.withShareURL(list),
+	})
+}
+
+func (rlc *readingListController) GetSharedReadingList(ctx *gin.Context) {
+	list, err := rlc.readingListUsecase.GetShared(ctx.Param("token"), ctx.GetString("currency"))
+	if rlc.abortWithReadingListError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get reading list",
+		Data: list,
+	})
+}
+
+func (rlc *readingListController) UpdateReadingList(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var reqUpdate dto.UpdateReadingListRequest
+	err = ctx.ShouldBindJSON(&reqUpdate)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	list, err := rlc.readingListUsecase.Update(id, ctx.GetInt("user_id"), reqUpdate, ctx.GetString("currency"))
+	if rlc.abortWithReadingListError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated reading list",
+		Data: rlc.withShareURL(list),
+	})
+}
+
+func (rlc *readingListController) DeleteReadingList(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = rlc.readingListUsecase.Delete(id, ctx.GetInt("user_id"))
+	if rlc.abortWithReadingListError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted reading list",
+	})
+}
+
+func (rlc *readingListController) AddBook(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.AddReadingListBookRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	list, err := rlc.readingListUsecase.AddBook(id, ctx.GetInt("user_id"), req, ctx.GetString("currency"))
+	if errors.Is(err, usecase.ErrBookNotFound) {
+		helper.AbortWithFieldError(ctx, "book_id", "exists", err.Error())
+		return
+	} else if rlc.abortWithReadingListError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully added book to reading list",
+		Data: rlc.withShareURL(list),
+	})
+}
+
+func (rlc *readingListController) RemoveBook(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	bookId, err := strconv.Atoi(ctx.Param("bookId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	list, err := rlc.readingListUsecase.RemoveBook(id, ctx.GetInt("user_id"), bookId, ctx.GetString("currency"))
+	if rlc.abortWithReadingListError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully removed book from reading list",
+		Data: rlc.withShareURL(list),
+	})
+}
+
+func (rlc *readingListController) RotateShareLink(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	list, err := rlc.readingListUsecase.RotateShareToken(id, ctx.GetInt("user_id"), ctx.GetString("currency"))
+	if rlc.abortWithReadingListError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully rotated reading list share link",
+		Data: rlc.withShareURL(list),
+	})
+}
+
+// withShareURL fills in the public link of a list shown to its owner.
+func (rlc *readingListController) withShareURL(list *dto.ReadingListResponse) *dto.ReadingListResponse {
+	if list.IsPublic && list.ShareToken != "" {
+		list.ShareURL = rlc.sharedPath + "/" + list.ShareToken
+	}
+	return list
+}
+
+func (rlc *readingListController) abortWithReadingListError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrReadingListNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+// NewReadingListController registers the owner routes on rg and the share
+// link on public, which does not require a token.
+func NewReadingListController(rlu usecase.ReadingListUsecase, rg *gin.RouterGroup, public *gin.RouterGroup) *readingListController {
+	controller := &readingListController{
+		readingListUsecase: rlu,
+		sharedPath:         public.BasePath() + "/shared/reading-list",
+	}
+
+	// public routes
+	public.GET("/shared/reading-list/:token", controller.GetSharedReadingList)
+
+	rg.GET("/reading-list", controller.GetReadingLists)
+	rg.POST("/reading-list", controller.CreateReadingList)
+	rg.GET("/reading-list/:id", controller.GetReadingListById)
+	rg.PUT("/reading-list/:id", controller.UpdateReadingList)
+	rg.DELETE("/reading-list/:id", controller.DeleteReadingList)
+	rg.POST("/reading-list/:id/books", controller.AddBook)
+	rg.DELETE("/reading-list/:id/books/:bookId", controller.RemoveBook)
+	rg.POST("/reading-list/:id/share", controller.RotateShareLink)
+
+	return controller
+}
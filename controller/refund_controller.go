@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type refundController struct {
+	refundUsecase usecase.RefundUsecase
+}
+
+func (rc *refundController) RefundOrder(ctx *gin.Context) {
+	orderId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.RefundOrderRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	order, err := rc.refundUsecase.RefundOrder(orderId, req)
+	if abortWithReturnError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully refunded order",
+		Data:    order,
+	})
+}
+
+func NewRefundController(ru usecase.RefundUsecase, rg *gin.RouterGroup) *refundController {
+	controller := &refundController{refundUsecase: ru}
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin"))
+
+	protected.POST("/order/:id/refunds", controller.RefundOrder)
+
+	return controller
+}
@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type returnController struct {
+	returnUsecase usecase.ReturnUsecase
+}
+
+func (rc *returnController) CreateReturn(ctx *gin.Context) {
+	orderId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.CreateReturnRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	ret, err := rc.returnUsecase.Create(orderId, ctx.GetInt("user_id"), req)
+	if abortWithReturnError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully requested return",
+		Data:    ret,
+	})
+}
+
+func (rc *returnController) GetReturns(ctx *gin.Context) {
+	var filter dto.ReturnFilterRequest
+	err := ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	returns, paging, err := rc.returnUsecase.GetAll(ctx.GetInt("user_id"), ctx.GetString("role"), filter)
+	if abortWithReturnError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PagedResponse{
+		Message: "successfully get returns",
+		Data:    returns,
+		Paging:  *paging,
+	})
+}
+
+func (rc *returnController) GetReturnById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ret, err := rc.returnUsecase.GetById(id, ctx.GetInt("user_id"), ctx.GetString("role"))
+	if abortWithReturnError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get return",
+		Data:    ret,
+	})
+}
+
+func (rc *returnController) UploadPhoto(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// leave room for the multipart envelope around the file itself
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, usecase.MaxReturnPhotoSize+1<<20)
+
+	fileHeader, err := ctx.FormFile("photo")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: usecase.ErrPhotoTooLarge.Error()})
+		return
+	} else if err != nil {
+		helper.AbortWithFieldError(ctx, "photo", "required", "photo is required")
+		return
+	}
+
+	if fileHeader.Size > usecase.MaxReturnPhotoSize {
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: usecase.ErrPhotoTooLarge.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	ret, err := rc.returnUsecase.UploadPhoto(id, ctx.GetInt("user_id"), file)
+	if abortWithReturnError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully uploaded return photo",
+		Data:    ret,
+	})
+}
+
+func (rc *returnController) GetPhoto(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	photoId, err := strconv.Atoi(ctx.Param("photoId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	data, err := rc.returnUsecase.GetPhoto(id, photoId, ctx.GetInt("user_id"), ctx.GetString("role"))
+	if abortWithReturnError(ctx, err) {
+		return
+	}
+
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Data(http.StatusOK, http.DetectContentType(data), data)
+}
+
+func (rc *returnController) ApproveReturn(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.ReviewReturnRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	ret, err := rc.returnUsecase.Approve(id, req)
+	if abortWithReturnError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully approved return",
+		Data:    ret,
+	})
+}
+
+func (rc *returnController) RejectReturn(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.ReviewReturnRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	ret, err := rc.returnUsecase.Reject(id, req)
+	if abortWithReturnError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully rejected return",
+		Data:    ret,
+	})
+}
+
+func (rc *returnController) ReceiveReturn(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ret, err := rc.returnUsecase.Receive(id)
+	if abortWithReturnError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully received return",
+		Data:    ret,
+	})
+}
+
+func (rc *returnController) RefundReturn(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.RefundReturnRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	ret, err := rc.returnUsecase.Refund(id, req)
+	if abortWithReturnError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully refunded return",
+		Data:    ret,
+	})
+}
+
+// abortWithReturnError maps the return and refund usecase errors to a
+// status code and reports whether the request was aborted. It is shared with
+// the refund controller.
+func abortWithReturnError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrOrderNotFound), errors.Is(err, usecase.ErrReturnNotFound),
+		errors.Is(err, usecase.ErrReturnPhotoNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrReturnItem):
+		helper.AbortWithFieldError(ctx, "order_item_id", "invalid", err.Error())
+	case errors.Is(err, usecase.ErrRefundExceedsReturn), errors.Is(err, usecase.ErrRefundExceedsOrder):
+		helper.AbortWithFieldError(ctx, "amount", "max", err.Error())
+	case errors.Is(err, usecase.ErrPhotoTooLarge):
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrPhotoType):
+		ctx.AbortWithStatusJSON(http.StatusUnsupportedMediaType, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrOrderNotReturnable), errors.Is(err, usecase.ErrReturnExceedsOrder),
+		errors.Is(err, usecase.ErrReturnState), errors.Is(err, usecase.ErrTooManyReturnPhotos),
+		errors.Is(err, usecase.ErrOrderNotRefundable), errors.Is(err, usecase.ErrReturnRefunded):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrRefundFailed):
+		ctx.AbortWithStatusJSON(http.StatusBadGateway, dto.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewReturnController(ru usecase.ReturnUsecase, rg *gin.RouterGroup) *returnController {
+	controller := &returnController{returnUsecase: ru}
+
+	rg.POST("/order/:id/returns", controller.CreateReturn)
+	rg.GET("/return", controller.GetReturns)
+	rg.GET("/return/:id", controller.GetReturnById)
+	rg.POST("/return/:id/photos", controller.UploadPhoto)
+	rg.GET("/return/:id/photos/:photoId", controller.GetPhoto)
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin"))
+
+	protected.PUT("/return/:id/approve", controller.ApproveReturn)
+	protected.PUT("/return/:id/reject", controller.RejectReturn)
+	protected.PUT("/return/:id/receive", controller.ReceiveReturn)
+	protected.POST("/return/:id/refund", controller.RefundReturn)
+
+	return controller
+}
@@ -0,0 +1,275 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type reviewController struct {
+	reviewUsecase usecase.ReviewUsecase
+}
+
+func (rc *reviewController) CreateReview(ctx *gin.Context) {
+	bookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.CreateReviewRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	createReview, err := rc.reviewUsecase.Create(bookId, ctx.GetInt("user_id"), req)
+	if rc.abortWithReviewError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created review",
+		Data: createReview,
+	})
+}
+
+func (rc *reviewController) GetBookReviews(ctx *gin.Context) {
+	bookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var filter dto.ReviewFilterRequest
+	err = ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	reviews, paging, err := rc.reviewUsecase.GetByBook(bookId, filter)
+	if rc.abortWithReviewError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PagedResponse{
+		Message: "successfully get book reviews",
+		Data: reviews,
+		Paging: *paging,
+	})
+}
+
+func (rc *reviewController) UpdateReview(ctx *gin.Context) {
+	bookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	reviewId, err := strconv.Atoi(ctx.Param("reviewId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var reqUpdate dto.UpdateReviewRequest
+	err = ctx.ShouldBindJSON(&reqUpdate)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	updateReview, err := rc.reviewUsecase.Update(bookId, reviewId, ctx.GetInt("user_id"), reqUpdate)
+	if rc.abortWithReviewError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated review",
+		Data: updateReview,
+	})
+}
+
+func (rc *reviewController) DeleteReview(ctx *gin.Context) {
+	bookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	reviewId, err := strconv.Atoi(ctx.Param("reviewId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = rc.reviewUsecase.Delete(bookId, reviewId, ctx.GetInt("user_id"), ctx.GetString("role"))
+	if rc.abortWithReviewError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted review",
+	})
+}
+
+func (rc *reviewController) VoteReview(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.ReviewVoteRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	review, err := rc.reviewUsecase.Vote(id, ctx.GetInt("user_id"), req)
+	if rc.abortWithReviewError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully voted on review",
+		Data: review,
+	})
+}
+
+func (rc *reviewController) RemoveReviewVote(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	review, err := rc.reviewUsecase.RemoveVote(id, ctx.GetInt("user_id"))
+	if rc.abortWithReviewError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully removed review vote",
+		Data: review,
+	})
+}
+
+func (rc *reviewController) ReportReview(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.ReportReviewRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	err = rc.reviewUsecase.Report(id, ctx.GetInt("user_id"), req)
+	if rc.abortWithReviewError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully reported review",
+	})
+}
+
+func (rc *reviewController) GetModerationQueue(ctx *gin.Context) {
+	var filter dto.ModerationFilterRequest
+	err := ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	reviews, paging, err := rc.reviewUsecase.GetModerationQueue(filter)
+	if rc.abortWithReviewError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PagedResponse{
+		Message: "successfully get review moderation queue",
+		Data: reviews,
+		Paging: *paging,
+	})
+}
+
+func (rc *reviewController) ModerateReview(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.ModerateReviewRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	review, err := rc.reviewUsecase.Moderate(id, ctx.GetInt("user_id"), req)
+	if rc.abortWithReviewError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully moderated review",
+		Data: review,
+	})
+}
+
+// abortWithReviewError maps the review usecase errors to a status code and
+// reports whether the request was aborted.
+func (rc *reviewController) abortWithReviewError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrBookNotFound), errors.Is(err, usecase.ErrReviewNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrReviewExists), errors.Is(err, usecase.ErrReviewReported):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrReviewForbidden), errors.Is(err, usecase.ErrOwnReviewVote):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewReviewController(ru usecase.ReviewUsecase, rg *gin.RouterGroup) *reviewController {
+	controller := &reviewController{reviewUsecase: ru}
+
+	// any signed in user can review, only the author of a review may edit it
+	rg.GET("/book/:id/reviews", controller.GetBookReviews)
+	rg.POST("/book/:id/reviews", controller.CreateReview)
+	rg.PUT("/book/:id/reviews/:reviewId", controller.UpdateReview)
+	rg.DELETE("/book/:id/reviews/:reviewId", controller.DeleteReview)
+	rg.POST("/review/:id/vote", controller.VoteReview)
+	rg.DELETE("/review/:id/vote", controller.RemoveReviewVote)
+	rg.POST("/review/:id/report", controller.ReportReview)
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin"))
+
+	protected.GET("/review/moderation", controller.GetModerationQueue)
+	protected.PUT("/review/:id/moderation", controller.ModerateReview)
+
+	return controller
+}
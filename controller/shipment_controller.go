@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type shipmentController struct {
+	shipmentUsecase usecase.ShipmentUsecase
+}
+
+func (sc *shipmentController) CreateShipment(ctx *gin.Context) {
+	orderId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.CreateShipmentRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	shipment, err := sc.shipmentUsecase.Create(orderId, req)
+	if sc.abortWithShipmentError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created shipment",
+		Data:    shipment,
+	})
+}
+
+func (sc *shipmentController) GetOrderShipments(ctx *gin.Context) {
+	orderId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	shipments, err := sc.shipmentUsecase.GetByOrder(orderId, ctx.GetInt("user_id"), ctx.GetString("role"))
+	if sc.abortWithShipmentError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get order shipments",
+		Data:    shipments,
+	})
+}
+
+func (sc *shipmentController) UpdateShipment(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.UpdateShipmentRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	shipment, err := sc.shipmentUsecase.Update(id, req)
+	if sc.abortWithShipmentError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated shipment",
+		Data:    shipment,
+	})
+}
+
+func (sc *shipmentController) AddShipmentEvent(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.CreateShipmentEventRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	shipment, err := sc.shipmentUsecase.AddEvent(id, req)
+	if sc.abortWithShipmentError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully recorded shipment event",
+		Data:    shipment,
+	})
+}
+
+func (sc *shipmentController) GetFulfillmentQueue(ctx *gin.Context) {
+	var filter dto.FulfillmentQueueFilterRequest
+	err := ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	entries, paging, err := sc.shipmentUsecase.GetQueue(filter)
+	if sc.abortWithShipmentError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PagedResponse{
+		Message: "successfully get fulfillment queue",
+		Data:    entries,
+		Paging:  *paging,
+	})
+}
+
+// abortWithShipmentError maps the shipment usecase errors to a status code
+// and reports whether the request was aborted.
+func (sc *shipmentController) abortWithShipmentError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrOrderNotFound), errors.Is(err, usecase.ErrShipmentNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrOrderNotPaid), errors.Is(err, usecase.ErrOverShipped), errors.Is(err, usecase.ErrShipmentDelivered), errors.Is(err, usecase.ErrShipmentStatus):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrShipmentItems):
+		helper.AbortWithFieldError(ctx, "items", "invalid", err.Error())
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewShipmentController(su usecase.ShipmentUsecase, rg *gin.RouterGroup) *shipmentController {
+	controller := &shipmentController{shipmentUsecase: su}
+
+	rg.GET("/orders/:id/shipments", controller.GetOrderShipments)
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin"))
+
+	protected.POST("/orders/:id/shipments", controller.CreateShipment)
+	protected.PUT("/shipment/:id", controller.UpdateShipment)
+	protected.POST("/shipment/:id/events", controller.AddShipmentEvent)
+	protected.GET("/fulfillment-queue", controller.GetFulfillmentQueue)
+
+	return controller
+}
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type shippingController struct {
+	shippingUsecase usecase.ShippingUsecase
+}
+
+func (sc *shippingController) CreateZone(ctx *gin.Context) {
+	var req dto.CreateShippingZoneRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	zone, err := sc.shippingUsecase.CreateZone(req)
+	if sc.abortWithShippingError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created shipping zone",
+		Data:    zone,
+	})
+}
+
+func (sc *shippingController) GetZones(ctx *gin.Context) {
+	zones, err := sc.shippingUsecase.GetZones()
+	if sc.abortWithShippingError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get all shipping zones",
+		Data:    zones,
+	})
+}
+
+func (sc *shippingController) GetZoneById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	zone, err := sc.shippingUsecase.GetZoneById(id)
+	if sc.abortWithShippingError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get shipping zone",
+		Data:    zone,
+	})
+}
+
+func (sc *shippingController) UpdateZone(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.UpdateShippingZoneRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	zone, err := sc.shippingUsecase.UpdateZone(id, req)
+	if sc.abortWithShippingError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated shipping zone",
+		Data:    zone,
+	})
+}
+
+func (sc *shippingController) DeleteZone(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = sc.shippingUsecase.DeleteZone(id)
+	if sc.abortWithShippingError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted shipping zone",
+	})
+}
+
+func (sc *shippingController) CreateMethod(ctx *gin.Context) {
+	var req dto.CreateShippingMethodRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	method, err := sc.shippingUsecase.CreateMethod(req)
+	if sc.abortWithShippingError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created shipping method",
+		Data:    method,
+	})
+}
+
+func (sc *shippingController) GetMethods(ctx *gin.Context) {
+	methods, err := sc.shippingUsecase.GetMethods(ctx.GetString("role"))
+	if sc.abortWithShippingError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get all shipping methods",
+		Data:    methods,
+	})
+}
+
+func (sc *shippingController) GetMethodById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	method, err := sc.shippingUsecase.GetMethodById(id, ctx.GetString("role"))
+	if sc.abortWithShippingError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get shipping method",
+		Data:    method,
+	})
+}
+
+func (sc *shippingController) UpdateMethod(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.UpdateShippingMethodRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	method, err := sc.shippingUsecase.UpdateMethod(id, req)
+	if sc.abortWithShippingError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated shipping method",
+		Data:    method,
+	})
+}
+
+func (sc *shippingController) DeleteMethod(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = sc.shippingUsecase.DeleteMethod(id)
+	if sc.abortWithShippingError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted shipping method",
+	})
+}
+
+// abortWithShippingError maps the shipping usecase errors to a status code
+// and reports whether the request was aborted.
+func (sc *shippingController) abortWithShippingError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrShippingZoneNotFound), errors.Is(err, usecase.ErrShippingMethodNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrDuplicateShippingZone), errors.Is(err, usecase.ErrShippingZoneInUse):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrShippingRates):
+		helper.AbortWithFieldError(ctx, "rates", "invalid", err.Error())
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewShippingController(su usecase.ShippingUsecase, rg *gin.RouterGroup) *shippingController {
+	controller := &shippingController{shippingUsecase: su}
+
+	// public routes
+	rg.GET("/shipping-method", controller.GetMethods)
+	rg.GET("/shipping-method/:id", controller.GetMethodById)
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin"))
+
+	protected.POST("/shipping-method", controller.CreateMethod)
+	protected.PUT("/shipping-method/:id", controller.UpdateMethod)
+	protected.DELETE("/shipping-method/:id", controller.DeleteMethod)
+
+	protected.GET("/shipping-zone", controller.GetZones)
+	protected.GET("/shipping-zone/:id", controller.GetZoneById)
+	protected.POST("/shipping-zone", controller.CreateZone)
+	protected.PUT("/shipping-zone/:id", controller.UpdateZone)
+	protected.DELETE("/shipping-zone/:id", controller.DeleteZone)
+
+	return controller
+}
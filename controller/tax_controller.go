@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type taxController struct {
+	taxUsecase usecase.TaxUsecase
+}
+
+func (tc *taxController) CreateTaxRule(ctx *gin.Context) {
+	var req dto.CreateTaxRuleRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	rule, err := tc.taxUsecase.Create(req)
+	if tc.abortWithTaxError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created tax rule",
+		Data:    rule,
+	})
+}
+
+func (tc *taxController) GetAllTaxRules(ctx *gin.Context) {
+	var filter dto.TaxRuleFilterRequest
+	err := ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	rules, err := tc.taxUsecase.GetAll(filter)
+	if tc.abortWithTaxError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get all tax rules",
+		Data:    rules,
+	})
+}
+
+func (tc *taxController) GetTaxRuleById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	rule, err := tc.taxUsecase.GetById(id)
+	if tc.abortWithTaxError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get tax rule",
+		Data:    rule,
+	})
+}
+
+func (tc *taxController) UpdateTaxRule(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.UpdateTaxRuleRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	rule, err := tc.taxUsecase.Update(id, req)
+	if tc.abortWithTaxError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated tax rule",
+		Data:    rule,
+	})
+}
+
+func (tc *taxController) DeleteTaxRule(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = tc.taxUsecase.Delete(id)
+	if tc.abortWithTaxError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted tax rule",
+	})
+}
+
+// abortWithTaxError maps the tax usecase errors to a status code and reports
+// whether the request was aborted.
+func (tc *taxController) abortWithTaxError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrTaxRuleNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrDuplicateTaxRule):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewTaxController(tu usecase.TaxUsecase, rg *gin.RouterGroup) *taxController {
+	controller := &taxController{taxUsecase: tu}
+
+	// public routes
+	rg.GET("/tax-rule", controller.GetAllTaxRules)
+	rg.GET("/tax-rule/:id", controller.GetTaxRuleById)
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin"))
+
+	protected.POST("/tax-rule", controller.CreateTaxRule)
+	protected.PUT("/tax-rule/:id", controller.UpdateTaxRule)
+	protected.DELETE("/tax-rule/:id", controller.DeleteTaxRule)
+
+	return controller
+}
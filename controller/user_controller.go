@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
 	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
 	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
 )
@@ -27,7 +28,7 @@ func (uc *userController) createUser(ctx *gin.Context) {
 
 	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		helper.AbortWithBindError(ctx, err)
 		return
 	}
 
@@ -85,7 +86,7 @@ func (uc *userController) updateUser(ctx *gin.Context) {
 	var reqUpdate dto.UpdateUserRequest
 	err = ctx.ShouldBindJSON(&reqUpdate)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		helper.AbortWithBindError(ctx, err)
 		return
 	}
 
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type variantController struct {
+	variantUsecase usecase.VariantUsecase
+}
+
+func (vc *variantController) CreateVariant(ctx *gin.Context) {
+	bookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.CreateVariantRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	createVariant, err := vc.variantUsecase.Create(bookId, req)
+	if vc.abortWithVariantError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.GeneralResponse{
+		Message: "successfully created book variant",
+		Data: createVariant,
+	})
+}
+
+func (vc *variantController) GetBookVariants(ctx *gin.Context) {
+	bookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	variants, err := vc.variantUsecase.GetByBook(bookId, ctx.GetString("currency"))
+	if vc.abortWithVariantError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get book variants",
+		Data: variants,
+	})
+}
+
+func (vc *variantController) UpdateVariant(ctx *gin.Context) {
+	bookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	variantId, err := strconv.Atoi(ctx.Param("variantId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var reqUpdate dto.UpdateVariantRequest
+	err = ctx.ShouldBindJSON(&reqUpdate)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	updateVariant, err := vc.variantUsecase.Update(bookId, variantId, reqUpdate)
+	if vc.abortWithVariantError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully updated book variant",
+		Data: updateVariant,
+	})
+}
+
+func (vc *variantController) DeleteVariant(ctx *gin.Context) {
+	bookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	variantId, err := strconv.Atoi(ctx.Param("variantId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = vc.variantUsecase.Delete(bookId, variantId)
+	if vc.abortWithVariantError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully deleted book variant",
+	})
+}
+
+// abortWithVariantError maps the variant usecase errors to a status code and
+// reports whether the request was aborted.
+func (vc *variantController) abortWithVariantError(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, usecase.ErrBookNotFound), errors.Is(err, usecase.ErrVariantNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrDuplicateSku), errors.Is(err, usecase.ErrLastVariant):
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrVariantPrices):
+		helper.AbortWithFieldError(ctx, "prices", "prices", err.Error())
+	default:
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+
+	return true
+}
+
+func NewVariantController(vu usecase.VariantUsecase, rg *gin.RouterGroup) *variantController {
+	controller := &variantController{variantUsecase: vu}
+
+	// public routes
+	rg.GET("/book/:id/variants", controller.GetBookVariants)
+
+	// allowed roles routes
+	protected := rg.Group("")
+	protected.Use(middleware.RoleMiddleware("admin", "seller"))
+
+	protected.POST("/book/:id/variants", controller.CreateVariant)
+	protected.PUT("/book/:id/variants/:variantId", controller.UpdateVariant)
+	protected.DELETE("/book/:id/variants/:variantId", controller.DeleteVariant)
+
+	return controller
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/usecase"
+)
+
+type wishlistController struct {
+	wishlistUsecase usecase.WishlistUsecase
+}
+
+func (wc *wishlistController) GetWishlist(ctx *gin.Context) {
+	items, err := wc.wishlistUsecase.GetByUser(ctx.GetInt("user_id"), ctx.GetString("currency"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get wishlist",
+		Data: items,
+	})
+}
+
+func (wc *wishlistController) AddToWishlist(ctx *gin.Context) {
+	var req dto.AddWishlistRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithBindError(ctx, err)
+		return
+	}
+
+	items, err := wc.wishlistUsecase.Add(ctx.GetInt("user_id"), req, ctx.GetString("currency"))
+	if errors.Is(err, usecase.ErrBookNotFound) {
+		helper.AbortWithFieldError(ctx, "book_id", "exists", err.Error())
+		return
+	} else if errors.Is(err, usecase.ErrVariantNotFound) {
+		helper.AbortWithFieldError(ctx, "variant_id", "exists", err.Error())
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully added book to wishlist",
+		Data: items,
+	})
+}
+
+func (wc *wishlistController) RemoveFromWishlist(ctx *gin.Context) {
+	bookId, err := strconv.Atoi(ctx.Param("bookId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = wc.wishlistUsecase.Remove(ctx.GetInt("user_id"), bookId)
+	if errors.Is(err, usecase.ErrWishlistItemNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully removed book from wishlist",
+	})
+}
+
+func (wc *wishlistController) MoveToCart(ctx *gin.Context) {
+	bookId, err := strconv.Atoi(ctx.Param("bookId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req dto.MoveToCartRequest
+	if ctx.Request.ContentLength != 0 {
+		err = ctx.ShouldBindJSON(&req)
+		if err != nil {
+			helper.AbortWithBindError(ctx, err)
+			return
+		}
+	}
+
+	cart, err := wc.wishlistUsecase.MoveToCart(ctx.Request.Context(), ctx.GetInt("user_id"), bookId, req, ctx.GetString("currency"))
+	if errors.Is(err, usecase.ErrWishlistItemNotFound) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	} else if abortWithCartError(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully moved book to cart",
+		Data: cart,
+	})
+}
+
+func NewWishlistController(wu usecase.WishlistUsecase, rg *gin.RouterGroup) *wishlistController {
+	controller := &wishlistController{wishlistUsecase: wu}
+
+	rg.GET("/wishlist", controller.GetWishlist)
+	rg.POST("/wishlist", controller.AddToWishlist)
+	rg.DELETE("/wishlist/:bookId", controller.RemoveFromWishlist)
+	rg.POST("/wishlist/:bookId/move-to-cart", controller.MoveToCart)
+
+	return controller
+}
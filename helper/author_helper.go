@@ -0,0 +1,70 @@
+package helper
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	authorSeparator = regexp.MustCompile(`\s*(?:;|&|\band\b)\s*`)
+	authorInitial   = regexp.MustCompile(`^(?:\p{Lu}\.)+$|^\p{Lu}$`)
+)
+
+// SplitAuthorNames breaks a free-text author field such as
+// "Terry Pratchett & Neil Gaiman" into the individual names, dropping blanks
+// and repeated names. Commas separate names too, except in a name written
+// surname first and ending in initials, such as "Tolkien, J.R.R." or
+// "Le Guin, Ursula K.", which is kept as one name.
+func SplitAuthorNames(author string) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, group := range authorSeparator.Split(author, -1) {
+		for _, name := range splitCommaNames(group) {
+			name = strings.Join(strings.Fields(name), " ")
+			key := strings.ToLower(name)
+			if name == "" || seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// splitCommaNames splits a comma separated list of names. A part without
+// initials followed by a part ending in an initial is taken to be a surname
+// and the given names that belong to it.
+func splitCommaNames(group string) []string {
+	parts := strings.Split(group, ",")
+	names := make([]string, 0, len(parts))
+
+	for i := 0; i < len(parts); i++ {
+		name := strings.TrimSpace(parts[i])
+		if i+1 < len(parts) && !hasInitial(name) && endsWithInitial(parts[i+1]) {
+			name += ", " + strings.TrimSpace(parts[i+1])
+			i++
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func hasInitial(name string) bool {
+	for _, word := range strings.Fields(name) {
+		if authorInitial.MatchString(word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func endsWithInitial(name string) bool {
+	words := strings.Fields(name)
+	return len(words) > 0 && authorInitial.MatchString(words[len(words)-1])
+}
@@ -1,12 +1,46 @@
 package helper
 
-import "github.com/mhmmmdrivaldhi/go-book-api/model"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
 
-func CalculateTotalPrice(cart *model.Cart) int {
-	price := 0
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+)
+
+var cartTokenPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func CalculateTotalPrice(cart *model.Cart) model.Money {
+	price := model.NewMoney(0, cart.Currency)
 	for _, item := range cart.Items {
-		price += item.Price * item.Qty
+		price = price.Add(item.Price.Times(item.Qty))
 	}
 
 	return price
-}
\ No newline at end of file
+}
+
+func CalculateTotalQty(cart *model.Cart) int {
+	qty := 0
+	for _, item := range cart.Items {
+		qty += item.Qty
+	}
+
+	return qty
+}
+
+// NewCartToken makes the opaque token a guest cart is kept under. It is long
+// and random enough that it cannot be guessed.
+func NewCartToken() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// ValidCartToken reports whether token looks like one made by NewCartToken.
+func ValidCartToken(token string) bool {
+	return cartTokenPattern.MatchString(token)
+}
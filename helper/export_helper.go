@@ -0,0 +1,254 @@
+package helper
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+)
+
+// BookExporter writes books one at a time in an export format. Close must be
+// called after the last row to finish the document.
+type BookExporter interface {
+	ContentType() string
+	FileExtension() string
+	Write(row dto.BookExportRow) error
+	Close() error
+}
+
+func NewBookExporter(format string, w io.Writer, sender string) (BookExporter, error) {
+	switch format {
+	case "csv":
+		return &csvBookExporter{writer: csv.NewWriter(w)}, nil
+	case "ndjson":
+		return &ndjsonBookExporter{encoder: json.NewEncoder(w)}, nil
+	case "onix":
+		return &onixBookExporter{w: w, encoder: xml.NewEncoder(w), sender: sender}, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+var bookExportColumns = []string{
+	"id", "title", "slug", "isbn13", "isbn10", "author", "category_id", "category",
+	"publisher", "price", "currency", "rating", "rating_count", "description", "created_at", "updated_at",
+}
+
+type csvBookExporter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (ce *csvBookExporter) ContentType() string   { return "text/csv; charset=utf-8" }
+func (ce *csvBookExporter) FileExtension() string { return ".csv" }
+
+func (ce *csvBookExporter) Write(row dto.BookExportRow) error {
+	if !ce.headerWritten {
+		ce.headerWritten = true
+		err := ce.writer.Write(bookExportColumns)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ce.writer.Write([]string{
+		strconv.Itoa(row.Id),
+		row.Title,
+		row.Slug,
+		row.Isbn13,
+		row.Isbn10,
+		row.Author,
+		strconv.Itoa(row.CategoryID),
+		row.Category,
+		row.Publisher,
+		strconv.FormatInt(row.Price, 10),
+		row.Currency,
+		strconv.FormatFloat(row.Rating, 'f', 2, 64),
+		strconv.Itoa(row.RatingCount),
+		row.Description,
+		row.CreatedAt.Format(time.RFC3339),
+		row.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (ce *csvBookExporter) Close() error {
+	if !ce.headerWritten {
+		ce.headerWritten = true
+		ce.writer.Write(bookExportColumns)
+	}
+
+	ce.writer.Flush()
+	return ce.writer.Error()
+}
+
+type ndjsonBookExporter struct {
+	encoder *json.Encoder
+}
+
+func (ne *ndjsonBookExporter) ContentType() string   { return "application/x-ndjson" }
+func (ne *ndjsonBookExporter) FileExtension() string { return ".ndjson" }
+
+func (ne *ndjsonBookExporter) Write(row dto.BookExportRow) error {
+	return ne.encoder.Encode(row)
+}
+
+func (ne *ndjsonBookExporter) Close() error {
+	return nil
+}
+
+// onixBookExporter writes a simplified ONIX for Books 3.0 message: one
+// Product per book with its ISBN, title, contributors, subject, description,
+// publisher and price. It is meant for partners that ingest ONIX-shaped
+// feeds, not as a fully conformant message.
+type onixBookExporter struct {
+	w       io.Writer
+	encoder *xml.Encoder
+	sender  string
+	started bool
+}
+
+type onixProduct struct {
+	XMLName            xml.Name              `xml:"Product"`
+	RecordReference    string                `xml:"RecordReference"`
+	NotificationType   string                `xml:"NotificationType"`
+	ProductIdentifiers []onixIdentifier      `xml:"ProductIdentifier"`
+	DescriptiveDetail  onixDescriptiveDetail `xml:"DescriptiveDetail"`
+	CollateralDetail   *onixCollateralDetail `xml:"CollateralDetail,omitempty"`
+	PublishingDetail   *onixPublishingDetail `xml:"PublishingDetail,omitempty"`
+	ProductSupply      onixProductSupply     `xml:"ProductSupply"`
+}
+
+type onixIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDValue       string `xml:"IDValue"`
+}
+
+type onixDescriptiveDetail struct {
+	TitleText    string            `xml:"TitleDetail>TitleElement>TitleText"`
+	Contributors []onixContributor `xml:"Contributor"`
+	Subject      string            `xml:"Subject>SubjectHeadingText,omitempty"`
+}
+
+type onixContributor struct {
+	SequenceNumber  int    `xml:"SequenceNumber"`
+	ContributorRole string `xml:"ContributorRole"`
+	PersonName      string `xml:"PersonName"`
+}
+
+type onixCollateralDetail struct {
+	Text string `xml:"TextContent>Text"`
+}
+
+type onixPublishingDetail struct {
+	PublisherName string `xml:"Publisher>PublisherName"`
+}
+
+type onixProductSupply struct {
+	PriceAmount  string `xml:"SupplyDetail>Price>PriceAmount"`
+	CurrencyCode string `xml:"SupplyDetail>Price>CurrencyCode"`
+}
+
+func (oe *onixBookExporter) ContentType() string   { return "application/xml; charset=utf-8" }
+func (oe *onixBookExporter) FileExtension() string { return ".xml" }
+
+func (oe *onixBookExporter) start() error {
+	oe.started = true
+
+	_, err := fmt.Fprintf(oe.w, "%s<ONIXMessage release=\"3.0\">", xml.Header)
+	if err != nil {
+		return err
+	}
+
+	header := struct {
+		XMLName      xml.Name `xml:"Header"`
+		SenderName   string   `xml:"Sender>SenderName"`
+		SentDateTime string   `xml:"SentDateTime"`
+	}{
+		SenderName:   oe.sender,
+		SentDateTime: time.Now().UTC().Format("20060102T1504Z"),
+	}
+
+	return oe.encoder.Encode(header)
+}
+
+func (oe *onixBookExporter) Write(row dto.BookExportRow) error {
+	if !oe.started {
+		err := oe.start()
+		if err != nil {
+			return err
+		}
+	}
+
+	product := onixProduct{
+		RecordReference:  fmt.Sprintf("book-%d", row.Id),
+		NotificationType: "03",
+		DescriptiveDetail: onixDescriptiveDetail{
+			TitleText: row.Title,
+			Subject:   row.Category,
+		},
+		ProductSupply: onixProductSupply{
+			PriceAmount:  FormatAmount(model.NewMoney(row.Price, row.Currency)),
+			CurrencyCode: row.Currency,
+		},
+	}
+
+	// ProductIDType 15 is ISBN-13 and 01 a proprietary identifier.
+	product.ProductIdentifiers = append(product.ProductIdentifiers, onixIdentifier{ProductIDType: "01", IDValue: strconv.Itoa(row.Id)})
+	if row.Isbn13 != "" {
+		product.ProductIdentifiers = append(product.ProductIdentifiers, onixIdentifier{ProductIDType: "15", IDValue: row.Isbn13})
+	}
+
+	for i, contributor := range row.Contributors {
+		product.DescriptiveDetail.Contributors = append(product.DescriptiveDetail.Contributors, onixContributor{
+			SequenceNumber:  i + 1,
+			ContributorRole: onixContributorRole(contributor.Role),
+			PersonName:      contributor.Name,
+		})
+	}
+
+	if row.Description != "" {
+		product.CollateralDetail = &onixCollateralDetail{Text: row.Description}
+	}
+
+	if row.Publisher != "" {
+		product.PublishingDetail = &onixPublishingDetail{PublisherName: row.Publisher}
+	}
+
+	return oe.encoder.Encode(product)
+}
+
+// onixContributorRole maps an author role to ONIX code list 17: A01 is
+// "by (author)", B01 "edited by" and B06 "translated by".
+func onixContributorRole(role string) string {
+	switch role {
+	case model.AuthorRoleEditor:
+		return "B01"
+	case model.AuthorRoleTranslator:
+		return "B06"
+	default:
+		return "A01"
+	}
+}
+
+func (oe *onixBookExporter) Close() error {
+	if !oe.started {
+		err := oe.start()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := oe.encoder.Flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(oe.w, "</ONIXMessage>\n")
+	return err
+}
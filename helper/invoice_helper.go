@@ -0,0 +1,141 @@
+package helper
+
+import (
+	"fmt"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+)
+
+// InvoiceDocument is everything printed on an invoice or credit note.
+type InvoiceDocument struct {
+	Invoice  model.Invoice
+	Corrects string
+	Seller   []string
+	Buyer    []string
+	Lines    []InvoiceLine
+	TaxLines []InvoiceLine
+}
+
+// InvoiceLine is a row of the document. Qty and UnitPrice are left out of
+// tax lines.
+type InvoiceLine struct {
+	Description string
+	Qty         int
+	UnitPrice   int64
+	Amount      int64
+}
+
+const (
+	invoiceMargin    = 50.0
+	invoiceRight     = PDFPageWidth - invoiceMargin
+	invoiceBottom    = 90.0
+	invoiceRowHeight = 16.0
+	invoiceFontSize  = 10.0
+)
+
+// RenderInvoice lays the document out on A4 pages: the title and number,
+// seller and buyer, a table of lines that continues on as many pages as it
+// needs, and the totals with the tax lines.
+func RenderInvoice(doc InvoiceDocument) []byte {
+	invoice := doc.Invoice
+	money := func(amount int64) string {
+		return FormatAmount(model.NewMoney(amount, invoice.Currency))
+	}
+
+	pdf := NewPDF()
+
+	title := "INVOICE"
+	if invoice.Kind == model.InvoiceKindCreditNote {
+		title = "CREDIT NOTE"
+	}
+
+	y := PDFPageHeight - invoiceMargin - 20
+	pdf.Text(invoiceMargin, y, 20, true, title)
+
+	details := []string{
+		"No. " + invoice.Number,
+		"Date " + invoice.IssuedAt.Format("2006-01-02"),
+		fmt.Sprintf("Order #%d", invoice.OrderID),
+	}
+	if doc.Corrects != "" {
+		details = append(details, "Corrects "+doc.Corrects)
+	}
+	for i, detail := range details {
+		pdf.TextRight(invoiceRight, y-float64(i)*14, invoiceFontSize, i == 0, detail)
+	}
+
+	y -= 20 + float64(len(details))*14
+	pdf.Text(invoiceMargin, y, invoiceFontSize, true, "From")
+	pdf.Text(PDFPageWidth/2, y, invoiceFontSize, true, "Bill to")
+	for i := 0; i < len(doc.Seller) || i < len(doc.Buyer); i++ {
+		y -= 14
+		if i < len(doc.Seller) {
+			pdf.Text(invoiceMargin, y, invoiceFontSize, false, PDFFit(doc.Seller[i], invoiceFontSize, PDFPageWidth/2-invoiceMargin-10))
+		}
+		if i < len(doc.Buyer) {
+			pdf.Text(PDFPageWidth/2, y, invoiceFontSize, false, PDFFit(doc.Buyer[i], invoiceFontSize, invoiceRight-PDFPageWidth/2))
+		}
+	}
+
+	columns := [3]float64{invoiceRight - 200, invoiceRight - 100, invoiceRight}
+	header := func() {
+		y -= 30
+		pdf.Text(invoiceMargin, y, invoiceFontSize, true, "Description")
+		pdf.TextRight(columns[0], y, invoiceFontSize, true, "Qty")
+		pdf.TextRight(columns[1], y, invoiceFontSize, true, "Unit price")
+		pdf.TextRight(columns[2], y, invoiceFontSize, true, "Amount ("+invoice.Currency+")")
+		pdf.Line(invoiceMargin, y-5, invoiceRight, y-5)
+		y -= 6
+	}
+	header()
+
+	for _, line := range doc.Lines {
+		if y-invoiceRowHeight < invoiceBottom {
+			pdf.AddPage()
+			y = PDFPageHeight - invoiceMargin
+			pdf.Text(invoiceMargin, y, invoiceFontSize, false, fmt.Sprintf("%s %s (continued)", title, invoice.Number))
+			header()
+		}
+
+		y -= invoiceRowHeight
+		pdf.Text(invoiceMargin, y, invoiceFontSize, false, PDFFit(line.Description, invoiceFontSize, columns[0]-invoiceMargin-40))
+		pdf.TextRight(columns[0], y, invoiceFontSize, false, fmt.Sprint(line.Qty))
+		pdf.TextRight(columns[1], y, invoiceFontSize, false, money(line.UnitPrice))
+		pdf.TextRight(columns[2], y, invoiceFontSize, false, money(line.Amount))
+	}
+
+	totals := []InvoiceLine{}
+	if invoice.Kind == model.InvoiceKindInvoice {
+		totals = append(totals, InvoiceLine{Description: "Subtotal", Amount: invoice.Subtotal})
+		if invoice.TotalDiscount > 0 {
+			totals = append(totals, InvoiceLine{Description: "Discount", Amount: -invoice.TotalDiscount})
+		}
+		if invoice.ShippingTotal > 0 {
+			totals = append(totals, InvoiceLine{Description: "Shipping", Amount: invoice.ShippingTotal})
+		}
+	}
+	totals = append(totals, doc.TaxLines...)
+
+	if y-invoiceRowHeight*float64(len(totals)+2) < invoiceBottom {
+		pdf.AddPage()
+		y = PDFPageHeight - invoiceMargin
+	}
+
+	y -= 10
+	pdf.Line(invoiceMargin, y, invoiceRight, y)
+	for _, total := range totals {
+		y -= invoiceRowHeight
+		pdf.TextRight(columns[1], y, invoiceFontSize, false, total.Description)
+		pdf.TextRight(columns[2], y, invoiceFontSize, false, money(total.Amount))
+	}
+
+	y -= invoiceRowHeight + 4
+	label := "Total"
+	if invoice.Kind == model.InvoiceKindCreditNote {
+		label = "Total credited"
+	}
+	pdf.TextRight(columns[1], y, invoiceFontSize+1, true, label)
+	pdf.TextRight(columns[2], y, invoiceFontSize+1, true, invoice.Currency+" "+money(invoice.Total))
+
+	return pdf.Bytes()
+}
@@ -0,0 +1,104 @@
+package helper
+
+import (
+	"errors"
+	"strings"
+)
+
+// NormalizeISBN accepts an ISBN-10 or ISBN-13 written with or without hyphens
+// and spaces, verifies its check digit and returns the ISBN-13 form, which is
+// what books are stored and looked up by.
+func NormalizeISBN(raw string) (string, error) {
+	isbn := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(raw)))
+
+	switch len(isbn) {
+	case 10:
+		if !ValidISBN10(isbn) {
+			return "", errors.New("invalid ISBN-10 check digit")
+		}
+		return ISBN10To13(isbn), nil
+	case 13:
+		if !ValidISBN13(isbn) {
+			return "", errors.New("invalid ISBN-13 check digit")
+		}
+		return isbn, nil
+	default:
+		return "", errors.New("ISBN must have 10 or 13 digits")
+	}
+}
+
+func ValidISBN10(isbn string) bool {
+	if len(isbn) != 10 {
+		return false
+	}
+
+	sum := 0
+	for i, r := range isbn {
+		var digit int
+		switch {
+		case r >= '0' && r <= '9':
+			digit = int(r - '0')
+		case r == 'X' && i == 9:
+			digit = 10
+		default:
+			return false
+		}
+		sum += digit * (10 - i)
+	}
+
+	return sum%11 == 0
+}
+
+func ValidISBN13(isbn string) bool {
+	if len(isbn) != 13 {
+		return false
+	}
+
+	for _, r := range isbn {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return isbn13CheckDigit(isbn[:12]) == isbn[12]
+}
+
+// ISBN10To13 prefixes a valid ISBN-10 with 978 and recomputes the check digit.
+func ISBN10To13(isbn10 string) string {
+	body := "978" + isbn10[:9]
+	return body + string(isbn13CheckDigit(body))
+}
+
+// ISBN13To10 converts a 978-prefixed ISBN-13 back to ISBN-10. ISBN-13s in the
+// 979 range have no ISBN-10 equivalent and report false.
+func ISBN13To10(isbn13 string) (string, bool) {
+	if len(isbn13) != 13 || !strings.HasPrefix(isbn13, "978") {
+		return "", false
+	}
+
+	body := isbn13[3:12]
+	sum := 0
+	for i, r := range body {
+		sum += int(r-'0') * (10 - i)
+	}
+
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return body + "X", true
+	}
+
+	return body + string(rune('0'+check)), true
+}
+
+func isbn13CheckDigit(body string) byte {
+	sum := 0
+	for i, r := range body {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(r-'0') * weight
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,112 @@
+package helper
+
+import "testing"
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "isbn-13", raw: "9780306406157", want: "9780306406157"},
+		{name: "isbn-13 with hyphens", raw: "978-0-306-40615-7", want: "9780306406157"},
+		{name: "isbn-13 with spaces", raw: " 978 0 306 40615 7 ", want: "9780306406157"},
+		{name: "isbn-10", raw: "0306406152", want: "9780306406157"},
+		{name: "isbn-10 with hyphens", raw: "0-306-40615-2", want: "9780306406157"},
+		{name: "isbn-10 check digit x", raw: "080442957X", want: "9780804429573"},
+		{name: "isbn-10 lowercase x", raw: "080442957x", want: "9780804429573"},
+		{name: "979 isbn-13", raw: "979-10-90636-07-1", want: "9791090636071"},
+		{name: "bad isbn-13 check digit", raw: "9780306406158", wantErr: true},
+		{name: "bad isbn-10 check digit", raw: "0306406153", wantErr: true},
+		{name: "x inside isbn-10", raw: "03064X6152", wantErr: true},
+		{name: "letters in isbn-13", raw: "97803064061A7", wantErr: true},
+		{name: "too short", raw: "030640615", wantErr: true},
+		{name: "too long", raw: "97803064061570", wantErr: true},
+		{name: "empty", raw: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeISBN(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NormalizeISBN(%q) = %q, want error", tt.raw, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NormalizeISBN(%q) error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeISBN(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidISBN10(t *testing.T) {
+	tests := []struct {
+		isbn string
+		want bool
+	}{
+		{isbn: "0306406152", want: true},
+		{isbn: "080442957X", want: true},
+		{isbn: "0306406153", want: false},
+		{isbn: "X306406152", want: false},
+		{isbn: "030640615", want: false},
+		{isbn: "03064061520", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidISBN10(tt.isbn); got != tt.want {
+			t.Errorf("ValidISBN10(%q) = %v, want %v", tt.isbn, got, tt.want)
+		}
+	}
+}
+
+func TestValidISBN13(t *testing.T) {
+	tests := []struct {
+		isbn string
+		want bool
+	}{
+		{isbn: "9780306406157", want: true},
+		{isbn: "9791090636071", want: true},
+		{isbn: "9780306406150", want: false},
+		{isbn: "978030640615X", want: false},
+		{isbn: "978030640615", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidISBN13(tt.isbn); got != tt.want {
+			t.Errorf("ValidISBN13(%q) = %v, want %v", tt.isbn, got, tt.want)
+		}
+	}
+}
+
+func TestISBNConversion(t *testing.T) {
+	tests := []struct {
+		isbn10 string
+		isbn13 string
+	}{
+		{isbn10: "0306406152", isbn13: "9780306406157"},
+		{isbn10: "080442957X", isbn13: "9780804429573"},
+		{isbn10: "0198526636", isbn13: "9780198526636"},
+	}
+
+	for _, tt := range tests {
+		if got := ISBN10To13(tt.isbn10); got != tt.isbn13 {
+			t.Errorf("ISBN10To13(%q) = %q, want %q", tt.isbn10, got, tt.isbn13)
+		}
+
+		got, ok := ISBN13To10(tt.isbn13)
+		if !ok || got != tt.isbn10 {
+			t.Errorf("ISBN13To10(%q) = %q, %v, want %q, true", tt.isbn13, got, ok, tt.isbn10)
+		}
+	}
+
+	if got, ok := ISBN13To10("9791090636071"); ok {
+		t.Errorf("ISBN13To10 of a 979 ISBN = %q, true, want false", got)
+	}
+}
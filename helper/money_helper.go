@@ -0,0 +1,66 @@
+package helper
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// currencyExponents lists the ISO 4217 currencies whose minor unit is not a
+// hundredth of the major unit. IDR officially has two decimals, but the sen
+// is out of use and rupiah prices have always been stored as whole rupiah.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IDR": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// ValidCurrencyCode reports whether code looks like an ISO 4217 code.
+func ValidCurrencyCode(code string) bool {
+	return currencyCodePattern.MatchString(code)
+}
+
+// CurrencyExponent returns the number of decimals of the currency's minor
+// unit, 2 for most currencies.
+func CurrencyExponent(code string) int {
+	exponent, ok := currencyExponents[code]
+	if !ok {
+		return 2
+	}
+	return exponent
+}
+
+// ConvertMoney converts the amount into another currency, where rate is how
+// many units of the target currency one unit of the source currency buys.
+// The result is rounded half away from zero to the target's minor unit.
+func ConvertMoney(money model.Money, currency string, rate float64) model.Money {
+	if money.Currency == currency {
+		return money
+	}
+
+	major := float64(money.Amount) / math.Pow10(CurrencyExponent(money.Currency))
+	amount := math.Round(major * rate * math.Pow10(CurrencyExponent(currency)))
+
+	return model.NewMoney(int64(amount), currency)
+}
+
+// FormatAmount renders the amount in major units, e.g. "12.50" for 1250
+// US cents.
+func FormatAmount(money model.Money) string {
+	exponent := CurrencyExponent(money.Currency)
+	major := float64(money.Amount) / math.Pow10(exponent)
+
+	return strconv.FormatFloat(major, 'f', exponent, 64)
+}
+
+// FormatMoney renders the amount in major units with its currency code, e.g.
+// "USD 12.50", for explanations shown to customers.
+func FormatMoney(money model.Money) string {
+	return fmt.Sprintf("%s %s", money.Currency, FormatAmount(money))
+}
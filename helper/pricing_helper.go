@@ -0,0 +1,382 @@
+package helper
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+)
+
+// PricingRule is a promotion together with the test for which cart items are
+// in its scope. Value is the promotion's value in the cart currency: the
+// percentage for percentage and buy_x_get_y promotions, and the converted
+// amount for fixed and bundle promotions.
+type PricingRule struct {
+	Promotion model.Promotion
+	Value     int64
+	Matches   func(item model.Item) bool
+}
+
+// PricingResult is what the promotions took off a cart. Remaining holds what
+// is left to pay per variant once they are applied.
+type PricingResult struct {
+	Discounts []model.Discount
+	Hints     []string
+	Remaining map[int]int64
+}
+
+// pricingLot is a number of copies of a cart item that have the same amount
+// left to pay and the same claim, so a rule can discount some copies of a
+// line and leave the others alone without the cart being priced copy by
+// copy.
+type pricingLot struct {
+	item      model.Item
+	qty       int
+	remaining int64
+	claimed   bool
+}
+
+// pricingTake is the part of a lot a deal was built from and what the deal
+// takes off each of those copies, which may be nothing for the copies paid
+// in full to qualify for it.
+type pricingTake struct {
+	lot *pricingLot
+	qty int
+	off int64
+}
+
+// ApplyPromotions runs the promotions over the cart items. Rules are applied
+// by descending priority and then by promotion id whatever order they are
+// passed in, and within a rule items are taken most expensive first, so the
+// same cart always gets the same discounts. Every type of promotion stacks
+// the same way: a promotion that is not stackable claims the copies of the
+// deals it gave a discount on, and claimed copies are left out of the
+// promotions applied after it. Amounts are in currency.
+func ApplyPromotions(items []model.Item, rules []PricingRule, currency string) PricingResult {
+	result := PricingResult{
+		Discounts: []model.Discount{},
+		Remaining: map[int]int64{},
+	}
+
+	var lots []*pricingLot
+	for _, item := range items {
+		if item.Qty > 0 {
+			lots = append(lots, &pricingLot{item: item, qty: item.Qty, remaining: item.Price.Amount})
+		}
+	}
+
+	ordered := append([]PricingRule{}, rules...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Promotion.Priority != ordered[j].Promotion.Priority {
+			return ordered[i].Promotion.Priority > ordered[j].Promotion.Priority
+		}
+		return ordered[i].Promotion.ID < ordered[j].Promotion.ID
+	})
+
+	for _, rule := range ordered {
+		var candidates []*pricingLot
+		for _, lot := range lots {
+			if !lot.claimed && lot.remaining > 0 && rule.Matches(lot.item) {
+				candidates = append(candidates, lot)
+			}
+		}
+
+		if len(candidates) == 0 {
+			continue
+		}
+
+		discount, takes, hint := applyPromotion(rule, candidates, currency)
+		if hint != "" {
+			result.Hints = append(result.Hints, hint)
+		}
+
+		if discount == nil {
+			continue
+		}
+
+		lots = splitLots(lots, takes, !rule.Promotion.Stackable)
+		result.Discounts = append(result.Discounts, *discount)
+	}
+
+	for _, lot := range lots {
+		result.Remaining[lot.item.VariantId] += lot.remaining * int64(lot.qty)
+	}
+
+	return result
+}
+
+// applyPromotion works out the promotion's deals on the candidate lots and
+// returns the discount line, the copies of the deals that gave a discount
+// and a hint when more items would unlock it again.
+func applyPromotion(rule PricingRule, candidates []*pricingLot, currency string) (*model.Discount, []pricingTake, string) {
+	promotion := rule.Promotion
+	var takes []pricingTake
+	hint := ""
+	explanation := ""
+
+	copies := 0
+	for _, lot := range candidates {
+		copies += lot.qty
+	}
+
+	switch promotion.Type {
+	case model.PromotionTypePercentage:
+		for _, lot := range candidates {
+			if off := lot.remaining * rule.Value / 100; off > 0 {
+				takes = append(takes, pricingTake{lot: lot, qty: lot.qty, off: off})
+			}
+		}
+		explanation = fmt.Sprintf("%d%% off %d matching %s", rule.Value, copies, pluralItems(copies))
+
+	case model.PromotionTypeFixed:
+		for _, lot := range candidates {
+			if off := min(rule.Value, lot.remaining); off > 0 {
+				takes = append(takes, pricingTake{lot: lot, qty: lot.qty, off: off})
+			}
+		}
+		explanation = fmt.Sprintf("%s off each of %d matching %s", FormatMoney(model.NewMoney(rule.Value, currency)), copies, pluralItems(copies))
+
+	case model.PromotionTypeBuyXGetY:
+		size := promotion.BuyQty + promotion.GetQty
+		sortLotsByPrice(candidates)
+
+		eachGroup(candidates, size, func(group []pricingTake, times int) {
+			// the cheapest copies of each group are the ones given away
+			var deal []pricingTake
+			var off int64
+			position := 0
+			for _, part := range group {
+				paid := max(0, min(part.qty, promotion.BuyQty-position))
+				if paid > 0 {
+					deal = append(deal, pricingTake{lot: part.lot, qty: paid * times})
+				}
+
+				if free := part.qty - paid; free > 0 {
+					freeOff := part.lot.remaining * rule.Value / 100
+					deal = append(deal, pricingTake{lot: part.lot, qty: free * times, off: freeOff})
+					off += freeOff
+				}
+				position += part.qty
+			}
+
+			if off > 0 {
+				takes = append(takes, deal...)
+			}
+		})
+
+		groups := copies / size
+		reward := "free"
+		if rule.Value < 100 {
+			reward = fmt.Sprintf("%d%% off", rule.Value)
+		}
+		explanation = fmt.Sprintf("buy %d get %d %s, applied %d %s", promotion.BuyQty, promotion.GetQty, reward, groups, pluralTimes(groups))
+
+		if leftover := copies % size; leftover > 0 {
+			hint = fmt.Sprintf("add %d more matching %s to get %s", size-leftover, pluralItems(size-leftover), promotion.Name)
+		}
+
+	case model.PromotionTypeBundle:
+		size := promotion.BuyQty
+		sortLotsByPrice(candidates)
+
+		groups := 0
+		eachGroup(candidates, size, func(group []pricingTake, times int) {
+			var sum int64
+			for _, part := range group {
+				sum += part.lot.remaining * int64(part.qty)
+			}
+
+			off := sum - rule.Value
+			if off <= 0 {
+				return
+			}
+			groups += times
+
+			// spread the saving over the bundle by price, giving the rounding
+			// remainder to the last copy
+			var spread int64
+			for j, part := range group {
+				share := off * part.lot.remaining / sum
+				spread += share * int64(part.qty)
+
+				if j < len(group)-1 {
+					takes = append(takes, pricingTake{lot: part.lot, qty: part.qty * times, off: share})
+					continue
+				}
+
+				if part.qty > 1 {
+					takes = append(takes, pricingTake{lot: part.lot, qty: (part.qty - 1) * times, off: share})
+				}
+				takes = append(takes, pricingTake{lot: part.lot, qty: times, off: share + off - spread})
+			}
+		})
+		explanation = fmt.Sprintf("%d items for %s, applied %d %s", size, FormatMoney(model.NewMoney(rule.Value, currency)), groups, pluralTimes(groups))
+
+		if leftover := copies % size; leftover > 0 {
+			hint = fmt.Sprintf("add %d more matching %s to get %s", size-leftover, pluralItems(size-leftover), promotion.Name)
+		}
+	}
+
+	var total int64
+	variantIds := []int{}
+	seen := map[int]bool{}
+	for _, take := range takes {
+		if take.off <= 0 {
+			continue
+		}
+		total += take.off * int64(take.qty)
+
+		if !seen[take.lot.item.VariantId] {
+			seen[take.lot.item.VariantId] = true
+			variantIds = append(variantIds, take.lot.item.VariantId)
+		}
+	}
+
+	if total == 0 {
+		return nil, nil, hint
+	}
+
+	return &model.Discount{
+		Source:      model.DiscountSourcePromotion,
+		PromotionId: promotion.ID,
+		Label:       promotion.Name,
+		Explanation: explanation,
+		Amount:      model.NewMoney(total, currency),
+		VariantIds:  variantIds,
+	}, takes, hint
+}
+
+// eachGroup walks the copies of the lots in order in groups of size and
+// calls fn with the parts of the lots in each group. Consecutive groups made
+// of the same lot are passed once, with times saying how many they are.
+// Copies that do not fill a last group are left out.
+func eachGroup(lots []*pricingLot, size int, fn func(group []pricingTake, times int)) {
+	copies := 0
+	for _, lot := range lots {
+		copies += lot.qty
+	}
+
+	i, grouped := 0, 0
+	for groups := copies / size; groups > 0; {
+		if left := lots[i].qty - grouped; left >= size {
+			times := min(left/size, groups)
+			fn([]pricingTake{{lot: lots[i], qty: size}}, times)
+			grouped += times * size
+			groups -= times
+		} else {
+			var group []pricingTake
+			for need := size; need > 0; {
+				left := lots[i].qty - grouped
+				if left == 0 {
+					i, grouped = i+1, 0
+					continue
+				}
+
+				n := min(left, need)
+				group = append(group, pricingTake{lot: lots[i], qty: n})
+				grouped += n
+				need -= n
+			}
+			fn(group, 1)
+			groups--
+		}
+
+		if grouped == lots[i].qty {
+			i, grouped = i+1, 0
+		}
+	}
+}
+
+// splitLots takes the copies of a deal out of their lots into lots of their
+// own with the discount taken off, claimed when claim is set. Lots left with
+// the same item, amount and claim are merged again, so a cart has at most a
+// few lots per line however many promotions ran over it.
+func splitLots(lots []*pricingLot, takes []pricingTake, claim bool) []*pricingLot {
+	taken := map[*pricingLot][]pricingTake{}
+	for _, take := range takes {
+		taken[take.lot] = append(taken[take.lot], take)
+	}
+
+	type lotKey struct {
+		variantId int
+		remaining int64
+		claimed   bool
+	}
+	merged := map[lotKey]*pricingLot{}
+	var split []*pricingLot
+
+	add := func(lot pricingLot) {
+		key := lotKey{lot.item.VariantId, lot.remaining, lot.claimed}
+		if existing, ok := merged[key]; ok {
+			existing.qty += lot.qty
+			return
+		}
+
+		merged[key] = &lot
+		split = append(split, &lot)
+	}
+
+	for _, lot := range lots {
+		left := lot.qty
+		for _, take := range taken[lot] {
+			add(pricingLot{item: lot.item, qty: take.qty, remaining: lot.remaining - take.off, claimed: lot.claimed || claim})
+			left -= take.qty
+		}
+
+		if left > 0 {
+			add(pricingLot{item: lot.item, qty: left, remaining: lot.remaining, claimed: lot.claimed})
+		}
+	}
+
+	return split
+}
+
+// sortLotsByPrice orders lots from the most to the least expensive copy,
+// with the variant id breaking ties.
+func sortLotsByPrice(lots []*pricingLot) {
+	sort.SliceStable(lots, func(i, j int) bool {
+		if lots[i].remaining != lots[j].remaining {
+			return lots[i].remaining > lots[j].remaining
+		}
+		return lots[i].item.VariantId < lots[j].item.VariantId
+	})
+}
+
+func pluralItems(n int) string {
+	if n == 1 {
+		return "item"
+	}
+	return "items"
+}
+
+func pluralTimes(n int) string {
+	if n == 1 {
+		return "time"
+	}
+	return "times"
+}
+
+// AllocateDiscount spreads a discount given on several items over them in
+// proportion to what is left to pay for each, taking it off remaining. The
+// rounding difference goes to the last item so the shares add up to amount.
+func AllocateDiscount(remaining map[int]int64, variantIds []int, amount int64) {
+	var total int64
+	for _, variantId := range variantIds {
+		total += remaining[variantId]
+	}
+
+	if total == 0 {
+		return
+	}
+
+	var allocated int64
+	for i, variantId := range variantIds {
+		share := amount * remaining[variantId] / total
+		if i == len(variantIds)-1 {
+			share = amount - allocated
+		}
+
+		remaining[variantId] -= share
+		allocated += share
+	}
+}
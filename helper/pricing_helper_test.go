@@ -0,0 +1,163 @@
+package helper
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+)
+
+func pricingItem(variantId int, price int64, qty int) model.Item {
+	return model.Item{VariantId: variantId, Price: model.NewMoney(price, "USD"), Qty: qty}
+}
+
+func pricingRule(promotion model.Promotion) PricingRule {
+	return PricingRule{
+		Promotion: promotion,
+		Value:     int64(promotion.Value),
+		Matches:   func(item model.Item) bool { return true },
+	}
+}
+
+func TestApplyPromotions(t *testing.T) {
+	type discount struct {
+		promotionId int
+		amount      int64
+	}
+
+	tests := []struct {
+		name      string
+		items     []model.Item
+		rules     []PricingRule
+		want      []discount
+		remaining map[int]int64
+		hints     int
+	}{
+		{
+			name:  "higher priority first whatever the order passed in",
+			items: []model.Item{pricingItem(1, 1000, 1)},
+			rules: []PricingRule{
+				pricingRule(model.Promotion{ID: 1, Type: model.PromotionTypePercentage, Value: 10}),
+				pricingRule(model.Promotion{ID: 2, Type: model.PromotionTypePercentage, Value: 50, Priority: 5}),
+			},
+			want:      []discount{{2, 500}},
+			remaining: map[int]int64{1: 500},
+		},
+		{
+			name:  "lower promotion id first on equal priority",
+			items: []model.Item{pricingItem(1, 1000, 1)},
+			rules: []PricingRule{
+				pricingRule(model.Promotion{ID: 7, Type: model.PromotionTypePercentage, Value: 20, Priority: 1}),
+				pricingRule(model.Promotion{ID: 3, Type: model.PromotionTypePercentage, Value: 10, Priority: 1}),
+			},
+			want:      []discount{{3, 100}},
+			remaining: map[int]int64{1: 900},
+		},
+		{
+			name:  "stackable promotion leaves the rest to later ones",
+			items: []model.Item{pricingItem(1, 1000, 2)},
+			rules: []PricingRule{
+				pricingRule(model.Promotion{ID: 1, Type: model.PromotionTypePercentage, Value: 10, Priority: 2, Stackable: true}),
+				pricingRule(model.Promotion{ID: 2, Type: model.PromotionTypeFixed, Value: 100, Priority: 1}),
+			},
+			want:      []discount{{1, 200}, {2, 200}},
+			remaining: map[int]int64{1: 1600},
+		},
+		{
+			name:  "buy x get y gives away the cheapest copies",
+			items: []model.Item{pricingItem(1, 300, 1), pricingItem(2, 200, 1), pricingItem(3, 100, 1)},
+			rules: []PricingRule{
+				pricingRule(model.Promotion{ID: 1, Type: model.PromotionTypeBuyXGetY, Value: 100, BuyQty: 2, GetQty: 1}),
+			},
+			want:      []discount{{1, 100}},
+			remaining: map[int]int64{1: 300, 2: 200, 3: 0},
+		},
+		{
+			name:  "equal prices give away the higher variant id",
+			items: []model.Item{pricingItem(2, 500, 1), pricingItem(1, 500, 1)},
+			rules: []PricingRule{
+				pricingRule(model.Promotion{ID: 1, Type: model.PromotionTypeBuyXGetY, Value: 100, BuyQty: 1, GetQty: 1}),
+			},
+			want:      []discount{{1, 500}},
+			remaining: map[int]int64{1: 500, 2: 0},
+		},
+		{
+			name:  "non-stackable buy x get y claims the copies paid for too",
+			items: []model.Item{pricingItem(1, 500, 2), pricingItem(2, 100, 1)},
+			rules: []PricingRule{
+				pricingRule(model.Promotion{ID: 1, Type: model.PromotionTypeBuyXGetY, Value: 100, BuyQty: 1, GetQty: 1, Priority: 2}),
+				pricingRule(model.Promotion{ID: 2, Type: model.PromotionTypePercentage, Value: 10, Priority: 1}),
+			},
+			want:      []discount{{1, 500}, {2, 10}},
+			remaining: map[int]int64{1: 500, 2: 90},
+			hints:     1,
+		},
+		{
+			name:  "bundle without a saving claims nothing",
+			items: []model.Item{pricingItem(1, 300, 1), pricingItem(2, 200, 1)},
+			rules: []PricingRule{
+				pricingRule(model.Promotion{ID: 1, Type: model.PromotionTypeBundle, Value: 600, BuyQty: 2, Priority: 2}),
+				pricingRule(model.Promotion{ID: 2, Type: model.PromotionTypePercentage, Value: 10, Priority: 1}),
+			},
+			want:      []discount{{2, 50}},
+			remaining: map[int]int64{1: 270, 2: 180},
+		},
+		{
+			name:  "bundle spreads the saving by price",
+			items: []model.Item{pricingItem(1, 300, 1), pricingItem(2, 200, 1), pricingItem(3, 100, 1)},
+			rules: []PricingRule{
+				pricingRule(model.Promotion{ID: 1, Type: model.PromotionTypeBundle, Value: 450, BuyQty: 3}),
+			},
+			want:      []discount{{1, 150}},
+			remaining: map[int]int64{1: 225, 2: 150, 3: 75},
+		},
+		{
+			name:  "bundle groups spanning lines",
+			items: []model.Item{pricingItem(1, 400, 3), pricingItem(2, 100, 1)},
+			rules: []PricingRule{
+				pricingRule(model.Promotion{ID: 1, Type: model.PromotionTypeBundle, Value: 500, BuyQty: 2}),
+			},
+			want:      []discount{{1, 300}},
+			remaining: map[int]int64{1: 900, 2: 100},
+		},
+		{
+			name:  "large quantities are priced without expanding copies",
+			items: []model.Item{pricingItem(1, 1000, 1_000_000_000)},
+			rules: []PricingRule{
+				pricingRule(model.Promotion{ID: 1, Type: model.PromotionTypeBuyXGetY, Value: 100, BuyQty: 2, GetQty: 1}),
+			},
+			want:      []discount{{1, 333_333_333_000}},
+			remaining: map[int]int64{1: 666_666_667_000},
+			hints:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ApplyPromotions(tt.items, tt.rules, "USD")
+
+			var got []discount
+			for _, d := range result.Discounts {
+				got = append(got, discount{d.PromotionId, d.Amount.Amount})
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("discounts = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("discounts = %v, want %v", got, tt.want)
+					break
+				}
+			}
+
+			if !maps.Equal(result.Remaining, tt.remaining) {
+				t.Errorf("remaining = %v, want %v", result.Remaining, tt.remaining)
+			}
+
+			if len(result.Hints) != tt.hints {
+				t.Errorf("hints = %q, want %d", result.Hints, tt.hints)
+			}
+		})
+	}
+}
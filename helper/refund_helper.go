@@ -0,0 +1,25 @@
+package helper
+
+import "github.com/mhmmmdrivaldhi/go-book-api/model"
+
+// ReturnValue is what the customer paid for qty copies of an order item: the
+// item price less its share of the order's discounts, plus the exclusive tax
+// charged on those copies. Shipping is not part of it.
+func ReturnValue(order model.Order, item model.OrderItem, qty int) int64 {
+	gross := item.Price * int64(qty)
+
+	discount := int64(0)
+	if order.Subtotal > 0 {
+		discount = (order.TotalDiscount*gross + order.Subtotal/2) / order.Subtotal
+	}
+
+	tax := int64(0)
+	for _, line := range order.Taxes {
+		if line.VariantID != item.VariantID || line.Inclusive || item.Qty == 0 {
+			continue
+		}
+		tax += (line.Amount*int64(qty) + int64(item.Qty)/2) / int64(item.Qty)
+	}
+
+	return gross - discount + tax
+}
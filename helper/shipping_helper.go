@@ -0,0 +1,44 @@
+package helper
+
+import "github.com/mhmmmdrivaldhi/go-book-api/model"
+
+// ShipsTo reports whether the method delivers to the country. Methods
+// without a zone deliver everywhere.
+func ShipsTo(method model.ShippingMethod, country string) bool {
+	if method.Zone == nil {
+		return true
+	}
+
+	for _, zoneCountry := range method.Zone.Countries {
+		if zoneCountry.Country == country {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ShippingCost is what the method charges, in the base currency, for a
+// parcel of the given weight in grams. It returns false when the parcel is
+// heavier than every weight bracket of the method.
+func ShippingCost(method model.ShippingMethod, weight int) (int64, bool) {
+	if method.Type != model.ShippingTypeWeight {
+		return method.FlatRate, true
+	}
+
+	var best *model.ShippingRate
+	for i, rate := range method.Rates {
+		if rate.MaxWeight < weight {
+			continue
+		}
+		if best == nil || rate.MaxWeight < best.MaxWeight {
+			best = &method.Rates[i]
+		}
+	}
+
+	if best == nil {
+		return 0, false
+	}
+
+	return best.Price, true
+}
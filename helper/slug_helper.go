@@ -0,0 +1,69 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Slugify turns a title into a lowercase, dash separated slug. Letters with
+// diacritics are folded to their ASCII base where possible and anything else
+// that is not a letter or digit becomes a separator.
+func Slugify(text string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(text) {
+		if folded, ok := foldDiacritic[r]; ok {
+			r = folded
+		}
+
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || (r > unicode.MaxASCII && unicode.IsLetter(r)) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if runes := []rune(slug); len(runes) > 200 {
+		slug = strings.TrimSuffix(string(runes[:200]), "-")
+	}
+	if slug == "" {
+		slug = "untitled"
+	}
+
+	return slug
+}
+
+// UniqueSlug returns base, or base-2, base-3, ... for the first candidate that
+// taken reports as free.
+func UniqueSlug(base string, taken func(slug string) (bool, error)) (string, error) {
+	candidate := base
+	for i := 2; ; i++ {
+		exists, err := taken(candidate)
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return candidate, nil
+		}
+
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+var foldDiacritic = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'ç': 'c', 'č': 'c',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i',
+	'ñ': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'š': 's', 'ž': 'z',
+}
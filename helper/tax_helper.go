@@ -0,0 +1,52 @@
+package helper
+
+import (
+	"math"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+)
+
+// MatchTaxRule picks the rule of a country that applies to a product class
+// in a region. A rule for the region beats one for the whole country, and a
+// rule for the class beats one for every class. It returns nil when no rule
+// applies and the line is not taxed.
+func MatchTaxRule(rules []model.TaxRule, region, productClass string) *model.TaxRule {
+	var best *model.TaxRule
+	bestScore := -1
+
+	for i, rule := range rules {
+		if rule.Region != "" && rule.Region != region {
+			continue
+		}
+		if rule.ProductClass != "" && rule.ProductClass != productClass {
+			continue
+		}
+
+		score := 0
+		if rule.Region != "" {
+			score += 2
+		}
+		if rule.ProductClass != "" {
+			score++
+		}
+
+		if score > bestScore {
+			best = &rules[i]
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
+// CalculateTax returns the tax on an amount. For inclusive rules the tax is
+// the part of the amount above its net value, for exclusive rules it is
+// added on top. Both are rounded half away from zero to the minor unit.
+func CalculateTax(amount int64, rule model.TaxRule) int64 {
+	if rule.Inclusive {
+		net := math.Round(float64(amount) * 100 / (100 + rule.Rate))
+		return amount - int64(net)
+	}
+
+	return int64(math.Round(float64(amount) * rule.Rate / 100))
+}
@@ -0,0 +1,134 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+)
+
+// RegisterValidations hooks the custom rules into gin's binding validator and
+// makes field errors report the json (or query) name instead of the Go struct
+// field.
+func RegisterValidations() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	// An empty ISBN passes, so an update can clear it.
+	v.RegisterValidation("isbn_any", func(fl validator.FieldLevel) bool {
+		if fl.Field().String() == "" {
+			return true
+		}
+
+		_, err := NormalizeISBN(fl.Field().String())
+		return err == nil
+	})
+}
+
+// AbortWithBindError answers 422 with one entry per failing field when the
+// request body breaks a validation rule, and 400 when it cannot be decoded.
+func AbortWithBindError(ctx *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, dto.ValidationErrorResponse{
+		Error:  "validation failed",
+		Fields: TranslateValidationErrors(validationErrors),
+	})
+}
+
+// AbortWithFieldError answers 422 for a rule that can only be checked past the
+// binding layer, using the same payload shape as AbortWithBindError.
+func AbortWithFieldError(ctx *gin.Context, field, rule, message string) {
+	ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, dto.ValidationErrorResponse{
+		Error:  "validation failed",
+		Fields: []dto.FieldError{{Field: field, Rule: rule, Message: message}},
+	})
+}
+
+// ValidateStruct runs the binding rules of v outside of a request, e.g. for
+// rows of an uploaded file, and returns the failing fields.
+func ValidateStruct(v interface{}) []dto.FieldError {
+	err := binding.Validator.ValidateStruct(v)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []dto.FieldError{{Rule: "invalid", Message: err.Error()}}
+	}
+
+	return TranslateValidationErrors(validationErrors)
+}
+
+func TranslateValidationErrors(validationErrors validator.ValidationErrors) []dto.FieldError {
+	fields := make([]dto.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, dto.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: validationMessage(fe),
+		})
+	}
+
+	return fields
+}
+
+func validationMessage(fe validator.FieldError) string {
+	isString := fe.Kind() == reflect.String
+
+	switch fe.Tag() {
+	case "required", "required_if":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "required_without":
+		return fmt.Sprintf("%s is required when %s is not provided", fe.Field(), fe.Param())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", fe.Field())
+	case "min":
+		if isString {
+			return fmt.Sprintf("%s must be at least %s characters", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+	case "max":
+		if isString {
+			return fmt.Sprintf("%s must be at most %s characters", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", fe.Field(), fe.Param())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", fe.Field(), fe.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", fe.Field(), fe.Param())
+	case "gtefield":
+		return fmt.Sprintf("%s must not be less than %s", fe.Field(), fe.Param())
+	case "url":
+		return fmt.Sprintf("%s must be a valid URL", fe.Field())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", fe.Field(), fe.Param())
+	case "isbn_any":
+		return fmt.Sprintf("%s must be a valid ISBN-10 or ISBN-13", fe.Field())
+	default:
+		return fmt.Sprintf("%s is invalid", fe.Field())
+	}
+}
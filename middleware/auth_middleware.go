@@ -13,6 +13,7 @@ import (
 
 type AuthMiddleware interface {
 	RequireToken() gin.HandlerFunc
+	OptionalToken() gin.HandlerFunc
 }
 
 type authMiddleware struct{
@@ -60,6 +61,21 @@ func (am *authMiddleware) RequireToken() gin.HandlerFunc {
 	}
 }
 
+// OptionalToken lets requests without an Authorization header through as
+// guests, without a user_id. A token that is sent must still be valid.
+func (am *authMiddleware) OptionalToken() gin.HandlerFunc {
+	requireToken := am.RequireToken()
+
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			ctx.Next()
+			return
+		}
+
+		requireToken(ctx)
+	}
+}
+
 func NewAuthMiddleware(jwtService service.JwtService) AuthMiddleware {
 	return &authMiddleware{
 		jwtService: jwtService,
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+)
+
+// CurrencyMiddleware picks the currency prices are shown in from the
+// currency query parameter or the X-Currency header, in that order, and
+// stores what resolve makes of it under "currency". Without either the
+// resolver falls back to the base currency.
+func CurrencyMiddleware(resolve func(code string) (string, error)) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		code := ctx.Query("currency")
+		if code == "" {
+			code = ctx.GetHeader("X-Currency")
+		}
+
+		currency, err := resolve(strings.ToUpper(strings.TrimSpace(code)))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		ctx.Set("currency", currency)
+		ctx.Next()
+	}
+}
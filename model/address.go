@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// PostalAddress is where a parcel goes. It is embedded in saved addresses
+// and copied onto orders, so editing an address later does not change where
+// an order was shipped.
+type PostalAddress struct {
+	RecipientName string `json:"recipient_name" gorm:"size:100;not null;default:''"`
+	Phone         string `json:"phone" gorm:"size:30;not null;default:''"`
+	Line1         string `json:"line1" gorm:"size:255;not null;default:''"`
+	Line2         string `json:"line2" gorm:"size:255;not null;default:''"`
+	City          string `json:"city" gorm:"size:100;not null;default:''"`
+	Region        string `json:"region" gorm:"size:64;not null;default:''"`
+	PostalCode    string `json:"postal_code" gorm:"size:20;not null;default:''"`
+	Country       string `json:"country" gorm:"size:2;not null;default:''"`
+}
+
+// Address is an entry in a user's address book. At most one address per user
+// is the default, which checkout falls back to when the cart has none.
+type Address struct {
+	ID     int    `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	UserID int    `json:"user_id" gorm:"not null;index"`
+	Label  string `json:"label" gorm:"size:50"`
+	PostalAddress
+	IsDefault bool      `json:"is_default" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	AuthorRoleAuthor     = "author"
+	AuthorRoleEditor     = "editor"
+	AuthorRoleTranslator = "translator"
+)
+
+type Author struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	Name      string    `json:"name" gorm:"size:150;not null;uniqueIndex"`
+	Bio       string    `json:"bio"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BookAuthor is the book_authors join row. The same person can appear on a
+// book more than once with a different role, e.g. as editor and translator.
+type BookAuthor struct {
+	BookID   int    `json:"book_id" gorm:"primaryKey"`
+	AuthorID int    `json:"author_id" gorm:"primaryKey"`
+	Role     string `json:"role" gorm:"primaryKey;size:20;default:'author'"`
+	Position int    `json:"position"`
+	Author   Author `json:"author" gorm:"foreignKey:AuthorID"`
+}
@@ -5,12 +5,22 @@ import "time"
 type Book struct {
 	Id          int    		`json:"id" gorm:"autoIncrement:true;primaryKey"`
 	Title       string 		`json:"title" binding:"required"`
+	Slug        *string		`json:"slug" gorm:"size:255;uniqueIndex"`
+	Isbn13      *string		`json:"isbn13" gorm:"size:13;uniqueIndex"`
+	Isbn10      *string		`json:"isbn10" gorm:"size:10;index"`
 	Description string 		`json:"description" binding:"required"`
 	Author      string 		`json:"author" binding:"required"`
-	Price       int    		`json:"price" binding:"required"`
-	Rating      int			`json:"rating" binding:"required"`
+	Price       Money		`json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	Rating      float64		`json:"rating" gorm:"type:numeric(3,2);not null;default:0"`
+	RatingCount int			`json:"rating_count" gorm:"not null;default:0"`
+	CoverKey    *string		`json:"cover_key" gorm:"size:255"`
 	CategoryID  int			`json:"category_id" gorm:"not null"`
 	Category    Category	`json:"category" gorm:"foreignKey:CategoryID"`
+	Authors     []BookAuthor	`json:"authors" gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE"`
+	Variants    []BookVariant	`json:"variants" gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE"`
+	Reviews     []Review		`json:"-" gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE"`
+	PublisherID *int		`json:"publisher_id" gorm:"index"`
+	Publisher   *Publisher	`json:"publisher,omitempty" gorm:"foreignKey:PublisherID"`
 	CreatedAt   time.Time	`json:"created_at"`
 	UpdatedAt   time.Time	`json:"updated_at"`
 }
\ No newline at end of file
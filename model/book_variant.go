@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+const (
+	FormatHardcover = "hardcover"
+	FormatPaperback = "paperback"
+	FormatEbook     = "ebook"
+	FormatAudiobook = "audiobook"
+)
+
+// BookVariant is one sellable edition/format of a book (the work). Price and
+// stock live here rather than on the book so a hardcover and an ebook of the
+// same title can be priced and stocked independently. Price is in the store's
+// base currency; Prices holds fixed amounts for other currencies, which win
+// over converting the base price. Weight is the shipping weight in grams.
+type BookVariant struct {
+	ID        int            `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	BookID    int            `json:"book_id" gorm:"not null;index"`
+	Sku       string         `json:"sku" gorm:"size:64;not null;uniqueIndex"`
+	Format    string         `json:"format" gorm:"size:20;not null"`
+	Edition   string         `json:"edition" gorm:"size:100"`
+	Price     Money          `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	Prices    []VariantPrice `json:"prices" gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+	Stock     int            `json:"stock" gorm:"not null;default:0"`
+	PageCount int            `json:"page_count"`
+	Weight    int            `json:"weight" gorm:"not null;default:0"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// IsDigital reports whether the variant is delivered electronically and so
+// never runs out of stock.
+func (v BookVariant) IsDigital() bool {
+	return v.Format == FormatEbook || v.Format == FormatAudiobook
+}
+
+// VariantPrice is the fixed price of a variant in one currency.
+type VariantPrice struct {
+	VariantID int    `json:"variant_id" gorm:"primaryKey"`
+	Currency  string `json:"currency" gorm:"primaryKey;size:3"`
+	Amount    int64  `json:"amount" gorm:"not null"`
+}
@@ -1,14 +1,114 @@
 package model
 
+const (
+	DiscountSourcePromotion = "promotion"
+	DiscountSourceCoupon    = "coupon"
+)
+
+const (
+	ItemStatusPriceChanged      = "price_changed"
+	ItemStatusInsufficientStock = "insufficient_stock"
+	ItemStatusUnavailable       = "unavailable"
+)
+
+// Item is a line of the cart. Its snapshot of the variant is checked again
+// every time the cart is loaded. Status flags a line the customer should
+// look at: PreviousPrice is what it cost before its price changed, kept
+// until the customer reviews the cart, and AvailableQty how many copies are
+// left when there are fewer than Qty.
 type Item struct {
-	BookId int `json:"book_id"`
-	Price  int `json:"price"`
-	Qty	   int `json:"qty"`
+	VariantId     int    `json:"variant_id"`
+	BookId        int    `json:"book_id"`
+	Sku           string `json:"sku"`
+	Format        string `json:"format"`
+	Weight        int    `json:"weight"`
+	Price         Money  `json:"price"`
+	Qty           int    `json:"qty"`
+	Status        string `json:"status,omitempty"`
+	PreviousPrice *Money `json:"previous_price,omitempty"`
+	AvailableQty  *int   `json:"available_qty,omitempty"`
+}
+
+// NeedsShipping reports whether the item is a physical copy that has to be
+// shipped.
+func (i Item) NeedsShipping() bool {
+	return i.Format != FormatEbook && i.Format != FormatAudiobook
+}
+
+// CartOwner says whose cart it is: a signed in user, by UserId, or a guest,
+// by the opaque Token the guest was handed with their first cart request.
+type CartOwner struct {
+	UserId int
+	Token  string
+}
+
+// IsGuest reports whether the cart belongs to a visitor who is not signed in.
+func (o CartOwner) IsGuest() bool {
+	return o.UserId == 0
 }
 
+// Discount is one line taken off the cart total. Explanation says how the
+// amount was reached and VariantIds lists the items it was calculated from.
+type Discount struct {
+	Source      string `json:"source"`
+	PromotionId int    `json:"promotion_id,omitempty"`
+	Code        string `json:"code,omitempty"`
+	Label       string `json:"label"`
+	Explanation string `json:"explanation"`
+	Amount      Money  `json:"amount"`
+	VariantIds  []int  `json:"variant_ids,omitempty"`
+}
+
+// Cart is priced again on every change, in Currency. TotalPrice is the sum
+// of the items before discounts and GrandTotal is what checkout will charge,
+// including tax added on top by exclusive tax rules. Taxes break the tax
+// down per line for the Country and Region the cart is taxed in, which are
+// those of AddressId once the customer picks a shipping address.
+// ShippingQuotes list what each shipping method would charge and Shipping
+// is the charge of the chosen ShippingMethodId; like the coupon, a method
+// that stops being available stays chosen with ShippingError saying why.
+// When the applied coupon stops qualifying it stays on the cart with
+// CouponError explaining why it gives no discount. PromotionHints tell the
+// customer how close they are to a promotion that does not apply yet.
+// UnavailableItems are lines taken out of the cart because their book or
+// variant is no longer sold; they are shown until the customer reviews the
+// cart.
 type Cart struct {
 	UserId int `json:"user_id"`
+	Currency string `json:"currency"`
+	Country string `json:"country"`
+	Region string `json:"region,omitempty"`
+	AddressId int `json:"address_id,omitempty"`
 	Items []Item `json:"items"`
+	UnavailableItems []Item `json:"unavailable_items,omitempty"`
 	TotalQty int `json:"total_qty"`
-	TotalPrice int `json:"total_price"`
-}
\ No newline at end of file
+	TotalPrice Money `json:"total_price"`
+	CouponCode string `json:"coupon_code,omitempty"`
+	CouponError string `json:"coupon_error,omitempty"`
+	Discounts []Discount `json:"discounts"`
+	PromotionHints []string `json:"promotion_hints,omitempty"`
+	TotalDiscount Money `json:"total_discount"`
+	ShippingMethodId int `json:"shipping_method_id,omitempty"`
+	ShippingError string `json:"shipping_error,omitempty"`
+	ShippingQuotes []ShippingQuote `json:"shipping_quotes"`
+	Shipping Money `json:"shipping"`
+	Taxes []TaxLine `json:"taxes"`
+	TotalTax Money `json:"total_tax"`
+	GrandTotal Money `json:"grand_total"`
+}
+
+// NeedsReview reports whether lines changed price or were taken out of the
+// cart since the customer last reviewed it.
+func (c Cart) NeedsReview() bool {
+	if len(c.UnavailableItems) > 0 {
+		return true
+	}
+
+	for _, item := range c.Items {
+		if item.PreviousPrice != nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -5,6 +5,9 @@ import "time"
 type Category struct {
 	ID        int    `json:"id" gorm:"primaryKey;autoIncrement:true"`
 	Name      string `json:"name" gorm:"varchar(100)"`
+	Slug      *string `json:"slug" gorm:"size:255;uniqueIndex"`
+	ParentID  *int   `json:"parent_id" gorm:"index"`
+	Parent    *Category `json:"-" gorm:"foreignKey:ParentID"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Books     []Book `json:"books" gorm:"foreignKey:CategoryID"`
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+const (
+	CouponTypePercentage = "percentage"
+	CouponTypeFixed      = "fixed"
+)
+
+// Coupon is a discount code a customer applies to their cart. Value is a
+// percentage (1-100) for percentage coupons and an amount for fixed ones.
+// A coupon without categories or books applies to the whole cart; otherwise
+// only matching items count towards the minimum spend and the discount.
+//
+// Usage is counted from redemptions of orders that were not cancelled, so
+// cancelling an order gives the use back.
+type Coupon struct {
+	ID           int              `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	Code         string           `json:"code" gorm:"size:50;not null;uniqueIndex"`
+	Description  string           `json:"description" gorm:"size:255"`
+	Type         string           `json:"type" gorm:"size:20;not null"`
+	Value        int              `json:"value" gorm:"not null"`
+	MaxDiscount  *int             `json:"max_discount"`
+	MinSpend     int              `json:"min_spend" gorm:"not null;default:0"`
+	StartsAt     *time.Time       `json:"starts_at"`
+	EndsAt       *time.Time       `json:"ends_at"`
+	UsageLimit   *int             `json:"usage_limit"`
+	PerUserLimit *int             `json:"per_user_limit"`
+	IsActive     bool             `json:"is_active" gorm:"not null"`
+	Categories   []CouponCategory `json:"-" gorm:"foreignKey:CouponID;constraint:OnDelete:CASCADE"`
+	Books        []CouponBook     `json:"-" gorm:"foreignKey:CouponID;constraint:OnDelete:CASCADE"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// CouponCategory scopes a coupon to a category and everything nested below it.
+type CouponCategory struct {
+	CouponID   int `json:"coupon_id" gorm:"primaryKey"`
+	CategoryID int `json:"category_id" gorm:"primaryKey"`
+}
+
+// CouponBook scopes a coupon to every variant of a book.
+type CouponBook struct {
+	CouponID int `json:"coupon_id" gorm:"primaryKey"`
+	BookID   int `json:"book_id" gorm:"primaryKey"`
+}
+
+// CouponRedemption records a coupon used on an order and the discount it
+// gave at checkout.
+type CouponRedemption struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	CouponID  int       `json:"coupon_id" gorm:"not null;index"`
+	UserID    int       `json:"user_id" gorm:"not null;index"`
+	OrderID   int       `json:"order_id" gorm:"not null;uniqueIndex"`
+	Order     Order     `json:"-" gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	Discount  int64     `json:"discount" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+type CreateAddressRequest struct {
+	Label         string `json:"label" binding:"omitempty,max=50"`
+	RecipientName string `json:"recipient_name" binding:"required,max=100"`
+	Phone         string `json:"phone" binding:"required,max=30"`
+	Line1         string `json:"line1" binding:"required,max=255"`
+	Line2         string `json:"line2" binding:"omitempty,max=255"`
+	City          string `json:"city" binding:"required,max=100"`
+	Region        string `json:"region" binding:"omitempty,max=64"`
+	PostalCode    string `json:"postal_code" binding:"required,max=20"`
+	Country       string `json:"country" binding:"required,len=2,alpha"`
+	IsDefault     bool   `json:"is_default"`
+}
+
+type UpdateAddressRequest struct {
+	Label         *string `json:"label" binding:"omitempty,max=50"`
+	RecipientName *string `json:"recipient_name" binding:"omitempty,min=1,max=100"`
+	Phone         *string `json:"phone" binding:"omitempty,min=1,max=30"`
+	Line1         *string `json:"line1" binding:"omitempty,min=1,max=255"`
+	Line2         *string `json:"line2" binding:"omitempty,max=255"`
+	City          *string `json:"city" binding:"omitempty,min=1,max=100"`
+	Region        *string `json:"region" binding:"omitempty,max=64"`
+	PostalCode    *string `json:"postal_code" binding:"omitempty,min=1,max=20"`
+	Country       *string `json:"country" binding:"omitempty,len=2,alpha"`
+}
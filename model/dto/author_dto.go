@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+type CreateAuthorRequest struct {
+	Name string `json:"name" binding:"required,max=150"`
+	Bio  string `json:"bio"`
+}
+
+type UpdateAuthorRequest struct {
+	Name *string `json:"name" binding:"omitempty,min=1,max=150"`
+	Bio  *string `json:"bio"`
+}
+
+type AuthorResponse struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Bio       string    `json:"bio"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type BookAuthorRequest struct {
+	AuthorID int    `json:"author_id" binding:"required,gt=0"`
+	Role     string `json:"role" binding:"omitempty,oneof=author editor translator"`
+}
+
+type BookAuthorResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
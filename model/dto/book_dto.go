@@ -1,41 +1,147 @@
 package dto
 
-import "time"
+import (
+	"time"
 
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+)
+
+// CreateBookRequest accepts either a list of existing authors or, for older
+// clients, a free-text author string whose names are matched or created.
+// Without variants a single paperback priced at price is created. Prices are
+// in the minor unit of the store's base currency.
 type CreateBookRequest struct {
 	Title       string `json:"title" binding:"required"`
+	Isbn        string `json:"isbn" binding:"omitempty,isbn_any"`
 	Description string `json:"description" binding:"required"`
-	Author      string `json:"author" binding:"required"`
-	Price       int    `json:"price" binding:"required"`
-	Rating      int    `json:"rating" binding:"required"`
-	CategoryID  int	   `json:"category_id" binding:"required"`
+	Author      string `json:"author" binding:"required_without=Authors"`
+	Authors     []BookAuthorRequest `json:"authors" binding:"omitempty,dive"`
+	PublisherID *int   `json:"publisher_id" binding:"omitempty,gt=0"`
+	Price       int64  `json:"price" binding:"required,gt=0"`
+	CategoryID  int	   `json:"category_id" binding:"required,gt=0"`
+	Variants    []CreateVariantRequest `json:"variants" binding:"omitempty,dive"`
 }
 
+// UpdateBookRequest clears the ISBN when isbn is an empty string. Prices
+// are changed on the book's variants, which is what buyers pay.
 type UpdateBookRequest struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Author      *string `json:"author"`
-	Price       *int    `json:"price"`
-	Rating      *int    `json:"rating"`
+	Title       *string `json:"title" binding:"omitempty,min=1"`
+	Isbn        *string `json:"isbn" binding:"omitempty,isbn_any"`
+	Description *string `json:"description" binding:"omitempty,min=1"`
+	Author      *string `json:"author" binding:"omitempty,min=1"`
+	Authors     []BookAuthorRequest `json:"authors" binding:"omitempty,dive"`
+	PublisherID *int    `json:"publisher_id" binding:"omitempty,gte=0"`
+	CategoryID  *int    `json:"category_id" binding:"omitempty,gt=0"`
+}
+
+// BookFilterRequest prices books in Currency, which the controller fills in
+// from the caller's chosen currency. Unpaged lists every matching book, for
+// clients of GET /book from before it was paginated.
+type BookFilterRequest struct {
+	CategoryID         int    `form:"category_id" binding:"omitempty,gt=0"`
+	IncludeDescendants bool   `form:"include_descendants"`
+	AuthorID           int    `form:"author_id" binding:"omitempty,gt=0"`
+	PublisherID        int    `form:"publisher_id" binding:"omitempty,gt=0"`
+	Page               int    `form:"page" binding:"omitempty,gt=0"`
+	Limit              int    `form:"limit" binding:"omitempty,gt=0,max=100"`
+	Currency           string `form:"-"`
+	Unpaged            bool   `form:"-"`
+}
+
+type BookExportRequest struct {
+	BookFilterRequest
+	Format string `form:"format" binding:"required,oneof=csv ndjson onix"`
+}
+
+type BookExportRow struct {
+	Id          int       `json:"id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Isbn13      string    `json:"isbn13"`
+	Isbn10      string    `json:"isbn10"`
+	Description string    `json:"description"`
+	Author      string    `json:"author"`
+	Contributors []BookExportContributor `json:"contributors"`
+	Variants    []BookExportVariant `json:"variants"`
+	CategoryID  int       `json:"category_id"`
+	Category    string    `json:"category"`
+	Publisher   string    `json:"publisher"`
+	Price       int64     `json:"price"`
+	Currency    string    `json:"currency"`
+	Rating      float64   `json:"rating"`
+	RatingCount int       `json:"rating_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type BookExportContributor struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type BookExportVariant struct {
+	Sku      string `json:"sku"`
+	Format   string `json:"format"`
+	Price    int64  `json:"price"`
+	Currency string `json:"currency"`
 }
 
 type BookResponse struct {
 	Category    *CategoryResponse `json:"category"`
+	Breadcrumb  []CategoryBreadcrumb `json:"breadcrumb"`
 	Id          int    `json:"id"`
 	Title       string `json:"title"`
+	Slug        string `json:"slug"`
+	Isbn13      string `json:"isbn13"`
+	Isbn10      string `json:"isbn10"`
 	Description string `json:"description"`
 	Author      string `json:"author"`
-	Price       int    `json:"price"`
-	Rating      int    `json:"rating"`
+	Authors     []BookAuthorResponse `json:"authors"`
+	Publisher   *PublisherResponse `json:"publisher"`
+	Variants    []VariantResponse `json:"variants"`
+	Cover       *CoverResponse `json:"cover"`
+	Price       model.Money `json:"price"`
+	Rating      float64 `json:"rating"`
+	RatingCount int    `json:"rating_count"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+type CoverResponse struct {
+	Original string `json:"original"`
+	Small    string `json:"small"`
+	Medium   string `json:"medium"`
+}
+
 type GeneralResponse struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
 
+type PagedResponse struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+	Paging  Paging      `json:"paging"`
+}
+
+type Paging struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	TotalRows  int64 `json:"total_rows"`
+	TotalPages int   `json:"total_pages"`
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
 }
\ No newline at end of file
@@ -1,12 +1,25 @@
 package dto
 
 type RequestUpdateQtyFromItem struct {
-	BookId *int   `json:"book_id" binding:"omitempty"`
-	Qty  *int   `json:"qty" binding:"omitempty"`
+	VariantId *int `json:"variant_id" binding:"required,gt=0"`
+	Qty  *int   `json:"qty" binding:"required,gt=0,max=99"`
 }
 
 type RequestUpdateItemFromCart struct {
-	BookId *int   `json:"book_id" binding:"omitempty"`
-	Qty *int   `json:"qty" binding:"omitempty"`
-	Price *int `json:"price" binding:"omitempty"`
+	VariantId *int `json:"variant_id" binding:"required,gt=0"`
+	Qty *int   `json:"qty" binding:"required,gt=0,max=99"`
+	Price *int `json:"price" binding:"omitempty,gt=0"`
+}
+
+type RequestAddToCart struct {
+	VariantId int `json:"variant_id" binding:"required,gt=0"`
+	Qty       int `json:"qty" binding:"required,gt=0,max=99"`
+}
+
+type SetCartAddressRequest struct {
+	AddressID int `json:"address_id" binding:"required,gt=0"`
+}
+
+type SetShippingMethodRequest struct {
+	ShippingMethodID int `json:"shipping_method_id" binding:"required,gt=0"`
 }
@@ -3,16 +3,55 @@ package dto
 import "time"
 
 type CreateCategoryRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name     string `json:"name" binding:"required,max=100"`
+	ParentID *int   `json:"parent_id" binding:"omitempty,gt=0"`
 }
 
+// UpdateCategoryRequest moves the category to the root when parent_id is 0.
 type UpdateCategoryRequest struct {
-	Name *string `json:"name" binding:"omitempty"`
+	Name     *string `json:"name" binding:"omitempty,min=1,max=100"`
+	ParentID *int    `json:"parent_id" binding:"omitempty,gte=0"`
 }
 
 type CategoryResponse struct {
 	ID        int       `json:"id"`
 	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	ParentID  *int      `json:"parent_id"`
+	BookCount *int64    `json:"book_count,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+type CategoryTreeResponse struct {
+	ID        int                    `json:"id"`
+	Name      string                 `json:"name"`
+	Slug      string                 `json:"slug"`
+	ParentID  *int                   `json:"parent_id"`
+	BookCount int64                  `json:"book_count"`
+	Children  []CategoryTreeResponse `json:"children"`
+}
+
+type CategoryBreadcrumb struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+const (
+	DeleteStrategyRestrict = "restrict"
+	DeleteStrategyReassign = "reassign"
+	DeleteStrategyCascade  = "cascade"
+)
+
+type DeleteCategoryRequest struct {
+	Strategy string `form:"strategy" binding:"omitempty,oneof=restrict reassign cascade"`
+	TargetID int    `form:"target_id" binding:"required_if=Strategy reassign,omitempty,gt=0"`
+}
+
+type DeleteCategoryResponse struct {
+	CategoryID    int    `json:"category_id"`
+	Strategy      string `json:"strategy"`
+	TargetID      int    `json:"target_id,omitempty"`
+	AffectedBooks int64  `json:"affected_books"`
+}
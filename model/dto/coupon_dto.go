@@ -0,0 +1,66 @@
+package dto
+
+import "time"
+
+type CreateCouponRequest struct {
+	Code         string     `json:"code" binding:"required,max=50"`
+	Description  string     `json:"description" binding:"omitempty,max=255"`
+	Type         string     `json:"type" binding:"required,oneof=percentage fixed"`
+	Value        int        `json:"value" binding:"required,gt=0"`
+	MaxDiscount  *int       `json:"max_discount" binding:"omitempty,gt=0"`
+	MinSpend     int        `json:"min_spend" binding:"omitempty,gte=0"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	UsageLimit   *int       `json:"usage_limit" binding:"omitempty,gt=0"`
+	PerUserLimit *int       `json:"per_user_limit" binding:"omitempty,gt=0"`
+	IsActive     *bool      `json:"is_active"`
+	CategoryIds  []int      `json:"category_ids" binding:"omitempty,dive,gt=0"`
+	BookIds      []int      `json:"book_ids" binding:"omitempty,dive,gt=0"`
+}
+
+// UpdateCouponRequest replaces the scope when CategoryIds or BookIds is sent;
+// an empty list removes that part of the scope.
+type UpdateCouponRequest struct {
+	Code         *string    `json:"code" binding:"omitempty,min=1,max=50"`
+	Description  *string    `json:"description" binding:"omitempty,max=255"`
+	Type         *string    `json:"type" binding:"omitempty,oneof=percentage fixed"`
+	Value        *int       `json:"value" binding:"omitempty,gt=0"`
+	MaxDiscount  *int       `json:"max_discount" binding:"omitempty,gt=0"`
+	MinSpend     *int       `json:"min_spend" binding:"omitempty,gte=0"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	UsageLimit   *int       `json:"usage_limit" binding:"omitempty,gt=0"`
+	PerUserLimit *int       `json:"per_user_limit" binding:"omitempty,gt=0"`
+	IsActive     *bool      `json:"is_active"`
+	CategoryIds  *[]int     `json:"category_ids" binding:"omitempty,dive,gt=0"`
+	BookIds      *[]int     `json:"book_ids" binding:"omitempty,dive,gt=0"`
+}
+
+type CouponFilterRequest struct {
+	Page  int `form:"page" binding:"omitempty,gt=0"`
+	Limit int `form:"limit" binding:"omitempty,gt=0,max=100"`
+}
+
+type CouponResponse struct {
+	ID           int        `json:"id"`
+	Code         string     `json:"code"`
+	Description  string     `json:"description"`
+	Type         string     `json:"type"`
+	Value        int        `json:"value"`
+	MaxDiscount  *int       `json:"max_discount"`
+	MinSpend     int        `json:"min_spend"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	UsageLimit   *int       `json:"usage_limit"`
+	PerUserLimit *int       `json:"per_user_limit"`
+	TimesUsed    int64      `json:"times_used"`
+	IsActive     bool       `json:"is_active"`
+	CategoryIds  []int      `json:"category_ids"`
+	BookIds      []int      `json:"book_ids"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type ApplyCouponRequest struct {
+	Code string `json:"code" binding:"required,max=50"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type SetExchangeRateRequest struct {
+	Rate float64 `json:"rate" binding:"required,gt=0"`
+}
+
+type ExchangeRateResponse struct {
+	Currency  string     `json:"currency"`
+	Rate      float64    `json:"rate"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// CurrencyResponse lists the currencies prices can be shown in: the base
+// currency, at a rate of 1, followed by every currency with a rate.
+type CurrencyResponse struct {
+	Base       string                 `json:"base"`
+	Currencies []ExchangeRateResponse `json:"currencies"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+type ImportBookRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv ndjson"`
+	DryRun bool   `form:"dry_run"`
+}
+
+type ImportJobResponse struct {
+	ID             int        `json:"id"`
+	Status         string     `json:"status"`
+	Format         string     `json:"format"`
+	DryRun         bool       `json:"dry_run"`
+	TotalRows      int        `json:"total_rows"`
+	ProcessedRows  int        `json:"processed_rows"`
+	SucceededRows  int        `json:"succeeded_rows"`
+	FailedRows     int        `json:"failed_rows"`
+	Progress       float64    `json:"progress"`
+	Error          string     `json:"error,omitempty"`
+	ErrorReportURL string     `json:"error_report_url,omitempty"`
+	StartedAt      *time.Time `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type OrderFilterRequest struct {
+	Page  int `form:"page" binding:"omitempty,gt=0"`
+	Limit int `form:"limit" binding:"omitempty,gt=0,max=100"`
+}
@@ -0,0 +1,61 @@
+package dto
+
+import "time"
+
+type CreatePromotionRequest struct {
+	Name        string     `json:"name" binding:"required,max=150"`
+	Description string     `json:"description" binding:"omitempty,max=255"`
+	Type        string     `json:"type" binding:"required,oneof=percentage fixed buy_x_get_y bundle"`
+	Value       int        `json:"value" binding:"omitempty,gt=0"`
+	BuyQty      int        `json:"buy_qty" binding:"omitempty,gt=0"`
+	GetQty      int        `json:"get_qty" binding:"omitempty,gt=0"`
+	Priority    int        `json:"priority"`
+	Stackable   bool       `json:"stackable"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	IsActive    *bool      `json:"is_active"`
+	CategoryIds []int      `json:"category_ids" binding:"omitempty,dive,gt=0"`
+	BookIds     []int      `json:"book_ids" binding:"omitempty,dive,gt=0"`
+}
+
+// UpdatePromotionRequest replaces the scope when CategoryIds or BookIds is
+// sent; an empty list removes that part of the scope.
+type UpdatePromotionRequest struct {
+	Name        *string    `json:"name" binding:"omitempty,min=1,max=150"`
+	Description *string    `json:"description" binding:"omitempty,max=255"`
+	Type        *string    `json:"type" binding:"omitempty,oneof=percentage fixed buy_x_get_y bundle"`
+	Value       *int       `json:"value" binding:"omitempty,gt=0"`
+	BuyQty      *int       `json:"buy_qty" binding:"omitempty,gt=0"`
+	GetQty      *int       `json:"get_qty" binding:"omitempty,gt=0"`
+	Priority    *int       `json:"priority"`
+	Stackable   *bool      `json:"stackable"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	IsActive    *bool      `json:"is_active"`
+	CategoryIds *[]int     `json:"category_ids" binding:"omitempty,dive,gt=0"`
+	BookIds     *[]int     `json:"book_ids" binding:"omitempty,dive,gt=0"`
+}
+
+type PromotionFilterRequest struct {
+	Page  int `form:"page" binding:"omitempty,gt=0"`
+	Limit int `form:"limit" binding:"omitempty,gt=0,max=100"`
+}
+
+type PromotionResponse struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Type        string     `json:"type"`
+	Value       int        `json:"value"`
+	BuyQty      int        `json:"buy_qty"`
+	GetQty      int        `json:"get_qty"`
+	Priority    int        `json:"priority"`
+	Stackable   bool       `json:"stackable"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	IsActive    bool       `json:"is_active"`
+	CategoryIds []int      `json:"category_ids"`
+	BookIds     []int      `json:"book_ids"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
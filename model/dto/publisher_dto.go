@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+type CreatePublisherRequest struct {
+	Name    string `json:"name" binding:"required,max=150"`
+	Website string `json:"website" binding:"omitempty,url,max=255"`
+}
+
+type UpdatePublisherRequest struct {
+	Name    *string `json:"name" binding:"omitempty,min=1,max=150"`
+	Website *string `json:"website" binding:"omitempty,url,max=255"`
+}
+
+type PublisherResponse struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Website   string    `json:"website"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+type CreateReadingListRequest struct {
+	Name        string `json:"name" binding:"required,max=150"`
+	Description string `json:"description" binding:"omitempty,max=2000"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+type UpdateReadingListRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=150"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	IsPublic    *bool   `json:"is_public"`
+}
+
+type AddReadingListBookRequest struct {
+	BookID int    `json:"book_id" binding:"required,gt=0"`
+	Note   string `json:"note" binding:"omitempty,max=500"`
+}
+
+type ReadingListResponse struct {
+	ID          int                       `json:"id"`
+	UserID      int                       `json:"user_id"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	IsPublic    bool                      `json:"is_public"`
+	ShareToken  string                    `json:"share_token,omitempty"`
+	ShareURL    string                    `json:"share_url,omitempty"`
+	Books       []ReadingListBookResponse `json:"books"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+type ReadingListBookResponse struct {
+	Book    BookResponse `json:"book"`
+	Note    string       `json:"note"`
+	AddedAt time.Time    `json:"added_at"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+type CreateReturnRequest struct {
+	OrderItemID int    `json:"order_item_id" binding:"required,gt=0"`
+	Qty         int    `json:"qty" binding:"required,gt=0"`
+	Reason      string `json:"reason" binding:"required,oneof=damaged defective wrong_item not_as_described changed_mind other"`
+	Comment     string `json:"comment" binding:"omitempty,max=1000"`
+}
+
+type ReviewReturnRequest struct {
+	Note string `json:"note" binding:"omitempty,max=500"`
+}
+
+// ReturnFilterRequest lists the caller's own returns; admins see every
+// user's.
+type ReturnFilterRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=requested approved rejected received refunded"`
+	Page   int    `form:"page" binding:"omitempty,gt=0"`
+	Limit  int    `form:"limit" binding:"omitempty,gt=0,max=100"`
+}
+
+// RefundReturnRequest refunds a received return. Without an amount the
+// full value paid for the returned copies is refunded.
+type RefundReturnRequest struct {
+	Amount *int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// RefundOrderRequest refunds part of an order outside of a return, such as
+// shipping or a goodwill gesture. Without an amount whatever is left to
+// refund on the order is refunded.
+type RefundOrderRequest struct {
+	Amount *int64 `json:"amount" binding:"omitempty,gt=0"`
+	Reason string `json:"reason" binding:"required,max=255"`
+}
@@ -0,0 +1,78 @@
+package dto
+
+import "time"
+
+type CreateReviewRequest struct {
+	Stars int    `json:"stars" binding:"required,min=1,max=5"`
+	Title string `json:"title" binding:"omitempty,max=150"`
+	Body  string `json:"body" binding:"omitempty,max=5000"`
+}
+
+type UpdateReviewRequest struct {
+	Stars *int    `json:"stars" binding:"omitempty,min=1,max=5"`
+	Title *string `json:"title" binding:"omitempty,max=150"`
+	Body  *string `json:"body" binding:"omitempty,max=5000"`
+}
+
+type ReviewFilterRequest struct {
+	Sort  string `form:"sort" binding:"omitempty,oneof=helpful newest highest lowest"`
+	Page  int    `form:"page" binding:"omitempty,gt=0"`
+	Limit int    `form:"limit" binding:"omitempty,gt=0,max=100"`
+}
+
+const (
+	ModerationQueuePending  = "pending"
+	ModerationQueueReported = "reported"
+	ModerationQueueRejected = "rejected"
+)
+
+type ModerationFilterRequest struct {
+	Queue string `form:"queue" binding:"omitempty,oneof=pending reported rejected"`
+	Page  int    `form:"page" binding:"omitempty,gt=0"`
+	Limit int    `form:"limit" binding:"omitempty,gt=0,max=100"`
+}
+
+type ModerateReviewRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+	Reason string `json:"reason" binding:"required_if=Status rejected,max=500"`
+}
+
+type ReviewVoteRequest struct {
+	Helpful *bool `json:"helpful" binding:"required"`
+}
+
+type ReportReviewRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type ReviewResponse struct {
+	ID               int       `json:"id"`
+	BookID           int       `json:"book_id"`
+	UserID           int       `json:"user_id"`
+	UserName         string    `json:"user_name"`
+	Stars            int       `json:"stars"`
+	Title            string    `json:"title"`
+	Body             string    `json:"body"`
+	VerifiedPurchase bool      `json:"verified_purchase"`
+	Status           string    `json:"status"`
+	RejectReason     string    `json:"reject_reason,omitempty"`
+	HelpfulCount     int       `json:"helpful_count"`
+	UnhelpfulCount   int       `json:"unhelpful_count"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type ReviewReportResponse struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Reason     string     `json:"reason"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ReviewModerationResponse struct {
+	ReviewResponse
+	ModeratedBy *int                   `json:"moderated_by"`
+	ModeratedAt *time.Time             `json:"moderated_at"`
+	Reports     []ReviewReportResponse `json:"reports"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+)
+
+type ShipmentItemRequest struct {
+	OrderItemID int `json:"order_item_id" binding:"required,gt=0"`
+	Qty         int `json:"qty" binding:"required,gt=0"`
+}
+
+type CreateShipmentRequest struct {
+	Carrier        string                `json:"carrier" binding:"required,max=50"`
+	TrackingNumber string                `json:"tracking_number" binding:"omitempty,max=100"`
+	Items          []ShipmentItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type UpdateShipmentRequest struct {
+	Carrier        *string `json:"carrier" binding:"omitempty,min=1,max=50"`
+	TrackingNumber *string `json:"tracking_number" binding:"omitempty,max=100"`
+}
+
+// CreateShipmentEventRequest records a carrier status. OccurredAt defaults
+// to now.
+type CreateShipmentEventRequest struct {
+	Status      string     `json:"status" binding:"required,oneof=shipped in_transit out_for_delivery delivered failed"`
+	Location    string     `json:"location" binding:"omitempty,max=100"`
+	Description string     `json:"description" binding:"omitempty,max=255"`
+	OccurredAt  *time.Time `json:"occurred_at"`
+}
+
+// FulfillmentQueueFilterRequest narrows the queue to orders paid at least
+// MinAgeHours and at most MaxAgeHours ago.
+type FulfillmentQueueFilterRequest struct {
+	MinAgeHours int `form:"min_age_hours" binding:"omitempty,gte=0"`
+	MaxAgeHours int `form:"max_age_hours" binding:"omitempty,gt=0,gtefield=MinAgeHours"`
+	Page        int `form:"page" binding:"omitempty,gt=0"`
+	Limit       int `form:"limit" binding:"omitempty,gt=0,max=100"`
+}
+
+type UnfulfilledItem struct {
+	OrderItemID int    `json:"order_item_id"`
+	VariantID   int    `json:"variant_id"`
+	Sku         string `json:"sku"`
+	Qty         int    `json:"qty"`
+}
+
+// FulfillmentQueueEntry is a paid order still waiting for some of its
+// physical items to be shipped. AgeHours counts from payment.
+type FulfillmentQueueEntry struct {
+	Order       model.Order       `json:"order"`
+	AgeHours    int               `json:"age_hours"`
+	Unfulfilled []UnfulfilledItem `json:"unfulfilled"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+type CreateShippingZoneRequest struct {
+	Name      string   `json:"name" binding:"required,max=100"`
+	Countries []string `json:"countries" binding:"required,min=1,dive,len=2,alpha"`
+}
+
+type UpdateShippingZoneRequest struct {
+	Name      *string   `json:"name" binding:"omitempty,min=1,max=100"`
+	Countries *[]string `json:"countries" binding:"omitempty,min=1,dive,len=2,alpha"`
+}
+
+type ShippingRateRequest struct {
+	MaxWeight int   `json:"max_weight" binding:"required,gt=0"`
+	Price     int64 `json:"price" binding:"gte=0"`
+}
+
+// CreateShippingMethodRequest amounts are in the minor unit of the store's
+// base currency and weights in grams. Weight methods need at least one rate.
+type CreateShippingMethodRequest struct {
+	Name          string                `json:"name" binding:"required,max=100"`
+	ZoneID        *int                  `json:"zone_id" binding:"omitempty,gt=0"`
+	Type          string                `json:"type" binding:"required,oneof=flat weight"`
+	FlatRate      int64                 `json:"flat_rate" binding:"gte=0"`
+	Rates         []ShippingRateRequest `json:"rates" binding:"required_if=Type weight,omitempty,dive"`
+	FreeOver      *int64                `json:"free_over" binding:"omitempty,gt=0"`
+	EstimatedDays string                `json:"estimated_days" binding:"omitempty,max=50"`
+	IsActive      *bool                 `json:"is_active"`
+}
+
+// UpdateShippingMethodRequest clears the zone with a zone_id of 0 and the
+// free shipping threshold with a free_over of 0.
+type UpdateShippingMethodRequest struct {
+	Name          *string                `json:"name" binding:"omitempty,min=1,max=100"`
+	ZoneID        *int                   `json:"zone_id" binding:"omitempty,gte=0"`
+	Type          *string                `json:"type" binding:"omitempty,oneof=flat weight"`
+	FlatRate      *int64                 `json:"flat_rate" binding:"omitempty,gte=0"`
+	Rates         *[]ShippingRateRequest `json:"rates" binding:"omitempty,dive"`
+	FreeOver      *int64                 `json:"free_over" binding:"omitempty,gte=0"`
+	EstimatedDays *string                `json:"estimated_days" binding:"omitempty,max=50"`
+	IsActive      *bool                  `json:"is_active"`
+}
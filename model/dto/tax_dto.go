@@ -0,0 +1,30 @@
+package dto
+
+type CreateTaxRuleRequest struct {
+	Country      string  `json:"country" binding:"required,len=2,alpha"`
+	Region       string  `json:"region" binding:"omitempty,max=64"`
+	ProductClass string  `json:"product_class" binding:"omitempty,oneof=printed_book ebook audiobook"`
+	Name         string  `json:"name" binding:"required,max=50"`
+	Rate         float64 `json:"rate" binding:"gte=0,lte=100"`
+	Inclusive    bool    `json:"inclusive"`
+}
+
+type UpdateTaxRuleRequest struct {
+	Country      *string  `json:"country" binding:"omitempty,len=2,alpha"`
+	Region       *string  `json:"region" binding:"omitempty,max=64"`
+	ProductClass *string  `json:"product_class" binding:"omitempty,oneof=printed_book ebook audiobook"`
+	Name         *string  `json:"name" binding:"omitempty,min=1,max=50"`
+	Rate         *float64 `json:"rate" binding:"omitempty,gte=0,lte=100"`
+	Inclusive    *bool    `json:"inclusive"`
+}
+
+type TaxRuleFilterRequest struct {
+	Country string `form:"country" binding:"omitempty,len=2,alpha"`
+}
+
+// SetTaxLocationRequest sets where the cart is taxed until checkout knows
+// the shipping address.
+type SetTaxLocationRequest struct {
+	Country string `json:"country" binding:"required,len=2,alpha"`
+	Region  string `json:"region" binding:"omitempty,max=64"`
+}
@@ -6,7 +6,7 @@ type CreateUserRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role"`
+	Role     string `json:"role" binding:"omitempty,oneof=user admin"`
 }
 
 type UserResponse struct {
@@ -20,8 +20,8 @@ type UserResponse struct {
 }
 
 type UpdateUserRequest struct {
-	Name     *string `json:"name" validate:"omitempty"`
-	Email    *string `json:"email" validate:"omitempty,email"`
-	Password *string `json:"password" validate:"omitempty,min=6"`
-	Role     *string `json:"role" validate:"omitempty"`
+	Name     *string `json:"name" binding:"omitempty,min=1"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+	Password *string `json:"password" binding:"omitempty,min=6"`
+	Role     *string `json:"role" binding:"omitempty,oneof=user admin"`
 }
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+)
+
+// PriceRequest fixes the price of a variant in a currency other than the
+// store's base currency.
+type PriceRequest struct {
+	Currency string `json:"currency" binding:"required,len=3,uppercase"`
+	Amount   int64  `json:"amount" binding:"required,gt=0"`
+}
+
+type CreateVariantRequest struct {
+	Sku       string `json:"sku" binding:"omitempty,max=64"`
+	Format    string `json:"format" binding:"required,oneof=hardcover paperback ebook audiobook"`
+	Edition   string `json:"edition" binding:"omitempty,max=100"`
+	Price     int64  `json:"price" binding:"required,gt=0"`
+	Prices    []PriceRequest `json:"prices" binding:"omitempty,dive"`
+	Stock     int    `json:"stock" binding:"omitempty,gte=0"`
+	PageCount int    `json:"page_count" binding:"omitempty,gte=0"`
+	Weight    int    `json:"weight" binding:"omitempty,gte=0"`
+}
+
+type UpdateVariantRequest struct {
+	Sku       *string `json:"sku" binding:"omitempty,min=1,max=64"`
+	Format    *string `json:"format" binding:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	Edition   *string `json:"edition" binding:"omitempty,max=100"`
+	Price     *int64  `json:"price" binding:"omitempty,gt=0"`
+	Prices    *[]PriceRequest `json:"prices" binding:"omitempty,dive"`
+	Stock     *int    `json:"stock" binding:"omitempty,gte=0"`
+	PageCount *int    `json:"page_count" binding:"omitempty,gte=0"`
+	Weight    *int    `json:"weight" binding:"omitempty,gte=0"`
+}
+
+type VariantResponse struct {
+	ID        int       `json:"id"`
+	BookID    int       `json:"book_id"`
+	Sku       string    `json:"sku"`
+	Format    string    `json:"format"`
+	Edition   string    `json:"edition"`
+	Price     model.Money `json:"price"`
+	Prices    []model.Money `json:"prices"`
+	Stock     int       `json:"stock"`
+	InStock   bool      `json:"in_stock"`
+	PageCount int       `json:"page_count"`
+	Weight    int       `json:"weight"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type AddWishlistRequest struct {
+	BookID    int  `json:"book_id" binding:"required,gt=0"`
+	VariantID *int `json:"variant_id" binding:"omitempty,gt=0"`
+}
+
+type MoveToCartRequest struct {
+	VariantID *int `json:"variant_id" binding:"omitempty,gt=0"`
+	Qty       int  `json:"qty" binding:"omitempty,gt=0,max=99"`
+}
+
+type WishlistItemResponse struct {
+	Book      BookResponse `json:"book"`
+	VariantID *int         `json:"variant_id"`
+	AddedAt   time.Time    `json:"added_at"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ExchangeRate is how many units of Currency one unit of the store's base
+// currency buys. Prices without an explicit amount in a currency are
+// converted with it.
+type ExchangeRate struct {
+	Currency  string    `json:"currency" gorm:"primaryKey;size:3"`
+	Rate      float64   `json:"rate" gorm:"type:numeric(20,10);not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+const (
+	ImportStatusQueued    = "queued"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// ImportJob tracks a bulk book import that runs in the background. In a dry
+// run every row is validated but nothing is written, so SucceededRows counts
+// the rows that would have been created.
+type ImportJob struct {
+	ID            int        `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	UserID        int        `json:"user_id" gorm:"not null;index"`
+	Format        string     `json:"format" gorm:"size:10;not null"`
+	DryRun        bool       `json:"dry_run" gorm:"not null;default:false"`
+	Status        string     `json:"status" gorm:"size:20;not null;index"`
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"`
+	SucceededRows int        `json:"succeeded_rows"`
+	FailedRows    int        `json:"failed_rows"`
+	Error         string     `json:"error" gorm:"type:text"`
+	StartedAt     *time.Time `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ImportRowError is one problem found in one row of an import file. Row is
+// the line number in the uploaded file.
+type ImportRowError struct {
+	ID      int    `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	JobID   int    `json:"job_id" gorm:"not null;index"`
+	Row     int    `json:"row" gorm:"column:row_number;not null"`
+	Field   string `json:"field" gorm:"size:100"`
+	Rule    string `json:"rule" gorm:"size:50"`
+	Message string `json:"message" gorm:"type:text"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+const (
+	InvoiceKindInvoice    = "invoice"
+	InvoiceKindCreditNote = "credit_note"
+
+	InvoiceLineItem = "item"
+	InvoiceLineTax  = "tax"
+)
+
+// Invoice is the accounting document issued for a paid order, or a credit
+// note issued for a refund on it. Numbers run per kind and calendar year
+// without gaps, e.g. INV-2026-000042 and CN-2026-000007. A credit note
+// points at the refund it documents and the invoice it corrects. Amounts
+// are copied from the order or refund when the document is issued and are
+// in the minor unit of Currency. The buyer and the lines are copied too, so
+// renaming a book or editing the account later does not change a document
+// already issued. The PDF is kept on disk and rendered again from this row
+// should the file go missing.
+type Invoice struct {
+	ID            int           `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	Kind          string        `json:"kind" gorm:"size:20;not null;uniqueIndex:idx_invoice_number_sequence,priority:1"`
+	Number        string        `json:"number" gorm:"size:30;not null;uniqueIndex"`
+	Year          int           `json:"year" gorm:"not null;uniqueIndex:idx_invoice_number_sequence,priority:2"`
+	Sequence      int           `json:"sequence" gorm:"not null;uniqueIndex:idx_invoice_number_sequence,priority:3"`
+	OrderID       int           `json:"order_id" gorm:"not null;index"`
+	RefundID      *int          `json:"refund_id,omitempty" gorm:"uniqueIndex"`
+	InvoiceID     *int          `json:"invoice_id,omitempty"`
+	Currency      string        `json:"currency" gorm:"size:3;not null"`
+	Subtotal      int64         `json:"subtotal" gorm:"not null;default:0"`
+	TotalDiscount int64         `json:"total_discount" gorm:"not null;default:0"`
+	ShippingTotal int64         `json:"shipping_total" gorm:"not null;default:0"`
+	TotalTax      int64         `json:"total_tax" gorm:"not null;default:0"`
+	Total         int64         `json:"total" gorm:"not null"`
+	BuyerName     string        `json:"buyer_name" gorm:"size:100;not null;default:''"`
+	BuyerEmail    string        `json:"buyer_email" gorm:"size:100;not null;default:''"`
+	BuyerAddress  PostalAddress `json:"buyer_address" gorm:"embedded;embeddedPrefix:buyer_"`
+	Lines         []InvoiceLine `json:"lines" gorm:"foreignKey:InvoiceID;constraint:OnDelete:CASCADE"`
+	IssuedAt      time.Time     `json:"issued_at" gorm:"not null"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// InvoiceLine is a row printed on an invoice or credit note, either an item
+// or a tax. Qty and UnitPrice are zero on tax lines.
+type InvoiceLine struct {
+	ID          int    `json:"-" gorm:"primaryKey;autoIncrement:true"`
+	InvoiceID   int    `json:"-" gorm:"not null;index"`
+	Kind        string `json:"kind" gorm:"size:10;not null"`
+	Description string `json:"description" gorm:"not null"`
+	Qty         int    `json:"qty,omitempty" gorm:"not null;default:0"`
+	UnitPrice   int64  `json:"unit_price,omitempty" gorm:"not null;default:0"`
+	Amount      int64  `json:"amount" gorm:"not null"`
+}
+
+// InvoiceSequence holds the last number handed out for a kind of invoice in
+// a year. It is only ever advanced in the transaction that saves the
+// invoice, so a number is used exactly when the invoice exists.
+type InvoiceSequence struct {
+	Kind string `gorm:"primaryKey;size:20"`
+	Year int    `gorm:"primaryKey;autoIncrement:false"`
+	Last int    `gorm:"not null;default:0"`
+}
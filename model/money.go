@@ -0,0 +1,47 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Money is an amount in the minor unit of its currency, e.g. cents for USD,
+// together with the ISO 4217 code of that currency. Embedded in a model it
+// is stored as <prefix>amount and <prefix>currency columns.
+type Money struct {
+	Amount   int64  `json:"amount" gorm:"not null;default:0"`
+	Currency string `json:"currency" gorm:"size:3;not null;default:''"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Add returns the sum of two amounts, keeping the currency of m.
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}
+}
+
+// Sub returns m minus other, keeping the currency of m.
+func (m Money) Sub(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}
+}
+
+// Times returns the amount multiplied by a quantity.
+func (m Money) Times(qty int) Money {
+	return Money{Amount: m.Amount * int64(qty), Currency: m.Currency}
+}
+
+// UnmarshalJSON also accepts a bare number, which is how amounts were stored
+// in carts before they carried a currency. Such amounts come back without a
+// currency and are priced again.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '{' && !bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return json.Unmarshal(data, &m.Amount)
+	}
+
+	type plain Money
+	return json.Unmarshal(data, (*plain)(m))
+}
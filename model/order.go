@@ -0,0 +1,89 @@
+package model
+
+import "time"
+
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRefunded  = "refunded"
+)
+
+// Order is a purchase placed by a user. Items keep a snapshot of the variant
+// as it was sold so later price or SKU changes do not rewrite history, and
+// Discounts keep the promotion lines that were locked in at checkout.
+// TotalPrice is the amount charged after discounts, with exclusive tax and
+// shipping added; TotalTax is all tax in it, included or added. Taxes keep
+// the tax lines for the Country and Region the order was taxed in. Orders
+// with physical items keep the shipping method and a copy of the address
+// they ship to. PaidAt is when payment was confirmed, from which the
+// fulfillment queue measures how long an order has waited. TotalRefunded is
+// what Refunds have paid back, or are paying back, and an order refunded in
+// full becomes refunded. Every amount on the order and its lines is in the
+// minor unit of Currency.
+type Order struct {
+	ID                 int             `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	UserID             int             `json:"user_id" gorm:"not null;index"`
+	Status             string          `json:"status" gorm:"size:20;not null;index"`
+	Currency           string          `json:"currency" gorm:"size:3;not null;default:''"`
+	Subtotal           int64           `json:"subtotal" gorm:"not null;default:0"`
+	TotalDiscount      int64           `json:"total_discount" gorm:"not null;default:0"`
+	TotalTax           int64           `json:"total_tax" gorm:"not null;default:0"`
+	Country            string          `json:"country" gorm:"size:2;not null;default:''"`
+	Region             string          `json:"region,omitempty" gorm:"size:64;not null;default:''"`
+	ShippingTotal      int64           `json:"shipping_total" gorm:"not null;default:0"`
+	ShippingMethodID   *int            `json:"shipping_method_id,omitempty"`
+	ShippingMethodName string          `json:"shipping_method_name,omitempty" gorm:"size:100"`
+	ShippingAddress    PostalAddress   `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"`
+	TotalPrice         int64           `json:"total_price" gorm:"not null"`
+	CouponCode         string          `json:"coupon_code,omitempty" gorm:"size:50"`
+	PaidAt             *time.Time      `json:"paid_at,omitempty" gorm:"index"`
+	TotalRefunded      int64           `json:"total_refunded" gorm:"not null;default:0"`
+	Items              []OrderItem     `json:"items" gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	Discounts          []OrderDiscount `json:"discounts" gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	Taxes              []OrderTax      `json:"taxes" gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	Refunds            []Refund        `json:"refunds" gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+type OrderItem struct {
+	ID        int    `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	OrderID   int    `json:"order_id" gorm:"not null;index"`
+	VariantID int    `json:"variant_id" gorm:"not null;index"`
+	BookID    int    `json:"book_id" gorm:"not null;index"`
+	Sku       string `json:"sku" gorm:"size:64;not null"`
+	Format    string `json:"format" gorm:"size:20;not null"`
+	Price     int64  `json:"price" gorm:"not null"`
+	Qty       int    `json:"qty" gorm:"not null"`
+}
+
+// NeedsShipping reports whether the item is a physical copy that has to be
+// shipped.
+func (i OrderItem) NeedsShipping() bool {
+	return i.Format != FormatEbook && i.Format != FormatAudiobook
+}
+
+type OrderDiscount struct {
+	ID          int    `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	OrderID     int    `json:"order_id" gorm:"not null;index"`
+	Source      string `json:"source" gorm:"size:20;not null"`
+	PromotionID *int   `json:"promotion_id,omitempty"`
+	Code        string `json:"code,omitempty" gorm:"size:50"`
+	Label       string `json:"label" gorm:"size:255;not null"`
+	Explanation string `json:"explanation" gorm:"size:500"`
+	Amount      int64  `json:"amount" gorm:"not null"`
+}
+
+type OrderTax struct {
+	ID        int     `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	OrderID   int     `json:"order_id" gorm:"not null;index"`
+	VariantID int     `json:"variant_id" gorm:"not null"`
+	Name      string  `json:"name" gorm:"size:50;not null"`
+	Rate      float64 `json:"rate" gorm:"type:numeric(7,4);not null"`
+	Inclusive bool    `json:"inclusive" gorm:"not null"`
+	Taxable   int64   `json:"taxable" gorm:"not null"`
+	Amount    int64   `json:"amount" gorm:"not null"`
+}
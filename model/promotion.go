@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+const (
+	PromotionTypePercentage = "percentage"
+	PromotionTypeFixed      = "fixed"
+	PromotionTypeBuyXGetY   = "buy_x_get_y"
+	PromotionTypeBundle     = "bundle"
+)
+
+// Promotion is a pricing rule applied to carts without a code. What Value
+// means depends on Type:
+//
+//   - percentage: percent off every matching item
+//   - fixed: amount off every matching item
+//   - buy_x_get_y: percent off the GetQty cheapest items of every BuyQty+GetQty
+//     matching items, so 100 makes them free
+//   - bundle: price of every BuyQty matching items
+//
+// Promotions are applied by descending Priority, then by id. Items
+// discounted by a promotion that is not Stackable are no longer available to
+// the promotions after it; a stackable promotion leaves them available and
+// later rules work on the already discounted price.
+type Promotion struct {
+	ID          int                 `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	Name        string              `json:"name" gorm:"size:150;not null"`
+	Description string              `json:"description" gorm:"size:255"`
+	Type        string              `json:"type" gorm:"size:20;not null"`
+	Value       int                 `json:"value" gorm:"not null"`
+	BuyQty      int                 `json:"buy_qty" gorm:"not null;default:0"`
+	GetQty      int                 `json:"get_qty" gorm:"not null;default:0"`
+	Priority    int                 `json:"priority" gorm:"not null;default:0;index"`
+	Stackable   bool                `json:"stackable" gorm:"not null"`
+	StartsAt    *time.Time          `json:"starts_at"`
+	EndsAt      *time.Time          `json:"ends_at"`
+	IsActive    bool                `json:"is_active" gorm:"not null"`
+	Categories  []PromotionCategory `json:"-" gorm:"foreignKey:PromotionID;constraint:OnDelete:CASCADE"`
+	Books       []PromotionBook     `json:"-" gorm:"foreignKey:PromotionID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// PromotionCategory scopes a promotion to a category and everything nested
+// below it.
+type PromotionCategory struct {
+	PromotionID int `json:"promotion_id" gorm:"primaryKey"`
+	CategoryID  int `json:"category_id" gorm:"primaryKey"`
+}
+
+// PromotionBook scopes a promotion to every variant of a book.
+type PromotionBook struct {
+	PromotionID int `json:"promotion_id" gorm:"primaryKey"`
+	BookID      int `json:"book_id" gorm:"primaryKey"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type Publisher struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	Name      string    `json:"name" gorm:"size:150;not null;uniqueIndex"`
+	Website   string    `json:"website" gorm:"size:255"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
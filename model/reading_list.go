@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// ReadingList is a named, ordered list of books. Public lists can be opened
+// by anyone holding the share token, without signing in.
+type ReadingList struct {
+	ID          int               `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	UserID      int               `json:"user_id" gorm:"not null;index"`
+	Name        string            `json:"name" gorm:"size:150;not null"`
+	Description string            `json:"description" gorm:"type:text"`
+	IsPublic    bool              `json:"is_public" gorm:"not null;default:false"`
+	ShareToken  string            `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Items       []ReadingListItem `json:"items" gorm:"foreignKey:ReadingListID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+type ReadingListItem struct {
+	ReadingListID int       `json:"reading_list_id" gorm:"primaryKey"`
+	BookID        int       `json:"book_id" gorm:"primaryKey"`
+	Book          Book      `json:"-" gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE"`
+	Position      int       `json:"position" gorm:"not null"`
+	Note          string    `json:"note" gorm:"size:500"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
+
+// Refund is money paid back on an order through the refund provider, either
+// for a return or on its own. Pending refunds already count against the
+// order's refundable amount until the provider answers. Amount is in the
+// minor unit of Currency, which is always the order's.
+type Refund struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	OrderID     int       `json:"order_id" gorm:"not null;index"`
+	ReturnID    *int      `json:"return_id,omitempty" gorm:"index"`
+	Amount      int64     `json:"amount" gorm:"not null"`
+	Currency    string    `json:"currency" gorm:"size:3;not null"`
+	Reason      string    `json:"reason,omitempty" gorm:"size:255"`
+	Status      string    `json:"status" gorm:"size:20;not null"`
+	Provider    string    `json:"provider" gorm:"size:20;not null"`
+	ProviderRef string    `json:"provider_ref,omitempty" gorm:"size:100"`
+	Error       string    `json:"error,omitempty" gorm:"size:255"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
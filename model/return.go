@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusReceived  = "received"
+	ReturnStatusRefunded  = "refunded"
+)
+
+// ReturnRequest asks to send back Qty copies of one order item. It moves from
+// requested to approved or rejected; approved returns are received back into
+// stock and then refunded. RefundedAmount is in the currency of the order.
+type ReturnRequest struct {
+	ID             int           `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	OrderID        int           `json:"order_id" gorm:"not null;index"`
+	OrderItemID    int           `json:"order_item_id" gorm:"not null;index"`
+	UserID         int           `json:"user_id" gorm:"not null;index"`
+	Qty            int           `json:"qty" gorm:"not null"`
+	Reason         string        `json:"reason" gorm:"size:30;not null"`
+	Comment        string        `json:"comment,omitempty" gorm:"size:1000"`
+	Status         string        `json:"status" gorm:"size:20;not null;index"`
+	AdminNote      string        `json:"admin_note,omitempty" gorm:"size:500"`
+	RefundedAmount int64         `json:"refunded_amount" gorm:"not null;default:0"`
+	Photos         []ReturnPhoto `json:"photos" gorm:"foreignKey:ReturnID;constraint:OnDelete:CASCADE"`
+	ReviewedAt     *time.Time    `json:"reviewed_at"`
+	ReceivedAt     *time.Time    `json:"received_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// ReturnPhoto is a picture the customer attached to a return, kept in the
+// private return photo store under Key. URL points at the authorised
+// endpoint that serves it.
+type ReturnPhoto struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	ReturnID  int       `json:"return_id" gorm:"not null;index"`
+	Key       string    `json:"-" gorm:"size:255;not null"`
+	URL       string    `json:"url" gorm:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// Review is a user's rating of a book, at most one per user and book.
+// VerifiedPurchase is set when the user has a paid order for the book. Only
+// approved reviews are shown publicly and count towards the book rating.
+//
+// Status defaults to approved in the database so reviews written before
+// moderation existed stay published; new reviews are always stored as
+// pending.
+type Review struct {
+	ID               int            `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	UserID           int            `json:"user_id" gorm:"not null;uniqueIndex:idx_reviews_user_book"`
+	User             User           `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	BookID           int            `json:"book_id" gorm:"not null;uniqueIndex:idx_reviews_user_book;index"`
+	Stars            int            `json:"stars" gorm:"not null"`
+	Title            string         `json:"title" gorm:"size:150"`
+	Body             string         `json:"body" gorm:"type:text"`
+	VerifiedPurchase bool           `json:"verified_purchase" gorm:"not null;default:false"`
+	Status           string         `json:"status" gorm:"size:20;not null;default:approved;index"`
+	RejectReason     string         `json:"reject_reason" gorm:"size:500"`
+	ModeratedBy      *int           `json:"moderated_by"`
+	ModeratedAt      *time.Time     `json:"moderated_at"`
+	HelpfulCount     int            `json:"helpful_count" gorm:"not null;default:0"`
+	UnhelpfulCount   int            `json:"unhelpful_count" gorm:"not null;default:0"`
+	Reports          []ReviewReport `json:"-" gorm:"foreignKey:ReviewID;constraint:OnDelete:CASCADE"`
+	Votes            []ReviewVote   `json:"-" gorm:"foreignKey:ReviewID;constraint:OnDelete:CASCADE"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// ReviewVote is one user's helpful or unhelpful vote on a review.
+type ReviewVote struct {
+	ReviewID  int       `json:"review_id" gorm:"primaryKey"`
+	UserID    int       `json:"user_id" gorm:"primaryKey"`
+	Helpful   bool      `json:"helpful" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReviewReport is a user flagging a review as abusive. Reports stay open
+// until an admin moderates the review; a user has at most one open report
+// per review.
+type ReviewReport struct {
+	ID         int        `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	ReviewID   int        `json:"review_id" gorm:"not null;uniqueIndex:idx_review_reports_open,where:resolved_at IS NULL"`
+	UserID     int        `json:"user_id" gorm:"not null;uniqueIndex:idx_review_reports_open,where:resolved_at IS NULL"`
+	Reason     string     `json:"reason" gorm:"size:500;not null"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	ShipmentStatusPending        = "pending"
+	ShipmentStatusShipped        = "shipped"
+	ShipmentStatusInTransit      = "in_transit"
+	ShipmentStatusOutForDelivery = "out_for_delivery"
+	ShipmentStatusDelivered      = "delivered"
+	ShipmentStatusFailed         = "failed"
+)
+
+// shipmentNextStatuses lists the statuses a shipment can move to from each
+// status. A shipment only moves forward through the carrier's stages and may
+// report a stage again, e.g. for another scan in transit; a failed delivery
+// can be tried again. Delivered is final.
+var shipmentNextStatuses = map[string][]string{
+	ShipmentStatusPending:        {ShipmentStatusShipped, ShipmentStatusInTransit, ShipmentStatusOutForDelivery, ShipmentStatusDelivered},
+	ShipmentStatusShipped:        {ShipmentStatusShipped, ShipmentStatusInTransit, ShipmentStatusOutForDelivery, ShipmentStatusDelivered, ShipmentStatusFailed},
+	ShipmentStatusInTransit:      {ShipmentStatusInTransit, ShipmentStatusOutForDelivery, ShipmentStatusDelivered, ShipmentStatusFailed},
+	ShipmentStatusOutForDelivery: {ShipmentStatusOutForDelivery, ShipmentStatusDelivered, ShipmentStatusFailed},
+	ShipmentStatusFailed:         {ShipmentStatusFailed, ShipmentStatusInTransit, ShipmentStatusOutForDelivery, ShipmentStatusDelivered},
+}
+
+// Shipment is a parcel the warehouse sends for an order. An order can go out
+// in several shipments, each carrying part of its physical items. Status is
+// that of the latest event; delivered shipments take no further events.
+type Shipment struct {
+	ID             int             `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	OrderID        int             `json:"order_id" gorm:"not null;index"`
+	Carrier        string          `json:"carrier" gorm:"size:50;not null"`
+	TrackingNumber string          `json:"tracking_number" gorm:"size:100;not null;default:''"`
+	Status         string          `json:"status" gorm:"size:20;not null;index"`
+	Items          []ShipmentItem  `json:"items" gorm:"foreignKey:ShipmentID;constraint:OnDelete:CASCADE"`
+	Events         []ShipmentEvent `json:"events" gorm:"foreignKey:ShipmentID;constraint:OnDelete:CASCADE"`
+	ShippedAt      *time.Time      `json:"shipped_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// CanMoveTo reports whether an event with the status can be recorded on the
+// shipment.
+func (s Shipment) CanMoveTo(status string) bool {
+	return slices.Contains(shipmentNextStatuses[s.Status], status)
+}
+
+// ShipmentItem is the quantity of an order item packed in a shipment.
+type ShipmentItem struct {
+	ID          int `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	ShipmentID  int `json:"shipment_id" gorm:"not null;index"`
+	OrderItemID int `json:"order_item_id" gorm:"not null;index"`
+	Qty         int `json:"qty" gorm:"not null"`
+}
+
+// ShipmentEvent records a status the carrier reported for a shipment, at
+// the time it happened rather than when it was recorded.
+type ShipmentEvent struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	ShipmentID  int       `json:"shipment_id" gorm:"not null;index"`
+	Status      string    `json:"status" gorm:"size:20;not null"`
+	Location    string    `json:"location,omitempty" gorm:"size:100"`
+	Description string    `json:"description,omitempty" gorm:"size:255"`
+	OccurredAt  time.Time `json:"occurred_at" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+const (
+	ShippingTypeFlat   = "flat"
+	ShippingTypeWeight = "weight"
+)
+
+// ShippingZone groups the countries a shipping method delivers to.
+type ShippingZone struct {
+	ID        int                   `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	Name      string                `json:"name" gorm:"size:100;not null;uniqueIndex"`
+	Countries []ShippingZoneCountry `json:"countries" gorm:"foreignKey:ZoneID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+type ShippingZoneCountry struct {
+	ZoneID  int    `json:"-" gorm:"primaryKey"`
+	Country string `json:"country" gorm:"primaryKey;size:2"`
+}
+
+// ShippingMethod is a way to ship a parcel. Methods without a zone deliver
+// everywhere. Flat methods cost FlatRate per parcel and weight methods the
+// price of the lightest bracket the parcel fits in; a parcel heavier than
+// every bracket cannot go with the method. Orders whose items, after
+// discounts, come to FreeOver or more ship for free. Amounts are in the base
+// currency.
+type ShippingMethod struct {
+	ID            int            `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	Name          string         `json:"name" gorm:"size:100;not null"`
+	ZoneID        *int           `json:"zone_id" gorm:"index"`
+	Zone          *ShippingZone  `json:"zone,omitempty" gorm:"foreignKey:ZoneID;constraint:OnDelete:RESTRICT"`
+	Type          string         `json:"type" gorm:"size:20;not null"`
+	FlatRate      int64          `json:"flat_rate" gorm:"not null;default:0"`
+	Rates         []ShippingRate `json:"rates" gorm:"foreignKey:MethodID;constraint:OnDelete:CASCADE"`
+	FreeOver      *int64         `json:"free_over"`
+	EstimatedDays string         `json:"estimated_days" gorm:"size:50"`
+	IsActive      bool           `json:"is_active" gorm:"not null"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// ShippingRate is a weight bracket of a weight method: parcels up to
+// MaxWeight grams cost Price.
+type ShippingRate struct {
+	ID        int   `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	MethodID  int   `json:"method_id" gorm:"not null;index"`
+	MaxWeight int   `json:"max_weight" gorm:"not null"`
+	Price     int64 `json:"price" gorm:"not null"`
+}
+
+// ShippingQuote is what a shipping method would charge for the cart.
+type ShippingQuote struct {
+	MethodId      int    `json:"method_id"`
+	Name          string `json:"name"`
+	EstimatedDays string `json:"estimated_days,omitempty"`
+	Amount        Money  `json:"amount"`
+	Free          bool   `json:"free"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+const (
+	SlugEntityBook     = "book"
+	SlugEntityCategory = "category"
+)
+
+// SlugRedirect keeps a slug that was replaced after a rename so old links can
+// still be resolved to the entity that used to own it.
+type SlugRedirect struct {
+	ID         int       `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	EntityType string    `json:"entity_type" gorm:"size:50;not null;uniqueIndex:idx_slug_redirect"`
+	Slug       string    `json:"slug" gorm:"size:255;not null;uniqueIndex:idx_slug_redirect"`
+	EntityID   int       `json:"entity_id" gorm:"not null;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+const (
+	TaxClassPrintedBook = "printed_book"
+	TaxClassEbook       = "ebook"
+	TaxClassAudiobook   = "audiobook"
+)
+
+// TaxRule is the rate charged on a product class shipped to a country, or to
+// one region of it. An empty Region covers the whole country and an empty
+// ProductClass every class; the most specific rule wins. Rate is a
+// percentage. Inclusive rules treat prices as already containing the tax,
+// as VAT usually is, while exclusive rules add it on top like sales tax.
+type TaxRule struct {
+	ID           int       `json:"id" gorm:"primaryKey;autoIncrement:true"`
+	Country      string    `json:"country" gorm:"size:2;not null;uniqueIndex:idx_tax_rules_scope"`
+	Region       string    `json:"region" gorm:"size:64;not null;default:'';uniqueIndex:idx_tax_rules_scope"`
+	ProductClass string    `json:"product_class" gorm:"size:20;not null;default:'';uniqueIndex:idx_tax_rules_scope"`
+	Name         string    `json:"name" gorm:"size:50;not null"`
+	Rate         float64   `json:"rate" gorm:"type:numeric(7,4);not null"`
+	Inclusive    bool      `json:"inclusive" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TaxLine is the tax on one cart line. Taxable is what is left to pay for the
+// line after discounts, and Amount the part of it, or the amount on top of
+// it for exclusive rules, that is tax.
+type TaxLine struct {
+	VariantId int     `json:"variant_id"`
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+	Taxable   Money   `json:"taxable"`
+	Amount    Money   `json:"amount"`
+}
+
+// TaxClassOf maps a variant format to the product class tax rules are set
+// for. Print formats share the printed book class.
+func TaxClassOf(format string) string {
+	switch format {
+	case FormatEbook:
+		return TaxClassEbook
+	case FormatAudiobook:
+		return TaxClassAudiobook
+	default:
+		return TaxClassPrintedBook
+	}
+}
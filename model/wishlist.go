@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// WishlistItem is a book a user saved for later. VariantID remembers the
+// format they picked, if any, for moving the book to the cart.
+type WishlistItem struct {
+	UserID    int       `json:"user_id" gorm:"primaryKey"`
+	BookID    int       `json:"book_id" gorm:"primaryKey"`
+	Book      Book      `json:"-" gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE"`
+	VariantID *int      `json:"variant_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
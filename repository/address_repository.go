@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+)
+
+type AddressRepository interface {
+	CreateAddress(address *model.Address) (*model.Address, error)
+	FindByUser(userId int) ([]model.Address, error)
+	FindById(id int) (*model.Address, error)
+	FindDefault(userId int) (*model.Address, error)
+	UpdateAddress(address *model.Address) (*model.Address, error)
+	DeleteAddress(address *model.Address) error
+	SetDefault(address *model.Address) error
+}
+
+type addressRepository struct {
+	db *gorm.DB
+}
+
+// CreateAddress saves the address, making it the default when it is the
+// user's first address or asks to be the default.
+func (ar *addressRepository) CreateAddress(address *model.Address) (*model.Address, error) {
+	err := ar.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&model.Address{}).Where("user_id = ?", address.UserID).Count(&count).Error
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			address.IsDefault = true
+		}
+
+		if address.IsDefault {
+			err = clearDefaultAddress(tx, address.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(address).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to create address")
+	}
+
+	return address, nil
+}
+
+func (ar *addressRepository) FindByUser(userId int) ([]model.Address, error) {
+	var addresses []model.Address
+
+	err := ar.db.Where("user_id = ?", userId).
+		Order("is_default DESC").Order("created_at DESC").Order("id DESC").
+		Find(&addresses).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+func (ar *addressRepository) FindById(id int) (*model.Address, error) {
+	var address model.Address
+
+	err := ar.db.First(&address, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("address not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+func (ar *addressRepository) FindDefault(userId int) (*model.Address, error) {
+	var address model.Address
+
+	err := ar.db.Where("user_id = ? AND is_default = ?", userId, true).First(&address).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("address not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+func (ar *addressRepository) UpdateAddress(address *model.Address) (*model.Address, error) {
+	err := ar.db.Save(address).Error
+	if err != nil {
+		return nil, errors.New("failed to update address")
+	}
+
+	return address, nil
+}
+
+// DeleteAddress removes the address. When it was the default, the most
+// recently added of the remaining addresses takes its place.
+func (ar *addressRepository) DeleteAddress(address *model.Address) error {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Delete(&model.Address{}, address.ID).Error
+		if err != nil {
+			return err
+		}
+
+		if !address.IsDefault {
+			return nil
+		}
+
+		var next model.Address
+		err = tx.Where("user_id = ?", address.UserID).Order("created_at DESC").Order("id DESC").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+// SetDefault makes the address the user's only default address.
+func (ar *addressRepository) SetDefault(address *model.Address) error {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		err := clearDefaultAddress(tx, address.UserID)
+		if err != nil {
+			return err
+		}
+
+		address.IsDefault = true
+		return tx.Model(address).Update("is_default", true).Error
+	})
+}
+
+func clearDefaultAddress(tx *gorm.DB, userId int) error {
+	return tx.Model(&model.Address{}).
+		Where("user_id = ? AND is_default = ?", userId, true).
+		Update("is_default", false).Error
+}
+
+func NewAddressRepository(db *gorm.DB) *addressRepository {
+	return &addressRepository{db: db}
+}
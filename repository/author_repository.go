@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+)
+
+type AuthorRepository interface {
+	CreateAuthor(author *model.Author) (*model.Author, error)
+	FindAll() ([]model.Author, error)
+	FindById(id int) (*model.Author, error)
+	FindOrCreateByName(name string) (*model.Author, error)
+	UpdateAuthor(id int, updateAuthor *model.Author) (*model.Author, error)
+	DeleteAuthor(id int) error
+	CountBooks(id int) (int64, error)
+}
+
+type authorRepository struct {
+	db *gorm.DB
+}
+
+func (ar *authorRepository) CreateAuthor(author *model.Author) (*model.Author, error) {
+	err := ar.db.Create(author).Error
+	if err != nil {
+		return nil, errors.New("failed to create author")
+	}
+
+	return author, nil
+}
+
+func (ar *authorRepository) FindAll() ([]model.Author, error) {
+	var authors []model.Author
+
+	err := ar.db.Order("name ASC").Find(&authors).Error
+	if err != nil {
+		return nil, errors.New("failed to find all authors")
+	}
+
+	return authors, nil
+}
+
+func (ar *authorRepository) FindById(id int) (*model.Author, error) {
+	var author model.Author
+
+	err := ar.db.First(&author, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("author not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &author, nil
+}
+
+// FindOrCreateByName matches names case-insensitively so spelling variants in
+// letter case collapse onto a single author.
+func (ar *authorRepository) FindOrCreateByName(name string) (*model.Author, error) {
+	return findOrCreateAuthor(ar.db, name)
+}
+
+func (ar *authorRepository) UpdateAuthor(id int, updateAuthor *model.Author) (*model.Author, error) {
+	var author model.Author
+
+	err := ar.db.First(&author, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("author not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = ar.db.Model(&author).Select("name", "bio").Updates(updateAuthor).Error
+	if err != nil {
+		return nil, errors.New("failed to update author")
+	}
+
+	return &author, nil
+}
+
+func (ar *authorRepository) DeleteAuthor(id int) error {
+	err := ar.db.Delete(&model.Author{}, id).Error
+	if err != nil {
+		return errors.New("failed to delete author")
+	}
+
+	return nil
+}
+
+func (ar *authorRepository) CountBooks(id int) (int64, error) {
+	var count int64
+
+	err := ar.db.Model(&model.BookAuthor{}).Where("author_id = ?", id).Distinct("book_id").Count(&count).Error
+	if err != nil {
+		return 0, errors.New("failed to count books of author")
+	}
+
+	return count, nil
+}
+
+func findOrCreateAuthor(tx *gorm.DB, name string) (*model.Author, error) {
+	var author model.Author
+
+	err := tx.Where("LOWER(name) = LOWER(?)", name).First(&author).Error
+	if err == nil {
+		return &author, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	author = model.Author{Name: name}
+	err = tx.Create(&author).Error
+	if err != nil {
+		return nil, errors.New("failed to create author")
+	}
+
+	return &author, nil
+}
+
+func NewAuthorRepository(db *gorm.DB) *authorRepository {
+	return &authorRepository{db: db}
+}
@@ -2,31 +2,94 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/mhmmmdrivaldhi/go-book-api/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookRepository interface {
 	CreateBook(book *model.Book) (*model.Book, error)
-	FindAll() ([]model.Book, error)
+	FindAll(filter BookFilter) ([]model.Book, int64, error)
+	StreamAll(filter BookFilter, fn func(row BookExportRow) error) error
 	FindById(id int) (*model.Book, error)
-	UpdateBook(id int, updateBook *model.Book) (*model.Book, error)
+	FindByIds(ids []int) ([]model.Book, error)
+	FindBySlug(slug string) (*model.Book, error)
+	FindByIsbn(isbn13 string) (*model.Book, error)
+	FindWithoutSlug() ([]model.Book, error)
+	SlugExists(slug string, excludeId int) (bool, error)
+	FindWithoutAuthors() ([]model.Book, error)
+	UpdateBook(id int, columns map[string]interface{}, authors []model.BookAuthor) (*model.Book, error)
+	ReplaceAuthors(id int, authors []model.BookAuthor) error
+	UpdateCover(id int, coverKey *string) error
 	DeleteBook(id int) error
 }
 
+// ErrBookNotFound is returned by FindByIsbn when no book has the ISBN.
+var ErrBookNotFound = errors.New("book not found")
+
+type BookFilter struct {
+	CategoryIds []int
+	AuthorId    int
+	PublisherId int
+	Limit       int
+	Offset      int
+}
+
+// BookExportRow is the flat shape books are streamed in for exports, with
+// the category and publisher names resolved in the same query. Contributors
+// holds the credited authors as a JSON array of name and role, in order, and
+// Variants the variants as a JSON array of sku, format, price and currency.
+type BookExportRow struct {
+	Id            int
+	Title         string
+	Slug          *string
+	Isbn13        *string
+	Isbn10        *string
+	Description   string
+	Author        string
+	CategoryID    int
+	CategoryName  string
+	PublisherName *string
+	Contributors  *string
+	Variants      *string
+	Rating        float64
+	RatingCount   int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
 type bookRepository struct {
 	db *gorm.DB
 }
 
 func (bookRepo *bookRepository) CreateBook(book *model.Book) (*model.Book, error) {
-	err := bookRepo.db.Create(&book).Error
+	err := bookRepo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Create(book).Error
+		if err != nil {
+			return err
+		}
+
+		if len(book.Variants) > 0 {
+			for i := range book.Variants {
+				book.Variants[i].BookID = book.Id
+			}
+
+			err = tx.Create(&book.Variants).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return saveBookAuthors(tx, book.Id, book.Authors)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	var res model.Book
-	err = bookRepo.db.Preload("Category").First(&res, book.Id).Error
+	err = preloadBook(bookRepo.db).First(&res, book.Id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -34,25 +97,71 @@ func (bookRepo *bookRepository) CreateBook(book *model.Book) (*model.Book, error
 	return &res, nil
 }
 
-func (bookRepo *bookRepository) FindAll() ([]model.Book, error) {
+func (bookRepo *bookRepository) FindAll(filter BookFilter) ([]model.Book, int64, error) {
 	var books []model.Book
+	var total int64
 
-	err := bookRepo.db.Preload("Category").Find(&books).Error
+	err := applyBookFilter(bookRepo.db.Model(&model.Book{}), filter).Count(&total).Error
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	if len(books) == 0 {
-		return nil, errors.New("no books found")
+	query := applyBookFilter(preloadBook(bookRepo.db), filter).Order("id ASC")
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit).Offset(filter.Offset)
 	}
 
-	return books, nil
+	err = query.Find(&books).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return books, total, nil
+}
+
+// StreamAll walks every book matching the filter through a database cursor
+// and hands them to fn one at a time, so exports never hold the whole catalog
+// in memory. Returning an error from fn stops the walk.
+func (bookRepo *bookRepository) StreamAll(filter BookFilter, fn func(row BookExportRow) error) error {
+	query := applyBookFilter(bookRepo.db.Model(&model.Book{}), filter).
+		Select("books.id, books.title, books.slug, books.isbn13, books.isbn10, books.description, books.author, " +
+			"books.category_id, books.rating, books.rating_count, books.created_at, books.updated_at, " +
+			"(SELECT name FROM categories WHERE categories.id = books.category_id) AS category_name, " +
+			"(SELECT name FROM publishers WHERE publishers.id = books.publisher_id) AS publisher_name, " +
+			"(SELECT json_agg(json_build_object('name', authors.name, 'role', book_authors.role) ORDER BY book_authors.position) " +
+			"FROM book_authors JOIN authors ON authors.id = book_authors.author_id WHERE book_authors.book_id = books.id) AS contributors, " +
+			"(SELECT json_agg(json_build_object('sku', book_variants.sku, 'format', book_variants.format, 'price', book_variants.price_amount, " +
+			"'currency', book_variants.price_currency) ORDER BY book_variants.id) " +
+			"FROM book_variants WHERE book_variants.book_id = books.id) AS variants").
+		Order("id ASC")
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row BookExportRow
+		err = bookRepo.db.ScanRows(rows, &row)
+		if err != nil {
+			return err
+		}
+
+		err = fn(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
 
 func (bookRepo *bookRepository) FindById(id int) (*model.Book, error) {
 	var book model.Book
 
-	err := bookRepo.db.Preload("Category").First(&book, id).Error
+	err := preloadBook(bookRepo.db).First(&book, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.New("book not found")
 	} else if err != nil {
@@ -62,30 +171,134 @@ func (bookRepo *bookRepository) FindById(id int) (*model.Book, error) {
 	return &book, nil
 }
 
-func (bookRepo *bookRepository) UpdateBook(id int, updateBook *model.Book) (*model.Book, error) {
+func (bookRepo *bookRepository) FindByIds(ids []int) ([]model.Book, error) {
+	var books []model.Book
+
+	if len(ids) == 0 {
+		return books, nil
+	}
+
+	err := preloadBook(bookRepo.db).Where("id IN ?", ids).Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (bookRepo *bookRepository) FindBySlug(slug string) (*model.Book, error) {
 	var book model.Book
 
-	err := bookRepo.db.First(&book, id).Error
+	err := preloadBook(bookRepo.db).Where("slug = ?", slug).First(&book).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.New("book not found")
 	} else if err != nil {
 		return nil, err
 	}
 
-	err = bookRepo.db.Model(&book).Updates(updateBook).Error
+	return &book, nil
+}
+
+func (bookRepo *bookRepository) FindByIsbn(isbn13 string) (*model.Book, error) {
+	var book model.Book
+
+	err := preloadBook(bookRepo.db).Where("isbn13 = ?", isbn13).First(&book).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBookNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+func (bookRepo *bookRepository) FindWithoutSlug() ([]model.Book, error) {
+	var books []model.Book
+
+	err := bookRepo.db.Where("slug IS NULL").Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+// FindWithoutAuthors returns books that still only carry the free-text author
+// string and have no book_authors rows yet.
+func (bookRepo *bookRepository) FindWithoutAuthors() ([]model.Book, error) {
+	var books []model.Book
+
+	err := bookRepo.db.
+		Where("author <> ''").
+		Where("NOT EXISTS (SELECT 1 FROM book_authors WHERE book_authors.book_id = books.id)").
+		Find(&books).Error
 	if err != nil {
 		return nil, err
 	}
 
-	err = bookRepo.db.Preload("Category").First(&book, id).Error
+	return books, nil
+}
+
+func (bookRepo *bookRepository) SlugExists(slug string, excludeId int) (bool, error) {
+	var count int64
+
+	err := bookRepo.db.Model(&model.Book{}).Where("slug = ? AND id <> ?", slug, excludeId).Count(&count).Error
 	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// UpdateBook writes only the given columns, so columns kept up to date by
+// other requests, such as the rating, are never written back stale. The
+// authors are replaced in the same transaction when authors is not nil.
+func (bookRepo *bookRepository) UpdateBook(id int, columns map[string]interface{}, authors []model.BookAuthor) (*model.Book, error) {
+	err := bookRepo.db.Transaction(func(tx *gorm.DB) error {
+		var book model.Book
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&book, id).Error
+		if err != nil {
+			return err
+		}
+
+		if len(columns) > 0 {
+			err = tx.Model(&model.Book{}).Where("id = ?", id).Updates(columns).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if authors == nil {
+			return nil
+		}
+
+		return saveBookAuthors(tx, id, authors)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("book not found")
+	} else if err != nil {
 		return nil, err
 	}
 
+	var book model.Book
+	err = preloadBook(bookRepo.db).First(&book, id).Error
+	if err != nil {
+		return nil, err
+	}
 
 	return &book, nil
 }
 
+func (bookRepo *bookRepository) ReplaceAuthors(id int, authors []model.BookAuthor) error {
+	return bookRepo.db.Transaction(func(tx *gorm.DB) error {
+		return saveBookAuthors(tx, id, authors)
+	})
+}
+
+func (bookRepo *bookRepository) UpdateCover(id int, coverKey *string) error {
+	return bookRepo.db.Model(&model.Book{}).Where("id = ?", id).Update("cover_key", coverKey).Error
+}
+
 func (bookRepo *bookRepository) DeleteBook(id int) error {
 	err := bookRepo.db.Delete(&model.Book{}, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -97,6 +310,63 @@ func (bookRepo *bookRepository) DeleteBook(id int) error {
 	return nil
 }
 
+func preloadBook(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Category").
+		Preload("Authors", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Preload("Authors.Author").
+		Preload("Publisher").
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Variants.Prices", func(db *gorm.DB) *gorm.DB { return db.Order("currency ASC") })
+}
+
+// saveBookAuthors replaces the authors of a book. Authors only known by name,
+// from a free-text author string, are matched or created in the same
+// transaction, so a failed save leaves no authors behind.
+func saveBookAuthors(tx *gorm.DB, bookId int, authors []model.BookAuthor) error {
+	err := tx.Where("book_id = ?", bookId).Delete(&model.BookAuthor{}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(authors) == 0 {
+		return nil
+	}
+
+	for i := range authors {
+		authors[i].BookID = bookId
+		if authors[i].AuthorID != 0 {
+			continue
+		}
+
+		author, err := findOrCreateAuthor(tx, authors[i].Author.Name)
+		if err != nil {
+			return err
+		}
+		authors[i].AuthorID = author.ID
+		authors[i].Author = *author
+	}
+
+	return tx.Omit("Author").Create(&authors).Error
+}
+
+// applyBookFilter adds the where clauses shared by every book listing.
+func applyBookFilter(query *gorm.DB, filter BookFilter) *gorm.DB {
+	if len(filter.CategoryIds) > 0 {
+		query = query.Where("category_id IN ?", filter.CategoryIds)
+	}
+
+	if filter.AuthorId != 0 {
+		query = query.Where("books.id IN (SELECT book_id FROM book_authors WHERE author_id = ?)", filter.AuthorId)
+	}
+
+	if filter.PublisherId != 0 {
+		query = query.Where("publisher_id = ?", filter.PublisherId)
+	}
+
+	return query
+}
+
 func NewBookRepository(db *gorm.DB) *bookRepository {
 	return &bookRepository{db: db}
 }
\ No newline at end of file
@@ -3,32 +3,134 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/mhmmmdrivaldhi/go-book-api/model"
 	"github.com/redis/go-redis/v9"
 )
 
 type CartRepository interface {
-	GetCart(ctx context.Context, userId int) (*model.Cart, error)
-	SetCart(ctx context.Context, userId int, cart *model.Cart) (*model.Cart, error)
-	ClearCart(ctx context.Context, userId int) error
+	GetCart(ctx context.Context, owner model.CartOwner) (*model.Cart, error)
+	UpdateCart(ctx context.Context, owner model.CartOwner, update func(cart *model.Cart) error) (*model.Cart, error)
+	ClearCart(ctx context.Context, owner model.CartOwner) error
 }
 
+var (
+	// ErrCartUnchanged can be returned by the update given to UpdateCart to
+	// leave the stored cart as it is; only its expiry is pushed back.
+	ErrCartUnchanged = errors.New("cart unchanged")
+	// ErrCartBusy is returned when UpdateCart kept losing the race against
+	// other changes to the same cart.
+	ErrCartBusy = errors.New("cart is being changed by another request, try again")
+)
+
+// maxCartUpdateAttempts bounds how often UpdateCart starts over after
+// another request changed the cart first.
+const maxCartUpdateAttempts = 10
+
 type cartRepository struct {
 	redis *redis.Client
+	ttl time.Duration
+	guestTTL time.Duration
+}
+
+// GetCart loads the cart. Reading leaves its expiry alone; touching the key
+// would fail an UpdateCart of the same cart that is watching it. Callers that
+// should keep the cart alive read it through UpdateCart instead.
+func (cr *cartRepository) GetCart(ctx context.Context, owner model.CartOwner) (*model.Cart, error) {
+	key := cartKey(owner)
+
+	return decodeCart(owner, cr.redis.Get(ctx, key))
+}
+
+// UpdateCart applies update to the cart and saves the result as one atomic
+// change, for another TTL, so a cart only expires once it has been left
+// alone for that long. Guest carts have a TTL of their own, usually
+// shorter than that of users. The key is watched while the cart is read and
+// updated, and the write only goes through when nobody else wrote the cart
+// in the meantime; otherwise update runs again on the cart as the other
+// request left it. So two requests changing the same cart at once never
+// lose each other's changes, and the totals update works out are always
+// those of the items saved with them. An error from update is returned as
+// it is and nothing is saved.
+func (cr *cartRepository) UpdateCart(ctx context.Context, owner model.CartOwner, update func(cart *model.Cart) error) (*model.Cart, error) {
+	key := cartKey(owner)
+
+	for attempt := 0; attempt < maxCartUpdateAttempts; attempt++ {
+		var cart *model.Cart
+		err := cr.redis.Watch(ctx, func(tx *redis.Tx) error {
+			var err error
+			cart, err = decodeCart(owner, tx.Get(ctx, key))
+			if err != nil {
+				return err
+			}
+
+			err = update(cart)
+			if err != nil {
+				return err
+			}
+
+			data, err := json.Marshal(cart)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, data, cr.expiration(owner))
+				return nil
+			})
+			return err
+		}, key)
+
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		} else if errors.Is(err, ErrCartUnchanged) {
+			err = cr.redis.Expire(ctx, key, cr.expiration(owner)).Err()
+			if err != nil {
+				return nil, err
+			}
+
+			return cart, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		return cart, nil
+	}
+
+	return nil, ErrCartBusy
+}
+
+func (cr *cartRepository) ClearCart(ctx context.Context, owner model.CartOwner) error {
+	key := cartKey(owner)
+
+	err := cr.redis.Del(ctx, key).Err()
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func (cr *cartRepository) GetCart(ctx context.Context, userId int) (*model.Cart, error) {
-	key := fmt.Sprintf("cart: %d", userId)
+func (cr *cartRepository) expiration(owner model.CartOwner) time.Duration {
+	if owner.IsGuest() {
+		return cr.guestTTL
+	}
+
+	return cr.ttl
+}
 
-	data, err := cr.redis.Get(ctx, key).Result()
+// decodeCart reads the cart out of a GET reply. A missing cart is an empty
+// one.
+func decodeCart(owner model.CartOwner, cmd *redis.StringCmd) (*model.Cart, error) {
+	data, err := cmd.Result()
 	if err == redis.Nil {
 		return &model.Cart{
-			UserId: userId,
+			UserId: owner.UserId,
 			Items: []model.Item{},
 			TotalQty: 0,
-			TotalPrice: 0,
 		}, nil
 	} else if err != nil {
 		return nil, err
@@ -43,33 +145,14 @@ func (cr *cartRepository) GetCart(ctx context.Context, userId int) (*model.Cart,
 	return &cart, nil
 }
 
-func (cr *cartRepository) SetCart(ctx context.Context, userId int, cart *model.Cart) (*model.Cart, error) {
-	key := fmt.Sprintf("cart: %d", userId)
-
-	data, err := json.Marshal(cart)
-	if err != nil {
-		return nil, err
-	}
-
-	err = cr.redis.Set(ctx, key, data, 0).Err()
-	if err != nil {
-		return nil, err
+func cartKey(owner model.CartOwner) string {
+	if owner.IsGuest() {
+		return fmt.Sprintf("guest-cart: %s", owner.Token)
 	}
 
-	return cart, err
-}
-
-func (cr *cartRepository) ClearCart(ctx context.Context, userId int) error {
-	key := fmt.Sprintf("cart: %d", userId)
-
-	err := cr.redis.Del(ctx, key).Err()
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return fmt.Sprintf("cart: %d", owner.UserId)
 }
 
-func NewCartRepository(redis *redis.Client) *cartRepository {
-	return &cartRepository{redis: redis}
+func NewCartRepository(redis *redis.Client, ttl, guestTTL time.Duration) *cartRepository {
+	return &cartRepository{redis: redis, ttl: ttl, guestTTL: guestTTL}
 }
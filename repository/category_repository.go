@@ -5,16 +5,30 @@ import (
 
 	"github.com/mhmmmdrivaldhi/go-book-api/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoryRepository interface {
 	CreateCategory(category *model.Category) (*model.Category, error)
 	FindAll() ([]model.Category, error)
 	FindById(id int) (*model.Category, error)
+	FindBySlug(slug string) (*model.Category, error)
+	FindWithoutSlug() ([]model.Category, error)
+	SlugExists(slug string, excludeId int) (bool, error)
 	UpdateCategory(id int, updateCategory *model.Category) (*model.Category, error)
 	DeleteCategory(id int) error
+	CountBooks(id int) (int64, error)
+	CountBooksByCategory() (map[int]int64, error)
+	ReassignBooksAndDelete(id, targetId int) (int64, error)
+	DeleteWithBooks(id int) (int64, error)
+	FindDescendantIds(id int) ([]int, error)
+	FindAncestors(ids []int) ([]model.Category, error)
 }
 
+// ErrCategoryHasBooks is returned by DeleteCategory for a category that
+// still has books.
+var ErrCategoryHasBooks = errors.New("category still has books")
+
 type categoryRepository struct {
 	db *gorm.DB
 }
@@ -50,6 +64,39 @@ func (cr *categoryRepository) FindById(id int) (*model.Category, error) {
 	return &category, nil
 }
 
+func (cr *categoryRepository) FindBySlug(slug string) (*model.Category, error) {
+	var category model.Category
+
+	err := cr.db.Where("slug = ?", slug).First(&category).Error
+	if err != nil {
+		return nil, errors.New("failed to find category by slug")
+	}
+
+	return &category, nil
+}
+
+func (cr *categoryRepository) FindWithoutSlug() ([]model.Category, error) {
+	var categories []model.Category
+
+	err := cr.db.Where("slug IS NULL").Find(&categories).Error
+	if err != nil {
+		return nil, errors.New("failed to find categories without slug")
+	}
+
+	return categories, nil
+}
+
+func (cr *categoryRepository) SlugExists(slug string, excludeId int) (bool, error) {
+	var count int64
+
+	err := cr.db.Model(&model.Category{}).Where("slug = ? AND id <> ?", slug, excludeId).Count(&count).Error
+	if err != nil {
+		return false, errors.New("failed to check category slug")
+	}
+
+	return count > 0, nil
+}
+
 func (cr *categoryRepository) UpdateCategory(id int, updateCategory *model.Category) (*model.Category, error) {
 	var category model.Category
 
@@ -58,7 +105,7 @@ func (cr *categoryRepository) UpdateCategory(id int, updateCategory *model.Categ
 		return nil, errors.New("failed to find category by id")
 	}
 
-	err = cr.db.Model(&category).Updates(updateCategory).Error
+	err = cr.db.Model(&category).Select("name", "slug", "parent_id").Updates(updateCategory).Error
 	if err != nil {
 		return nil, errors.New("failed to update category")
 	}
@@ -66,10 +113,38 @@ func (cr *categoryRepository) UpdateCategory(id int, updateCategory *model.Categ
 	return &category, nil 
 }
 
+// DeleteCategory deletes a category without books. The category is locked
+// while its books are counted, so a book cannot be added to it between the
+// count and the delete.
 func (cr *categoryRepository) DeleteCategory(id int) error {
-	err := cr.db.Delete(&model.Category{}, id).Error
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
+		var category model.Category
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category, id).Error
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&model.Book{}).Where("category_id = ?", id).Count(&count).Error
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrCategoryHasBooks
+		}
+
+		err = liftChildren(tx, id)
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&model.Category{}, id).Error
+	})
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.New("category not found")
+	} else if errors.Is(err, ErrCategoryHasBooks) {
+		return err
 	} else if err != nil {
 		return errors.New("failed to delete category")
 	}
@@ -77,6 +152,140 @@ func (cr *categoryRepository) DeleteCategory(id int) error {
 	return nil
 }
 
+func (cr *categoryRepository) CountBooks(id int) (int64, error) {
+	var count int64
+
+	err := cr.db.Model(&model.Book{}).Where("category_id = ?", id).Count(&count).Error
+	if err != nil {
+		return 0, errors.New("failed to count books in category")
+	}
+
+	return count, nil
+}
+
+// CountBooksByCategory counts the books of every category in one grouped
+// query. Categories without books are absent from the map.
+func (cr *categoryRepository) CountBooksByCategory() (map[int]int64, error) {
+	var rows []struct {
+		CategoryID int
+		Total      int64
+	}
+
+	err := cr.db.Model(&model.Book{}).Select("category_id, COUNT(*) AS total").Group("category_id").Scan(&rows).Error
+	if err != nil {
+		return nil, errors.New("failed to count books per category")
+	}
+
+	counts := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Total
+	}
+
+	return counts, nil
+}
+
+func (cr *categoryRepository) ReassignBooksAndDelete(id, targetId int) (int64, error) {
+	var affected int64
+
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Book{}).Where("category_id = ?", id).Update("category_id", targetId)
+		if res.Error != nil {
+			return res.Error
+		}
+		affected = res.RowsAffected
+
+		err := liftChildren(tx, id)
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&model.Category{}, id).Error
+	})
+	if err != nil {
+		return 0, errors.New("failed to reassign books and delete category")
+	}
+
+	return affected, nil
+}
+
+func (cr *categoryRepository) DeleteWithBooks(id int) (int64, error) {
+	var affected int64
+
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("category_id = ?", id).Delete(&model.Book{})
+		if res.Error != nil {
+			return res.Error
+		}
+		affected = res.RowsAffected
+
+		err := liftChildren(tx, id)
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&model.Category{}, id).Error
+	})
+	if err != nil {
+		return 0, errors.New("failed to delete category with its books")
+	}
+
+	return affected, nil
+}
+
+// FindDescendantIds returns the id of the category itself followed by the ids
+// of every category nested below it.
+func (cr *categoryRepository) FindDescendantIds(id int) ([]int, error) {
+	var ids []int
+
+	err := cr.db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE id = ?
+			UNION
+			SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT id FROM tree`, id).Scan(&ids).Error
+	if err != nil {
+		return nil, errors.New("failed to find descendant categories")
+	}
+
+	return ids, nil
+}
+
+// FindAncestors returns the categories with the given ids together with every
+// category above them, up to the roots.
+func (cr *categoryRepository) FindAncestors(ids []int) ([]model.Category, error) {
+	var categories []model.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+
+	err := cr.db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT * FROM categories WHERE id IN ?
+			UNION
+			SELECT c.* FROM categories c JOIN tree t ON c.id = t.parent_id
+		)
+		SELECT * FROM tree`, ids).Scan(&categories).Error
+	if err != nil {
+		return nil, errors.New("failed to find ancestor categories")
+	}
+
+	return categories, nil
+}
+
+// liftChildren moves the direct children of a category up to its parent so
+// subcategories survive whichever delete strategy is used.
+func liftChildren(tx *gorm.DB, id int) error {
+	var category model.Category
+
+	err := tx.First(&category, id).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&model.Category{}).Where("parent_id = ?", id).Update("parent_id", category.ParentID).Error
+}
+
 func NewCategoryRepository(db *gorm.DB) *categoryRepository {
 	return &categoryRepository{db: db}
 } 
\ No newline at end of file
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CouponRepository interface {
+	CreateCoupon(coupon *model.Coupon) (*model.Coupon, error)
+	FindAll(limit, offset int) ([]model.Coupon, int64, error)
+	FindById(id int) (*model.Coupon, error)
+	FindByCode(code string) (*model.Coupon, error)
+	CodeExists(code string, excludeId int) (bool, error)
+	UpdateCoupon(coupon *model.Coupon, replaceCategories, replaceBooks bool) (*model.Coupon, error)
+	DeleteCoupon(id int) error
+	CountRedemptions(couponId, userId int) (int64, int64, error)
+	FindBookCategories(bookIds []int) (map[int]int, error)
+	FindDescendantCategoryIds(categoryIds []int) ([]int, error)
+}
+
+// ErrCouponUsedUp is returned when placing an order would take a coupon past
+// its global or per-user usage limit.
+var ErrCouponUsedUp = errors.New("coupon has reached its usage limit")
+
+type couponRepository struct {
+	db *gorm.DB
+}
+
+func (cr *couponRepository) CreateCoupon(coupon *model.Coupon) (*model.Coupon, error) {
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Create(coupon).Error
+		if err != nil {
+			return err
+		}
+
+		return saveCouponScope(tx, coupon, true, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cr.FindById(coupon.ID)
+}
+
+func (cr *couponRepository) FindAll(limit, offset int) ([]model.Coupon, int64, error) {
+	var coupons []model.Coupon
+	var total int64
+
+	err := cr.db.Model(&model.Coupon{}).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = cr.db.Preload("Categories").Preload("Books").
+		Order("created_at DESC").Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&coupons).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return coupons, total, nil
+}
+
+func (cr *couponRepository) FindById(id int) (*model.Coupon, error) {
+	var coupon model.Coupon
+
+	err := cr.db.Preload("Categories").Preload("Books").First(&coupon, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("coupon not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &coupon, nil
+}
+
+func (cr *couponRepository) FindByCode(code string) (*model.Coupon, error) {
+	var coupon model.Coupon
+
+	err := cr.db.Preload("Categories").Preload("Books").Where("code = ?", code).First(&coupon).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("coupon not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &coupon, nil
+}
+
+func (cr *couponRepository) CodeExists(code string, excludeId int) (bool, error) {
+	var count int64
+
+	err := cr.db.Model(&model.Coupon{}).Where("code = ? AND id <> ?", code, excludeId).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// UpdateCoupon saves the coupon's own columns and, when asked, replaces its
+// category or book scope with the one on the coupon.
+func (cr *couponRepository) UpdateCoupon(coupon *model.Coupon, replaceCategories, replaceBooks bool) (*model.Coupon, error) {
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(coupon).Select("*").Omit("id", "created_at", clause.Associations).Updates(coupon).Error
+		if err != nil {
+			return err
+		}
+
+		return saveCouponScope(tx, coupon, replaceCategories, replaceBooks)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cr.FindById(coupon.ID)
+}
+
+func (cr *couponRepository) DeleteCoupon(id int) error {
+	return cr.db.Delete(&model.Coupon{}, id).Error
+}
+
+// CountRedemptions returns how many times the coupon has been used in total
+// and by the given user, ignoring cancelled orders.
+func (cr *couponRepository) CountRedemptions(couponId, userId int) (int64, int64, error) {
+	return countRedemptions(cr.db, couponId, userId)
+}
+
+// FindBookCategories maps each of the given books to its category.
+func (cr *couponRepository) FindBookCategories(bookIds []int) (map[int]int, error) {
+	var rows []struct {
+		Id         int
+		CategoryID int
+	}
+
+	categories := map[int]int{}
+	if len(bookIds) == 0 {
+		return categories, nil
+	}
+
+	err := cr.db.Model(&model.Book{}).Select("id, category_id").Where("id IN ?", bookIds).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		categories[row.Id] = row.CategoryID
+	}
+
+	return categories, nil
+}
+
+// FindDescendantCategoryIds returns the given categories together with every
+// category nested below them.
+func (cr *couponRepository) FindDescendantCategoryIds(categoryIds []int) ([]int, error) {
+	var ids []int
+
+	if len(categoryIds) == 0 {
+		return ids, nil
+	}
+
+	err := cr.db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE id IN ?
+			UNION
+			SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT id FROM tree`, categoryIds).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func saveCouponScope(tx *gorm.DB, coupon *model.Coupon, replaceCategories, replaceBooks bool) error {
+	if replaceCategories {
+		err := tx.Where("coupon_id = ?", coupon.ID).Delete(&model.CouponCategory{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(coupon.Categories) > 0 {
+			for i := range coupon.Categories {
+				coupon.Categories[i].CouponID = coupon.ID
+			}
+
+			err = tx.Create(&coupon.Categories).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if replaceBooks {
+		err := tx.Where("coupon_id = ?", coupon.ID).Delete(&model.CouponBook{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(coupon.Books) > 0 {
+			for i := range coupon.Books {
+				coupon.Books[i].CouponID = coupon.ID
+			}
+
+			err = tx.Create(&coupon.Books).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func countRedemptions(db *gorm.DB, couponId, userId int) (int64, int64, error) {
+	var counts struct {
+		Total  int64
+		ByUser int64
+	}
+
+	err := db.Model(&model.CouponRedemption{}).
+		Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE coupon_redemptions.user_id = ?) AS by_user", userId).
+		Joins("JOIN orders ON orders.id = coupon_redemptions.order_id").
+		Where("coupon_redemptions.coupon_id = ? AND orders.status <> ?", couponId, model.OrderStatusCancelled).
+		Scan(&counts).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return counts.Total, counts.ByUser, nil
+}
+
+func NewCouponRepository(db *gorm.DB) *couponRepository {
+	return &couponRepository{db: db}
+}
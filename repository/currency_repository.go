@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CurrencyRepository interface {
+	FindRates() ([]model.ExchangeRate, error)
+	FindRate(currency string) (*model.ExchangeRate, error)
+	SaveRate(rate *model.ExchangeRate) (*model.ExchangeRate, error)
+	DeleteRate(currency string) error
+	BackfillCurrency(currency string) error
+}
+
+type currencyRepository struct {
+	db *gorm.DB
+}
+
+func (cr *currencyRepository) FindRates() ([]model.ExchangeRate, error) {
+	var rates []model.ExchangeRate
+
+	err := cr.db.Order("currency ASC").Find(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (cr *currencyRepository) FindRate(currency string) (*model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+
+	err := cr.db.Where("currency = ?", currency).First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("exchange rate not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+func (cr *currencyRepository) SaveRate(rate *model.ExchangeRate) (*model.ExchangeRate, error) {
+	err := cr.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (cr *currencyRepository) DeleteRate(currency string) error {
+	return cr.db.Where("currency = ?", currency).Delete(&model.ExchangeRate{}).Error
+}
+
+// BackfillCurrency stamps the base currency on prices and orders stored
+// before amounts carried one.
+func (cr *currencyRepository) BackfillCurrency(currency string) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Book{}).Where("price_currency = ''").Update("price_currency", currency).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.BookVariant{}).Where("price_currency = ''").Update("price_currency", currency).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Order{}).Where("currency = ''").Update("currency", currency).Error
+	})
+}
+
+// MigrateMoneyColumns renames the price column of books to the name of the
+// embedded money amount, keeping the stored prices. It must run before
+// AutoMigrate, which would otherwise add an empty amount column next to the
+// old one.
+func MigrateMoneyColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&model.Book{}) || !migrator.HasColumn(&model.Book{}, "price") || migrator.HasColumn(&model.Book{}, "price_amount") {
+		return nil
+	}
+
+	return migrator.RenameColumn(&model.Book{}, "price", "price_amount")
+}
+
+func NewCurrencyRepository(db *gorm.DB) *currencyRepository {
+	return &currencyRepository{db: db}
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+)
+
+type ImportRepository interface {
+	CreateJob(job *model.ImportJob) (*model.ImportJob, error)
+	FindById(id int) (*model.ImportJob, error)
+	UpdateJob(job *model.ImportJob) error
+	AddRowErrors(rowErrors []model.ImportRowError) error
+	FindRowErrors(jobId int) ([]model.ImportRowError, error)
+	FailUnfinished(message string) error
+}
+
+type importRepository struct {
+	db *gorm.DB
+}
+
+func (ir *importRepository) CreateJob(job *model.ImportJob) (*model.ImportJob, error) {
+	err := ir.db.Create(job).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (ir *importRepository) FindById(id int) (*model.ImportJob, error) {
+	var job model.ImportJob
+
+	err := ir.db.First(&job, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("import job not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (ir *importRepository) UpdateJob(job *model.ImportJob) error {
+	return ir.db.Model(job).Select("*").Omit("id", "user_id", "created_at").Updates(job).Error
+}
+
+func (ir *importRepository) AddRowErrors(rowErrors []model.ImportRowError) error {
+	if len(rowErrors) == 0 {
+		return nil
+	}
+
+	return ir.db.CreateInBatches(&rowErrors, 500).Error
+}
+
+func (ir *importRepository) FindRowErrors(jobId int) ([]model.ImportRowError, error) {
+	var rowErrors []model.ImportRowError
+
+	err := ir.db.Where("job_id = ?", jobId).Order("row_number ASC").Order("id ASC").Find(&rowErrors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rowErrors, nil
+}
+
+// FailUnfinished marks jobs that were queued or running when the process
+// stopped as failed, since nothing will pick them up again.
+func (ir *importRepository) FailUnfinished(message string) error {
+	return ir.db.Model(&model.ImportJob{}).
+		Where("status IN ?", []string{model.ImportStatusQueued, model.ImportStatusRunning}).
+		Updates(map[string]interface{}{"status": model.ImportStatusFailed, "error": message}).Error
+}
+
+func NewImportRepository(db *gorm.DB) *importRepository {
+	return &importRepository{db: db}
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceRepository interface {
+	CreateInvoice(invoice *model.Invoice) error
+	FindById(id int) (*model.Invoice, error)
+	FindByOrder(orderId int) ([]model.Invoice, error)
+	FindInvoiceByOrder(orderId int) (*model.Invoice, error)
+	FindByRefund(refundId int) (*model.Invoice, error)
+}
+
+// ErrInvoiceExists is returned when the order already has its invoice, or
+// the refund its credit note.
+var ErrInvoiceExists = errors.New("invoice has already been issued")
+
+// invoicePrefixes start the number of each kind of invoice.
+var invoicePrefixes = map[string]string{
+	model.InvoiceKindInvoice:    "INV",
+	model.InvoiceKindCreditNote: "CN",
+}
+
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+// CreateInvoice numbers and saves the invoice in one transaction. The order
+// is locked first, so an order or refund is never invoiced twice, and the
+// year's sequence row is advanced in the same transaction, so a number is
+// only taken when the invoice is saved with it and there are no gaps. The
+// lines are saved with the invoice.
+func (ir *invoiceRepository) CreateInvoice(invoice *model.Invoice) error {
+	return ir.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Order{}, invoice.OrderID).Error
+		if err != nil {
+			return err
+		}
+
+		query := tx.Model(&model.Invoice{})
+		if invoice.Kind == model.InvoiceKindCreditNote {
+			query = query.Where("refund_id = ?", invoice.RefundID)
+		} else {
+			query = query.Where("order_id = ? AND kind = ?", invoice.OrderID, invoice.Kind)
+		}
+
+		var count int64
+		err = query.Count(&count).Error
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrInvoiceExists
+		}
+
+		invoice.Year = invoice.IssuedAt.Year()
+		err = tx.Raw(`INSERT INTO invoice_sequences (kind, year, last) VALUES (?, ?, 1)
+			ON CONFLICT (kind, year) DO UPDATE SET last = invoice_sequences.last + 1
+			RETURNING last`, invoice.Kind, invoice.Year).Scan(&invoice.Sequence).Error
+		if err != nil {
+			return err
+		}
+
+		invoice.Number = fmt.Sprintf("%s-%d-%06d", invoicePrefixes[invoice.Kind], invoice.Year, invoice.Sequence)
+
+		return tx.Create(invoice).Error
+	})
+}
+
+func (ir *invoiceRepository) FindById(id int) (*model.Invoice, error) {
+	var invoice model.Invoice
+
+	err := ir.withLines().First(&invoice, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("invoice not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+// FindByOrder lists the invoice and credit notes of an order in the order
+// they were issued.
+func (ir *invoiceRepository) FindByOrder(orderId int) ([]model.Invoice, error) {
+	var invoices []model.Invoice
+
+	err := ir.withLines().Where("order_id = ?", orderId).Order("issued_at ASC").Order("id ASC").Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+func (ir *invoiceRepository) FindInvoiceByOrder(orderId int) (*model.Invoice, error) {
+	var invoice model.Invoice
+
+	err := ir.withLines().Where("order_id = ? AND kind = ?", orderId, model.InvoiceKindInvoice).First(&invoice).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("invoice not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+func (ir *invoiceRepository) FindByRefund(refundId int) (*model.Invoice, error) {
+	var invoice model.Invoice
+
+	err := ir.withLines().Where("refund_id = ?", refundId).First(&invoice).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("credit note not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+// withLines loads the lines of the invoices found in the order they were
+// printed.
+func (ir *invoiceRepository) withLines() *gorm.DB {
+	return ir.db.Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") })
+}
+
+func NewInvoiceRepository(db *gorm.DB) *invoiceRepository {
+	return &invoiceRepository{db: db}
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderRepository interface {
+	CreateOrder(order *model.Order, claim *CouponClaim) (*model.Order, error)
+	FindByUser(userId, limit, offset int) ([]model.Order, int64, error)
+	FindById(id int) (*model.Order, error)
+	HasPurchased(userId, bookId int) (bool, error)
+	MarkPaid(id int, paidAt time.Time) (bool, error)
+	CancelOrder(id int) (bool, error)
+	FindPendingBefore(before time.Time) ([]int, error)
+}
+
+// CouponClaim asks CreateOrder to redeem a coupon on the new order.
+type CouponClaim struct {
+	CouponID int
+	Discount int64
+}
+
+// ErrOutOfStock is returned when a physical item no longer has enough stock
+// at the moment the order is placed.
+var ErrOutOfStock = errors.New("not enough stock for the requested quantity")
+
+// purchasedStatuses are the order states in which the customer has paid for
+// the items.
+var purchasedStatuses = []string{model.OrderStatusPaid, model.OrderStatusShipped, model.OrderStatusDelivered}
+
+type orderRepository struct {
+	db *gorm.DB
+}
+
+// CreateOrder places the order in one transaction: stock of physical items
+// is taken, and the coupon in claim is locked, checked against its usage
+// limits and redeemed, so two checkouts cannot both use the last redemption.
+func (orderRepo *orderRepository) CreateOrder(order *model.Order, claim *CouponClaim) (*model.Order, error) {
+	err := orderRepo.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range order.Items {
+			if item.Format == model.FormatEbook || item.Format == model.FormatAudiobook {
+				continue
+			}
+
+			res := tx.Model(&model.BookVariant{}).
+				Where("id = ? AND stock >= ?", item.VariantID, item.Qty).
+				Update("stock", gorm.Expr("stock - ?", item.Qty))
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return ErrOutOfStock
+			}
+		}
+
+		if claim != nil {
+			var coupon model.Coupon
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&coupon, claim.CouponID).Error
+			if err != nil {
+				return err
+			}
+
+			total, byUser, err := countRedemptions(tx, coupon.ID, order.UserID)
+			if err != nil {
+				return err
+			}
+
+			if coupon.UsageLimit != nil && total >= int64(*coupon.UsageLimit) {
+				return ErrCouponUsedUp
+			}
+			if coupon.PerUserLimit != nil && byUser >= int64(*coupon.PerUserLimit) {
+				return ErrCouponUsedUp
+			}
+		}
+
+		err := tx.Create(order).Error
+		if err != nil {
+			return err
+		}
+
+		if claim == nil {
+			return nil
+		}
+
+		return tx.Omit(clause.Associations).Create(&model.CouponRedemption{
+			CouponID: claim.CouponID,
+			UserID:   order.UserID,
+			OrderID:  order.ID,
+			Discount: claim.Discount,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orderRepo.FindById(order.ID)
+}
+
+func (orderRepo *orderRepository) FindByUser(userId, limit, offset int) ([]model.Order, int64, error) {
+	var orders []model.Order
+	var total int64
+
+	query := orderRepo.db.Model(&model.Order{}).Where("user_id = ?", userId)
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = preloadOrder(query).
+		Order("created_at DESC").Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&orders).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+func (orderRepo *orderRepository) FindById(id int) (*model.Order, error) {
+	var order model.Order
+
+	err := preloadOrder(orderRepo.db).First(&order, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("order not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// HasPurchased reports whether the user has a paid order containing any
+// variant of the book.
+func (orderRepo *orderRepository) HasPurchased(userId, bookId int) (bool, error) {
+	var count int64
+
+	err := orderRepo.db.Model(&model.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND order_items.book_id = ? AND orders.status IN ?", userId, bookId, purchasedStatuses).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// MarkPaid moves a pending order to paid and reports whether it was still
+// pending.
+func (orderRepo *orderRepository) MarkPaid(id int, paidAt time.Time) (bool, error) {
+	res := orderRepo.db.Model(&model.Order{}).
+		Where("id = ? AND status = ?", id, model.OrderStatusPending).
+		Updates(map[string]interface{}{"status": model.OrderStatusPaid, "paid_at": paidAt})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+// CancelOrder cancels a pending order and puts the stock of its physical
+// items back, reporting whether it was still pending. A coupon redeemed on
+// the order stops counting towards its usage limits once the order is
+// cancelled.
+func (orderRepo *orderRepository) CancelOrder(id int) (bool, error) {
+	cancelled := false
+
+	err := orderRepo.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Order{}).
+			Where("id = ? AND status = ?", id, model.OrderStatusPending).
+			Update("status", model.OrderStatusCancelled)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		cancelled = true
+
+		var items []model.OrderItem
+		err := tx.Where("order_id = ?", id).Find(&items).Error
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if !item.NeedsShipping() {
+				continue
+			}
+
+			err = tx.Model(&model.BookVariant{}).
+				Where("id = ?", item.VariantID).
+				Update("stock", gorm.Expr("stock + ?", item.Qty)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return cancelled, nil
+}
+
+// FindPendingBefore lists the ids of orders still awaiting payment that were
+// placed before the given time.
+func (orderRepo *orderRepository) FindPendingBefore(before time.Time) ([]int, error) {
+	var ids []int
+
+	err := orderRepo.db.Model(&model.Order{}).
+		Where("status = ? AND created_at < ?", model.OrderStatusPending, before).
+		Order("id ASC").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func preloadOrder(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Discounts", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Taxes", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Refunds", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") })
+}
+
+func NewOrderRepository(db *gorm.DB) *orderRepository {
+	return &orderRepository{db: db}
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromotionRepository interface {
+	CreatePromotion(promotion *model.Promotion) (*model.Promotion, error)
+	FindAll(limit, offset int) ([]model.Promotion, int64, error)
+	FindActive(at time.Time) ([]model.Promotion, error)
+	FindById(id int) (*model.Promotion, error)
+	UpdatePromotion(promotion *model.Promotion, replaceCategories, replaceBooks bool) (*model.Promotion, error)
+	DeletePromotion(id int) error
+}
+
+type promotionRepository struct {
+	db *gorm.DB
+}
+
+func (pr *promotionRepository) CreatePromotion(promotion *model.Promotion) (*model.Promotion, error) {
+	err := pr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Create(promotion).Error
+		if err != nil {
+			return err
+		}
+
+		return savePromotionScope(tx, promotion, true, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.FindById(promotion.ID)
+}
+
+func (pr *promotionRepository) FindAll(limit, offset int) ([]model.Promotion, int64, error) {
+	var promotions []model.Promotion
+	var total int64
+
+	err := pr.db.Model(&model.Promotion{}).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = pr.db.Preload("Categories").Preload("Books").
+		Order("priority DESC").Order("id ASC").
+		Limit(limit).Offset(offset).
+		Find(&promotions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return promotions, total, nil
+}
+
+// FindActive returns the promotions running at the given time in the order
+// the pricing engine applies them.
+func (pr *promotionRepository) FindActive(at time.Time) ([]model.Promotion, error) {
+	var promotions []model.Promotion
+
+	err := pr.db.Preload("Categories").Preload("Books").
+		Where("is_active = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", at).
+		Where("ends_at IS NULL OR ends_at > ?", at).
+		Order("priority DESC").Order("id ASC").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+func (pr *promotionRepository) FindById(id int) (*model.Promotion, error) {
+	var promotion model.Promotion
+
+	err := pr.db.Preload("Categories").Preload("Books").First(&promotion, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("promotion not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &promotion, nil
+}
+
+// UpdatePromotion saves the promotion's own columns and, when asked, replaces
+// its category or book scope with the one on the promotion.
+func (pr *promotionRepository) UpdatePromotion(promotion *model.Promotion, replaceCategories, replaceBooks bool) (*model.Promotion, error) {
+	err := pr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(promotion).Select("*").Omit("id", "created_at", clause.Associations).Updates(promotion).Error
+		if err != nil {
+			return err
+		}
+
+		return savePromotionScope(tx, promotion, replaceCategories, replaceBooks)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.FindById(promotion.ID)
+}
+
+func (pr *promotionRepository) DeletePromotion(id int) error {
+	return pr.db.Delete(&model.Promotion{}, id).Error
+}
+
+func savePromotionScope(tx *gorm.DB, promotion *model.Promotion, replaceCategories, replaceBooks bool) error {
+	if replaceCategories {
+		err := tx.Where("promotion_id = ?", promotion.ID).Delete(&model.PromotionCategory{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(promotion.Categories) > 0 {
+			for i := range promotion.Categories {
+				promotion.Categories[i].PromotionID = promotion.ID
+			}
+
+			err = tx.Create(&promotion.Categories).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if replaceBooks {
+		err := tx.Where("promotion_id = ?", promotion.ID).Delete(&model.PromotionBook{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(promotion.Books) > 0 {
+			for i := range promotion.Books {
+				promotion.Books[i].PromotionID = promotion.ID
+			}
+
+			err = tx.Create(&promotion.Books).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func NewPromotionRepository(db *gorm.DB) *promotionRepository {
+	return &promotionRepository{db: db}
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+)
+
+type PublisherRepository interface {
+	CreatePublisher(publisher *model.Publisher) (*model.Publisher, error)
+	FindAll() ([]model.Publisher, error)
+	FindById(id int) (*model.Publisher, error)
+	UpdatePublisher(id int, updatePublisher *model.Publisher) (*model.Publisher, error)
+	DeletePublisher(id int) error
+	CountBooks(id int) (int64, error)
+}
+
+type publisherRepository struct {
+	db *gorm.DB
+}
+
+func (pr *publisherRepository) CreatePublisher(publisher *model.Publisher) (*model.Publisher, error) {
+	err := pr.db.Create(publisher).Error
+	if err != nil {
+		return nil, errors.New("failed to create publisher")
+	}
+
+	return publisher, nil
+}
+
+func (pr *publisherRepository) FindAll() ([]model.Publisher, error) {
+	var publishers []model.Publisher
+
+	err := pr.db.Order("name ASC").Find(&publishers).Error
+	if err != nil {
+		return nil, errors.New("failed to find all publishers")
+	}
+
+	return publishers, nil
+}
+
+func (pr *publisherRepository) FindById(id int) (*model.Publisher, error) {
+	var publisher model.Publisher
+
+	err := pr.db.First(&publisher, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("publisher not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &publisher, nil
+}
+
+func (pr *publisherRepository) UpdatePublisher(id int, updatePublisher *model.Publisher) (*model.Publisher, error) {
+	var publisher model.Publisher
+
+	err := pr.db.First(&publisher, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("publisher not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = pr.db.Model(&publisher).Select("name", "website").Updates(updatePublisher).Error
+	if err != nil {
+		return nil, errors.New("failed to update publisher")
+	}
+
+	return &publisher, nil
+}
+
+func (pr *publisherRepository) DeletePublisher(id int) error {
+	err := pr.db.Delete(&model.Publisher{}, id).Error
+	if err != nil {
+		return errors.New("failed to delete publisher")
+	}
+
+	return nil
+}
+
+func (pr *publisherRepository) CountBooks(id int) (int64, error) {
+	var count int64
+
+	err := pr.db.Model(&model.Book{}).Where("publisher_id = ?", id).Count(&count).Error
+	if err != nil {
+		return 0, errors.New("failed to count books of publisher")
+	}
+
+	return count, nil
+}
+
+func NewPublisherRepository(db *gorm.DB) *publisherRepository {
+	return &publisherRepository{db: db}
+}
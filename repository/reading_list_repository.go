@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReadingListRepository interface {
+	CreateList(list *model.ReadingList) (*model.ReadingList, error)
+	FindByUser(userId int) ([]model.ReadingList, error)
+	FindById(id int) (*model.ReadingList, error)
+	FindByShareToken(token string) (*model.ReadingList, error)
+	UpdateList(list *model.ReadingList) (*model.ReadingList, error)
+	DeleteList(id int) error
+	AddItem(item *model.ReadingListItem) error
+	DeleteItem(listId, bookId int) error
+}
+
+type readingListRepository struct {
+	db *gorm.DB
+}
+
+func (rlr *readingListRepository) CreateList(list *model.ReadingList) (*model.ReadingList, error) {
+	err := rlr.db.Omit(clause.Associations).Create(list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rlr.FindById(list.ID)
+}
+
+func (rlr *readingListRepository) FindByUser(userId int) ([]model.ReadingList, error) {
+	var lists []model.ReadingList
+
+	err := preloadReadingList(rlr.db).Where("user_id = ?", userId).Order("created_at ASC").Find(&lists).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+func (rlr *readingListRepository) FindById(id int) (*model.ReadingList, error) {
+	var list model.ReadingList
+
+	err := preloadReadingList(rlr.db).First(&list, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("reading list not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+func (rlr *readingListRepository) FindByShareToken(token string) (*model.ReadingList, error) {
+	var list model.ReadingList
+
+	err := preloadReadingList(rlr.db).Where("share_token = ?", token).First(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("reading list not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+func (rlr *readingListRepository) UpdateList(list *model.ReadingList) (*model.ReadingList, error) {
+	err := rlr.db.Model(list).Select("name", "description", "is_public", "share_token").Updates(list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rlr.FindById(list.ID)
+}
+
+func (rlr *readingListRepository) DeleteList(id int) error {
+	return rlr.db.Delete(&model.ReadingList{}, id).Error
+}
+
+// AddItem appends the book at the end of the list. Adding a book that is
+// already on the list only updates its note.
+func (rlr *readingListRepository) AddItem(item *model.ReadingListItem) error {
+	return rlr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.ReadingListItem{}).
+			Where("reading_list_id = ?", item.ReadingListID).
+			Select("COALESCE(MAX(position) + 1, 0)").
+			Scan(&item.Position).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "reading_list_id"}, {Name: "book_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"note"}),
+		}).Create(item).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.ReadingList{ID: item.ReadingListID}).Update("updated_at", gorm.Expr("NOW()")).Error
+	})
+}
+
+func (rlr *readingListRepository) DeleteItem(listId, bookId int) error {
+	return rlr.db.Where("reading_list_id = ? AND book_id = ?", listId, bookId).Delete(&model.ReadingListItem{}).Error
+}
+
+func preloadReadingList(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") })
+}
+
+func NewReadingListRepository(db *gorm.DB) *readingListRepository {
+	return &readingListRepository{db: db}
+}
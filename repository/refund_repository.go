@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefundRepository interface {
+	Reserve(refund *model.Refund) error
+	Complete(refund *model.Refund) error
+	Fail(refund *model.Refund) error
+}
+
+var (
+	// ErrRefundExceedsOrder is returned when a refund would pay back more
+	// than is left to refund on the order.
+	ErrRefundExceedsOrder = errors.New("refund exceeds the amount left to refund")
+	// ErrReturnRefunded is returned when a return already has a refund that
+	// succeeded or is under way.
+	ErrReturnRefunded = errors.New("return has already been refunded")
+)
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+// Reserve saves the refund as pending and counts it against the order, with
+// the order locked, before the provider is asked to pay it. That way two
+// refunds started at once cannot together pay back more than the order.
+func (rr *refundRepository) Reserve(refund *model.Refund) error {
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		var order model.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, refund.OrderID).Error
+		if err != nil {
+			return err
+		}
+
+		if order.TotalRefunded+refund.Amount > order.TotalPrice {
+			return ErrRefundExceedsOrder
+		}
+
+		if refund.ReturnID != nil {
+			var count int64
+			err = tx.Model(&model.Refund{}).
+				Where("return_id = ? AND status <> ?", *refund.ReturnID, model.RefundStatusFailed).
+				Count(&count).Error
+			if err != nil {
+				return err
+			}
+
+			if count > 0 {
+				return ErrReturnRefunded
+			}
+		}
+
+		refund.Currency = order.Currency
+		refund.Status = model.RefundStatusPending
+		err = tx.Create(refund).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Order{}).Where("id = ?", order.ID).
+			Update("total_refunded", gorm.Expr("total_refunded + ?", refund.Amount)).Error
+	})
+}
+
+// Complete records that the provider paid the refund. The return it was for
+// becomes refunded, and so does the order once it is refunded in full.
+func (rr *refundRepository) Complete(refund *model.Refund) error {
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		refund.Status = model.RefundStatusSucceeded
+		err := tx.Model(refund).Updates(map[string]interface{}{
+			"status":       refund.Status,
+			"provider_ref": refund.ProviderRef,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if refund.ReturnID != nil {
+			err = tx.Model(&model.ReturnRequest{}).Where("id = ?", *refund.ReturnID).Updates(map[string]interface{}{
+				"status":          model.ReturnStatusRefunded,
+				"refunded_amount": gorm.Expr("refunded_amount + ?", refund.Amount),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var paidBack int64
+		err = tx.Model(&model.Refund{}).
+			Select("COALESCE(SUM(amount), 0)").
+			Where("order_id = ? AND status = ?", refund.OrderID, model.RefundStatusSucceeded).
+			Scan(&paidBack).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Order{}).
+			Where("id = ? AND total_price <= ?", refund.OrderID, paidBack).
+			Update("status", model.OrderStatusRefunded).Error
+	})
+}
+
+// Fail records that the provider refused the refund and gives its amount
+// back to what is left to refund on the order.
+func (rr *refundRepository) Fail(refund *model.Refund) error {
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		refund.Status = model.RefundStatusFailed
+		err := tx.Model(refund).Updates(map[string]interface{}{
+			"status": refund.Status,
+			"error":  refund.Error,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Order{}).Where("id = ?", refund.OrderID).
+			Update("total_refunded", gorm.Expr("total_refunded - ?", refund.Amount)).Error
+	})
+}
+
+func NewRefundRepository(db *gorm.DB) *refundRepository {
+	return &refundRepository{db: db}
+}
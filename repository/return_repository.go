@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReturnRepository interface {
+	CreateReturn(ret *model.ReturnRequest) (*model.ReturnRequest, error)
+	FindAll(userId int, status string, limit, offset int) ([]model.ReturnRequest, int64, error)
+	FindById(id int) (*model.ReturnRequest, error)
+	Review(ret *model.ReturnRequest, status, note string) (bool, error)
+	AddPhoto(photo *model.ReturnPhoto) error
+	CountPhotos(returnId int) (int64, error)
+	Receive(ret *model.ReturnRequest, restockVariantId int) (bool, error)
+}
+
+// ErrReturnExceedsOrder is returned when a return would send back more
+// copies of an order item than are not already being returned.
+var ErrReturnExceedsOrder = errors.New("return exceeds the quantity left to return")
+
+type returnRepository struct {
+	db *gorm.DB
+}
+
+// CreateReturn saves the return after checking, with the order locked, that
+// the copies are not already covered by another return that was not
+// rejected.
+func (rr *returnRepository) CreateReturn(ret *model.ReturnRequest) (*model.ReturnRequest, error) {
+	err := rr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Order{}, ret.OrderID).Error
+		if err != nil {
+			return err
+		}
+
+		var item model.OrderItem
+		err = tx.Where("id = ? AND order_id = ?", ret.OrderItemID, ret.OrderID).First(&item).Error
+		if err != nil {
+			return err
+		}
+
+		var returned int64
+		err = tx.Model(&model.ReturnRequest{}).
+			Select("COALESCE(SUM(qty), 0)").
+			Where("order_item_id = ? AND status <> ?", ret.OrderItemID, model.ReturnStatusRejected).
+			Scan(&returned).Error
+		if err != nil {
+			return err
+		}
+
+		if int(returned)+ret.Qty > item.Qty {
+			return ErrReturnExceedsOrder
+		}
+
+		return tx.Create(ret).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rr.FindById(ret.ID)
+}
+
+// FindAll lists returns, newest first. A userId of 0 lists the returns of
+// every user and an empty status those in any state.
+func (rr *returnRepository) FindAll(userId int, status string, limit, offset int) ([]model.ReturnRequest, int64, error) {
+	var returns []model.ReturnRequest
+	var total int64
+
+	query := rr.db.Model(&model.ReturnRequest{})
+	if userId != 0 {
+		query = query.Where("user_id = ?", userId)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Preload("Photos", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Order("created_at DESC").Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&returns).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return returns, total, nil
+}
+
+func (rr *returnRepository) FindById(id int) (*model.ReturnRequest, error) {
+	var ret model.ReturnRequest
+
+	err := rr.db.Preload("Photos", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).First(&ret, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("return not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// Review approves or rejects a return that is still requested, with the
+// admin's note. A return that was reviewed in the meantime is left alone,
+// so two admins never both decide on it, and false is returned.
+func (rr *returnRepository) Review(ret *model.ReturnRequest, status, note string) (bool, error) {
+	now := time.Now()
+	res := rr.db.Model(&model.ReturnRequest{}).
+		Where("id = ? AND status = ?", ret.ID, model.ReturnStatusRequested).
+		Updates(map[string]interface{}{"status": status, "admin_note": note, "reviewed_at": now})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+
+	ret.Status = status
+	ret.AdminNote = note
+	ret.ReviewedAt = &now
+
+	return true, nil
+}
+
+func (rr *returnRepository) AddPhoto(photo *model.ReturnPhoto) error {
+	return rr.db.Create(photo).Error
+}
+
+func (rr *returnRepository) CountPhotos(returnId int) (int64, error) {
+	var count int64
+
+	err := rr.db.Model(&model.ReturnPhoto{}).Where("return_id = ?", returnId).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Receive marks an approved return as received and puts the copies back in
+// stock of the variant, unless restockVariantId is 0. A return that is no
+// longer approved is left alone, so a copy is never restocked twice, and
+// false is returned.
+func (rr *returnRepository) Receive(ret *model.ReturnRequest, restockVariantId int) (bool, error) {
+	received := false
+	err := rr.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&model.ReturnRequest{}).
+			Where("id = ? AND status = ?", ret.ID, model.ReturnStatusApproved).
+			Updates(map[string]interface{}{"status": model.ReturnStatusReceived, "received_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		received = true
+		ret.Status = model.ReturnStatusReceived
+		ret.ReceivedAt = &now
+
+		if restockVariantId == 0 {
+			return nil
+		}
+
+		return tx.Model(&model.BookVariant{}).
+			Where("id = ?", restockVariantId).
+			Update("stock", gorm.Expr("stock + ?", ret.Qty)).Error
+	})
+
+	return received, err
+}
+
+func NewReturnRepository(db *gorm.DB) *returnRepository {
+	return &returnRepository{db: db}
+}
@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewRepository interface {
+	CreateReview(review *model.Review) (*model.Review, error)
+	FindById(id int) (*model.Review, error)
+	FindByBook(filter ReviewFilter) ([]model.Review, int64, error)
+	FindForModeration(queue string, limit, offset int) ([]model.Review, int64, error)
+	FindByUserAndBook(userId, bookId int) (*model.Review, error)
+	UpdateReview(review *model.Review) (*model.Review, error)
+	Moderate(review *model.Review) (*model.Review, error)
+	DeleteReview(review *model.Review) error
+	SaveVote(vote *model.ReviewVote) error
+	DeleteVote(reviewId, userId int) error
+	ReportExists(reviewId, userId int) (bool, error)
+	CreateReport(report *model.ReviewReport) error
+	RefreshAllBookRatings() error
+}
+
+var (
+	// ErrReviewNotFound is returned by FindByUserAndBook when the user has
+	// not reviewed the book.
+	ErrReviewNotFound = errors.New("review not found")
+
+	// ErrReviewExists is returned by CreateReview when the user already has
+	// a review of the book, e.g. from a concurrent request.
+	ErrReviewExists = errors.New("review already exists")
+
+	// ErrReportExists is returned by CreateReport when the user already has
+	// an open report on the review.
+	ErrReportExists = errors.New("report already exists")
+)
+
+type ReviewFilter struct {
+	BookId int
+	Status string
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+var reviewSortColumns = map[string]string{
+	"helpful": "helpful_count - unhelpful_count DESC, helpful_count DESC",
+	"newest":  "created_at DESC",
+	"highest": "stars DESC, created_at DESC",
+	"lowest":  "stars ASC, created_at DESC",
+}
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+// refreshBookRatingSQL recomputes the denormalized rating average and count
+// from the approved reviews of the books matched by the trailing condition.
+const refreshBookRatingSQL = `UPDATE books SET
+	rating = COALESCE((SELECT ROUND(AVG(stars)::numeric, 2) FROM reviews WHERE reviews.book_id = books.id AND reviews.status = 'approved'), 0),
+	rating_count = (SELECT COUNT(*) FROM reviews WHERE reviews.book_id = books.id AND reviews.status = 'approved')`
+
+// openReportsSQL matches reviews that have at least one unresolved report.
+const openReportsSQL = "EXISTS (SELECT 1 FROM review_reports WHERE review_reports.review_id = reviews.id AND review_reports.resolved_at IS NULL)"
+
+func (rr *reviewRepository) CreateReview(review *model.Review) (*model.Review, error) {
+	err := rr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Create(review).Error
+		if err != nil {
+			return err
+		}
+
+		return refreshBookRating(tx, review.BookID)
+	})
+	if isUniqueViolation(rr.db, err) {
+		return nil, ErrReviewExists
+	} else if err != nil {
+		return nil, err
+	}
+
+	return rr.FindById(review.ID)
+}
+
+func (rr *reviewRepository) FindById(id int) (*model.Review, error) {
+	var review model.Review
+
+	err := rr.db.Preload("User").Preload("Reports").First(&review, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("review not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (rr *reviewRepository) FindByBook(filter ReviewFilter) ([]model.Review, int64, error) {
+	var reviews []model.Review
+	var total int64
+
+	query := rr.db.Model(&model.Review{}).Where("book_id = ?", filter.BookId)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	order, ok := reviewSortColumns[filter.Sort]
+	if !ok {
+		order = reviewSortColumns["newest"]
+	}
+
+	err = query.Preload("User").
+		Order(order).Order("id DESC").
+		Limit(filter.Limit).Offset(filter.Offset).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+// FindForModeration lists the reviews waiting in a moderation queue: pending
+// reviews, reviews with open reports, or rejected reviews. Oldest come first
+// so nothing waits forever.
+func (rr *reviewRepository) FindForModeration(queue string, limit, offset int) ([]model.Review, int64, error) {
+	var reviews []model.Review
+	var total int64
+
+	query := rr.db.Model(&model.Review{})
+	switch queue {
+	case "reported":
+		query = query.Where(openReportsSQL)
+	case "rejected":
+		query = query.Where("status = ?", model.ReviewStatusRejected)
+	default:
+		query = query.Where("status = ?", model.ReviewStatusPending)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Preload("User").
+		Preload("Reports", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Order("created_at ASC").Order("id ASC").
+		Limit(limit).Offset(offset).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+func (rr *reviewRepository) FindByUserAndBook(userId, bookId int) (*model.Review, error) {
+	var review model.Review
+
+	err := rr.db.Where("user_id = ? AND book_id = ?", userId, bookId).First(&review).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReviewNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (rr *reviewRepository) UpdateReview(review *model.Review) (*model.Review, error) {
+	err := rr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(review).Select("stars", "title", "body", "verified_purchase", "status", "reject_reason").Updates(review).Error
+		if err != nil {
+			return err
+		}
+
+		return refreshBookRating(tx, review.BookID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rr.FindById(review.ID)
+}
+
+// Moderate stores the moderation decision on the review, closes its open
+// reports and refreshes the book rating.
+func (rr *reviewRepository) Moderate(review *model.Review) (*model.Review, error) {
+	err := rr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(review).Select("status", "reject_reason", "moderated_by", "moderated_at").Updates(review).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.ReviewReport{}).
+			Where("review_id = ? AND resolved_at IS NULL", review.ID).
+			Update("resolved_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+
+		return refreshBookRating(tx, review.BookID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rr.FindById(review.ID)
+}
+
+func (rr *reviewRepository) DeleteReview(review *model.Review) error {
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Delete(&model.Review{}, review.ID).Error
+		if err != nil {
+			return err
+		}
+
+		return refreshBookRating(tx, review.BookID)
+	})
+}
+
+// SaveVote records the user's vote, replacing an earlier one on the same
+// review, and refreshes the review's vote counts.
+func (rr *reviewRepository) SaveVote(vote *model.ReviewVote) error {
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "review_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"helpful", "updated_at"}),
+		}).Create(vote).Error
+		if err != nil {
+			return err
+		}
+
+		return refreshVoteCounts(tx, vote.ReviewID)
+	})
+}
+
+func (rr *reviewRepository) DeleteVote(reviewId, userId int) error {
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("review_id = ? AND user_id = ?", reviewId, userId).Delete(&model.ReviewVote{}).Error
+		if err != nil {
+			return err
+		}
+
+		return refreshVoteCounts(tx, reviewId)
+	})
+}
+
+func (rr *reviewRepository) ReportExists(reviewId, userId int) (bool, error) {
+	var count int64
+
+	err := rr.db.Model(&model.ReviewReport{}).Where("review_id = ? AND user_id = ? AND resolved_at IS NULL", reviewId, userId).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (rr *reviewRepository) CreateReport(report *model.ReviewReport) error {
+	err := rr.db.Create(report).Error
+	if isUniqueViolation(rr.db, err) {
+		return ErrReportExists
+	}
+
+	return err
+}
+
+// RefreshAllBookRatings recomputes every book's rating from its reviews.
+func (rr *reviewRepository) RefreshAllBookRatings() error {
+	return rr.db.Exec(refreshBookRatingSQL).Error
+}
+
+func refreshBookRating(tx *gorm.DB, bookId int) error {
+	return tx.Exec(refreshBookRatingSQL+" WHERE books.id = ?", bookId).Error
+}
+
+func refreshVoteCounts(tx *gorm.DB, reviewId int) error {
+	return tx.Exec(`UPDATE reviews SET
+		helpful_count = (SELECT COUNT(*) FROM review_votes WHERE review_votes.review_id = reviews.id AND helpful),
+		unhelpful_count = (SELECT COUNT(*) FROM review_votes WHERE review_votes.review_id = reviews.id AND NOT helpful)
+		WHERE id = ?`, reviewId).Error
+}
+
+// isUniqueViolation reports whether err comes from a unique constraint,
+// using the driver's translation of database errors.
+func isUniqueViolation(db *gorm.DB, err error) bool {
+	translator, ok := db.Dialector.(gorm.ErrorTranslator)
+	return err != nil && ok && errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+}
+
+func NewReviewRepository(db *gorm.DB) *reviewRepository {
+	return &reviewRepository{db: db}
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShipmentRepository interface {
+	CreateShipment(shipment *model.Shipment) (*model.Shipment, error)
+	FindById(id int) (*model.Shipment, error)
+	FindByOrder(orderId int) ([]model.Shipment, error)
+	UpdateShipment(shipment *model.Shipment) (*model.Shipment, error)
+	AddEvent(id int, event *model.ShipmentEvent) (*model.Shipment, error)
+	FindQueue(paidAfter, paidBefore *time.Time, limit, offset int) ([]model.Order, int64, error)
+	ShippedQuantities(orderIds []int) (map[int]int, error)
+}
+
+var (
+	// ErrOrderNotPaid is returned when a shipment is created for an order
+	// that is not waiting to be fulfilled.
+	ErrOrderNotPaid = errors.New("order is not paid")
+	// ErrOverShipped is returned when a shipment would send more of an order
+	// item than is left to ship.
+	ErrOverShipped = errors.New("shipment exceeds the quantity left to ship")
+	// ErrShipmentDelivered is returned when an event is added to a shipment
+	// that has already been delivered.
+	ErrShipmentDelivered = errors.New("shipment has already been delivered")
+	// ErrShipmentStatus is returned when an event would move a shipment back
+	// to an earlier status.
+	ErrShipmentStatus = errors.New("shipment cannot move to this status")
+)
+
+type shipmentRepository struct {
+	db *gorm.DB
+}
+
+// CreateShipment saves the shipment with its items and first event. The
+// order is locked while the quantities left to ship are checked, so two
+// shipments created at once cannot both send the last copy.
+func (sr *shipmentRepository) CreateShipment(shipment *model.Shipment) (*model.Shipment, error) {
+	err := sr.db.Transaction(func(tx *gorm.DB) error {
+		var order model.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Items").
+			First(&order, shipment.OrderID).Error
+		if err != nil {
+			return err
+		}
+
+		if order.Status != model.OrderStatusPaid {
+			return ErrOrderNotPaid
+		}
+
+		shipped, err := shippedQuantities(tx, []int{order.ID})
+		if err != nil {
+			return err
+		}
+
+		ordered := make(map[int]int)
+		for _, item := range order.Items {
+			ordered[item.ID] = item.Qty
+		}
+
+		for _, item := range shipment.Items {
+			if shipped[item.OrderItemID]+item.Qty > ordered[item.OrderItemID] {
+				return ErrOverShipped
+			}
+		}
+
+		return tx.Create(shipment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sr.FindById(shipment.ID)
+}
+
+func (sr *shipmentRepository) FindById(id int) (*model.Shipment, error) {
+	var shipment model.Shipment
+
+	err := preloadShipment(sr.db).First(&shipment, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("shipment not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &shipment, nil
+}
+
+func (sr *shipmentRepository) FindByOrder(orderId int) ([]model.Shipment, error) {
+	var shipments []model.Shipment
+
+	err := preloadShipment(sr.db).
+		Where("order_id = ?", orderId).
+		Order("created_at ASC").Order("id ASC").
+		Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return shipments, nil
+}
+
+func (sr *shipmentRepository) UpdateShipment(shipment *model.Shipment) (*model.Shipment, error) {
+	err := sr.db.Model(shipment).
+		Select("carrier", "tracking_number").
+		Updates(shipment).Error
+	if err != nil {
+		return nil, errors.New("failed to update shipment")
+	}
+
+	return sr.FindById(shipment.ID)
+}
+
+// AddEvent records the event, moves the shipment to its status and then the
+// order along: shipped once every physical item has left the warehouse and
+// delivered once every one of them has arrived. The shipment is locked while
+// the move is checked, so two events recorded at once cannot take it back
+// to an earlier status.
+func (sr *shipmentRepository) AddEvent(id int, event *model.ShipmentEvent) (*model.Shipment, error) {
+	err := sr.db.Transaction(func(tx *gorm.DB) error {
+		var shipment model.Shipment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&shipment, id).Error
+		if err != nil {
+			return err
+		}
+
+		if shipment.Status == model.ShipmentStatusDelivered {
+			return ErrShipmentDelivered
+		}
+		if !shipment.CanMoveTo(event.Status) {
+			return ErrShipmentStatus
+		}
+
+		event.ShipmentID = shipment.ID
+		err = tx.Create(event).Error
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"status": event.Status}
+		if event.Status != model.ShipmentStatusPending && shipment.ShippedAt == nil {
+			updates["shipped_at"] = event.OccurredAt
+		}
+		if event.Status == model.ShipmentStatusDelivered {
+			updates["delivered_at"] = event.OccurredAt
+		}
+
+		err = tx.Model(&model.Shipment{}).Where("id = ?", shipment.ID).Updates(updates).Error
+		if err != nil {
+			return err
+		}
+
+		return syncOrderStatus(tx, shipment.OrderID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sr.FindById(id)
+}
+
+// FindQueue returns the paid orders, oldest payment first, that still have
+// physical items left to ship, optionally only those paid in the given
+// window.
+func (sr *shipmentRepository) FindQueue(paidAfter, paidBefore *time.Time, limit, offset int) ([]model.Order, int64, error) {
+	var orders []model.Order
+	var total int64
+
+	query := sr.db.Model(&model.Order{}).
+		Where("orders.status = ?", model.OrderStatusPaid).
+		Where(`EXISTS (
+			SELECT 1 FROM order_items
+			WHERE order_items.order_id = orders.id
+			AND order_items.format NOT IN ?
+			AND order_items.qty > (
+				SELECT COALESCE(SUM(shipment_items.qty), 0) FROM shipment_items
+				WHERE shipment_items.order_item_id = order_items.id
+			)
+		)`, []string{model.FormatEbook, model.FormatAudiobook})
+
+	if paidAfter != nil {
+		query = query.Where("orders.paid_at >= ?", *paidAfter)
+	}
+
+	if paidBefore != nil {
+		query = query.Where("orders.paid_at <= ?", *paidBefore)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = preloadOrder(query).
+		Order("orders.paid_at ASC").Order("orders.id ASC").
+		Limit(limit).Offset(offset).
+		Find(&orders).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// ShippedQuantities maps each order item of the given orders to how much of
+// it has been put in shipments.
+func (sr *shipmentRepository) ShippedQuantities(orderIds []int) (map[int]int, error) {
+	return shippedQuantities(sr.db, orderIds)
+}
+
+func shippedQuantities(db *gorm.DB, orderIds []int) (map[int]int, error) {
+	var rows []struct {
+		OrderItemID int
+		Qty         int
+	}
+
+	shipped := map[int]int{}
+	if len(orderIds) == 0 {
+		return shipped, nil
+	}
+
+	err := db.Model(&model.ShipmentItem{}).
+		Select("shipment_items.order_item_id, SUM(shipment_items.qty) AS qty").
+		Joins("JOIN shipments ON shipments.id = shipment_items.shipment_id").
+		Where("shipments.order_id IN ?", orderIds).
+		Group("shipment_items.order_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		shipped[row.OrderItemID] = row.Qty
+	}
+
+	return shipped, nil
+}
+
+// syncOrderStatus moves a paid or shipped order to shipped or delivered
+// once its shipments account for every physical item.
+func syncOrderStatus(tx *gorm.DB, orderId int) error {
+	var order model.Order
+	err := tx.Preload("Items").First(&order, orderId).Error
+	if err != nil {
+		return err
+	}
+
+	if order.Status != model.OrderStatusPaid && order.Status != model.OrderStatusShipped {
+		return nil
+	}
+
+	var shipments []model.Shipment
+	err = tx.Preload("Items").Where("order_id = ?", orderId).Find(&shipments).Error
+	if err != nil {
+		return err
+	}
+
+	sent := make(map[int]int)
+	delivered := make(map[int]int)
+	for _, shipment := range shipments {
+		for _, item := range shipment.Items {
+			if shipment.Status != model.ShipmentStatusPending {
+				sent[item.OrderItemID] += item.Qty
+			}
+			if shipment.Status == model.ShipmentStatusDelivered {
+				delivered[item.OrderItemID] += item.Qty
+			}
+		}
+	}
+
+	status := model.OrderStatusDelivered
+	for _, item := range order.Items {
+		if !item.NeedsShipping() {
+			continue
+		}
+		if sent[item.ID] < item.Qty {
+			return nil
+		}
+		if delivered[item.ID] < item.Qty {
+			status = model.OrderStatusShipped
+		}
+	}
+
+	if status == order.Status {
+		return nil
+	}
+
+	return tx.Model(&model.Order{}).Where("id = ?", orderId).Update("status", status).Error
+}
+
+func preloadShipment(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("occurred_at ASC").Order("id ASC") })
+}
+
+func NewShipmentRepository(db *gorm.DB) *shipmentRepository {
+	return &shipmentRepository{db: db}
+}
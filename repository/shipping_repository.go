@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShippingRepository interface {
+	CreateZone(zone *model.ShippingZone) (*model.ShippingZone, error)
+	FindAllZones() ([]model.ShippingZone, error)
+	FindZoneById(id int) (*model.ShippingZone, error)
+	ZoneNameExists(name string, excludeId int) (bool, error)
+	UpdateZone(zone *model.ShippingZone, replaceCountries bool) (*model.ShippingZone, error)
+	DeleteZone(id int) error
+	CountMethodsInZone(zoneId int) (int64, error)
+	CreateMethod(method *model.ShippingMethod) (*model.ShippingMethod, error)
+	FindAllMethods(activeOnly bool) ([]model.ShippingMethod, error)
+	FindMethodById(id int) (*model.ShippingMethod, error)
+	UpdateMethod(method *model.ShippingMethod, replaceRates bool) (*model.ShippingMethod, error)
+	DeleteMethod(id int) error
+}
+
+type shippingRepository struct {
+	db *gorm.DB
+}
+
+func (sr *shippingRepository) CreateZone(zone *model.ShippingZone) (*model.ShippingZone, error) {
+	err := sr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Create(zone).Error
+		if err != nil {
+			return err
+		}
+
+		return saveZoneCountries(tx, zone)
+	})
+	if err != nil {
+		return nil, errors.New("failed to create shipping zone")
+	}
+
+	return sr.FindZoneById(zone.ID)
+}
+
+func (sr *shippingRepository) FindAllZones() ([]model.ShippingZone, error) {
+	var zones []model.ShippingZone
+
+	err := sr.db.Preload("Countries", func(db *gorm.DB) *gorm.DB { return db.Order("country ASC") }).
+		Order("name ASC").
+		Find(&zones).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return zones, nil
+}
+
+func (sr *shippingRepository) FindZoneById(id int) (*model.ShippingZone, error) {
+	var zone model.ShippingZone
+
+	err := sr.db.Preload("Countries", func(db *gorm.DB) *gorm.DB { return db.Order("country ASC") }).
+		First(&zone, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("shipping zone not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &zone, nil
+}
+
+func (sr *shippingRepository) ZoneNameExists(name string, excludeId int) (bool, error) {
+	var count int64
+
+	err := sr.db.Model(&model.ShippingZone{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, excludeId).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// UpdateZone saves the zone's own columns and, when asked, replaces its
+// countries with the ones on the zone.
+func (sr *shippingRepository) UpdateZone(zone *model.ShippingZone, replaceCountries bool) (*model.ShippingZone, error) {
+	err := sr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(zone).Select("*").Omit("id", "created_at", clause.Associations).Updates(zone).Error
+		if err != nil {
+			return err
+		}
+
+		if !replaceCountries {
+			return nil
+		}
+
+		err = tx.Where("zone_id = ?", zone.ID).Delete(&model.ShippingZoneCountry{}).Error
+		if err != nil {
+			return err
+		}
+
+		return saveZoneCountries(tx, zone)
+	})
+	if err != nil {
+		return nil, errors.New("failed to update shipping zone")
+	}
+
+	return sr.FindZoneById(zone.ID)
+}
+
+func (sr *shippingRepository) DeleteZone(id int) error {
+	return sr.db.Delete(&model.ShippingZone{}, id).Error
+}
+
+func (sr *shippingRepository) CountMethodsInZone(zoneId int) (int64, error) {
+	var count int64
+
+	err := sr.db.Model(&model.ShippingMethod{}).Where("zone_id = ?", zoneId).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (sr *shippingRepository) CreateMethod(method *model.ShippingMethod) (*model.ShippingMethod, error) {
+	err := sr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Create(method).Error
+		if err != nil {
+			return err
+		}
+
+		return saveShippingRates(tx, method)
+	})
+	if err != nil {
+		return nil, errors.New("failed to create shipping method")
+	}
+
+	return sr.FindMethodById(method.ID)
+}
+
+// FindAllMethods returns the shipping methods together with their zone and
+// weight brackets, lightest first.
+func (sr *shippingRepository) FindAllMethods(activeOnly bool) ([]model.ShippingMethod, error) {
+	var methods []model.ShippingMethod
+
+	query := sr.preloadMethod().Order("name ASC").Order("id ASC")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	err := query.Find(&methods).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+func (sr *shippingRepository) FindMethodById(id int) (*model.ShippingMethod, error) {
+	var method model.ShippingMethod
+
+	err := sr.preloadMethod().First(&method, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("shipping method not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &method, nil
+}
+
+// UpdateMethod saves the method's own columns and, when asked, replaces its
+// weight brackets with the ones on the method.
+func (sr *shippingRepository) UpdateMethod(method *model.ShippingMethod, replaceRates bool) (*model.ShippingMethod, error) {
+	err := sr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(method).Select("*").Omit("id", "created_at", clause.Associations).Updates(method).Error
+		if err != nil {
+			return err
+		}
+
+		if !replaceRates {
+			return nil
+		}
+
+		err = tx.Where("method_id = ?", method.ID).Delete(&model.ShippingRate{}).Error
+		if err != nil {
+			return err
+		}
+
+		return saveShippingRates(tx, method)
+	})
+	if err != nil {
+		return nil, errors.New("failed to update shipping method")
+	}
+
+	return sr.FindMethodById(method.ID)
+}
+
+func (sr *shippingRepository) DeleteMethod(id int) error {
+	return sr.db.Delete(&model.ShippingMethod{}, id).Error
+}
+
+func (sr *shippingRepository) preloadMethod() *gorm.DB {
+	return sr.db.
+		Preload("Zone").
+		Preload("Zone.Countries", func(db *gorm.DB) *gorm.DB { return db.Order("country ASC") }).
+		Preload("Rates", func(db *gorm.DB) *gorm.DB { return db.Order("max_weight ASC") })
+}
+
+func saveZoneCountries(tx *gorm.DB, zone *model.ShippingZone) error {
+	if len(zone.Countries) == 0 {
+		return nil
+	}
+
+	for i := range zone.Countries {
+		zone.Countries[i].ZoneID = zone.ID
+	}
+
+	return tx.Create(&zone.Countries).Error
+}
+
+func saveShippingRates(tx *gorm.DB, method *model.ShippingMethod) error {
+	if len(method.Rates) == 0 {
+		return nil
+	}
+
+	for i := range method.Rates {
+		method.Rates[i].ID = 0
+		method.Rates[i].MethodID = method.ID
+	}
+
+	return tx.Create(&method.Rates).Error
+}
+
+func NewShippingRepository(db *gorm.DB) *shippingRepository {
+	return &shippingRepository{db: db}
+}
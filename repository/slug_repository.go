@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+)
+
+type SlugRepository interface {
+	FindRedirect(entityType, slug string) (*model.SlugRedirect, error)
+	CreateRedirect(entityType, slug string, entityId int) error
+	DeleteRedirect(entityType, slug string) error
+}
+
+type slugRepository struct {
+	db *gorm.DB
+}
+
+func (sr *slugRepository) FindRedirect(entityType, slug string) (*model.SlugRedirect, error) {
+	var redirect model.SlugRedirect
+
+	err := sr.db.Where("entity_type = ? AND slug = ?", entityType, slug).First(&redirect).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("slug not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &redirect, nil
+}
+
+func (sr *slugRepository) CreateRedirect(entityType, slug string, entityId int) error {
+	redirect := model.SlugRedirect{
+		EntityType: entityType,
+		Slug:       slug,
+		EntityID:   entityId,
+	}
+
+	err := sr.db.Where("entity_type = ? AND slug = ?", entityType, slug).
+		Assign(model.SlugRedirect{EntityID: entityId}).
+		FirstOrCreate(&redirect).Error
+	if err != nil {
+		return errors.New("failed to save slug redirect")
+	}
+
+	return nil
+}
+
+func (sr *slugRepository) DeleteRedirect(entityType, slug string) error {
+	err := sr.db.Where("entity_type = ? AND slug = ?", entityType, slug).Delete(&model.SlugRedirect{}).Error
+	if err != nil {
+		return errors.New("failed to delete slug redirect")
+	}
+
+	return nil
+}
+
+func NewSlugRepository(db *gorm.DB) *slugRepository {
+	return &slugRepository{db: db}
+}
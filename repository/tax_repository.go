@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+)
+
+type TaxRepository interface {
+	CreateRule(rule *model.TaxRule) (*model.TaxRule, error)
+	FindAll(country string) ([]model.TaxRule, error)
+	FindById(id int) (*model.TaxRule, error)
+	FindByCountry(country string) ([]model.TaxRule, error)
+	UpdateRule(rule *model.TaxRule) (*model.TaxRule, error)
+	DeleteRule(id int) error
+	ScopeExists(country, region, productClass string, excludeId int) (bool, error)
+}
+
+type taxRepository struct {
+	db *gorm.DB
+}
+
+func (tr *taxRepository) CreateRule(rule *model.TaxRule) (*model.TaxRule, error) {
+	err := tr.db.Create(rule).Error
+	if err != nil {
+		return nil, errors.New("failed to create tax rule")
+	}
+
+	return rule, nil
+}
+
+func (tr *taxRepository) FindAll(country string) ([]model.TaxRule, error) {
+	var rules []model.TaxRule
+
+	query := tr.db.Order("country ASC").Order("region ASC").Order("product_class ASC")
+	if country != "" {
+		query = query.Where("country = ?", country)
+	}
+
+	err := query.Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (tr *taxRepository) FindById(id int) (*model.TaxRule, error) {
+	var rule model.TaxRule
+
+	err := tr.db.First(&rule, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("tax rule not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (tr *taxRepository) FindByCountry(country string) ([]model.TaxRule, error) {
+	var rules []model.TaxRule
+
+	err := tr.db.Where("country = ?", country).Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (tr *taxRepository) UpdateRule(rule *model.TaxRule) (*model.TaxRule, error) {
+	err := tr.db.Save(rule).Error
+	if err != nil {
+		return nil, errors.New("failed to update tax rule")
+	}
+
+	return rule, nil
+}
+
+func (tr *taxRepository) DeleteRule(id int) error {
+	return tr.db.Delete(&model.TaxRule{}, id).Error
+}
+
+func (tr *taxRepository) ScopeExists(country, region, productClass string, excludeId int) (bool, error) {
+	var count int64
+
+	err := tr.db.Model(&model.TaxRule{}).
+		Where("country = ? AND region = ? AND product_class = ? AND id <> ?", country, region, productClass, excludeId).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func NewTaxRepository(db *gorm.DB) *taxRepository {
+	return &taxRepository{db: db}
+}
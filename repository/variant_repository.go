@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+)
+
+type VariantRepository interface {
+	CreateVariant(variant *model.BookVariant) (*model.BookVariant, error)
+	FindByBook(bookId int) ([]model.BookVariant, error)
+	FindById(id int) (*model.BookVariant, error)
+	SkuExists(sku string, excludeId int) (bool, error)
+	UpdateVariant(id int, updateVariant *model.BookVariant) (*model.BookVariant, error)
+	ReplacePrices(id int, prices []model.VariantPrice) error
+	DeleteVariant(id int) error
+	FindBooksWithoutVariants() ([]model.Book, error)
+	FindLegacyStock(bookIds []int) (map[int]int, error)
+}
+
+// ErrVariantNotFound is returned when no variant has the id.
+var ErrVariantNotFound = errors.New("book variant not found")
+
+type variantRepository struct {
+	db *gorm.DB
+}
+
+func (vr *variantRepository) CreateVariant(variant *model.BookVariant) (*model.BookVariant, error) {
+	err := vr.db.Create(variant).Error
+	if err != nil {
+		return nil, errors.New("failed to create book variant")
+	}
+
+	return variant, nil
+}
+
+func (vr *variantRepository) FindByBook(bookId int) ([]model.BookVariant, error) {
+	var variants []model.BookVariant
+
+	err := vr.db.Preload("Prices").Where("book_id = ?", bookId).Order("id ASC").Find(&variants).Error
+	if err != nil {
+		return nil, errors.New("failed to find book variants")
+	}
+
+	return variants, nil
+}
+
+func (vr *variantRepository) FindById(id int) (*model.BookVariant, error) {
+	var variant model.BookVariant
+
+	err := vr.db.Preload("Prices").First(&variant, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVariantNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+func (vr *variantRepository) SkuExists(sku string, excludeId int) (bool, error) {
+	var count int64
+
+	err := vr.db.Model(&model.BookVariant{}).Where("sku = ? AND id <> ?", sku, excludeId).Count(&count).Error
+	if err != nil {
+		return false, errors.New("failed to check sku")
+	}
+
+	return count > 0, nil
+}
+
+func (vr *variantRepository) UpdateVariant(id int, updateVariant *model.BookVariant) (*model.BookVariant, error) {
+	var variant model.BookVariant
+
+	err := vr.db.First(&variant, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("book variant not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = vr.db.Model(&variant).Select("sku", "format", "edition", "price_amount", "price_currency", "stock", "page_count", "weight").Updates(updateVariant).Error
+	if err != nil {
+		return nil, errors.New("failed to update book variant")
+	}
+
+	return &variant, nil
+}
+
+func (vr *variantRepository) ReplacePrices(id int, prices []model.VariantPrice) error {
+	return vr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("variant_id = ?", id).Delete(&model.VariantPrice{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(prices) == 0 {
+			return nil
+		}
+
+		for i := range prices {
+			prices[i].VariantID = id
+		}
+
+		return tx.Create(&prices).Error
+	})
+}
+
+func (vr *variantRepository) DeleteVariant(id int) error {
+	err := vr.db.Delete(&model.BookVariant{}, id).Error
+	if err != nil {
+		return errors.New("failed to delete book variant")
+	}
+
+	return nil
+}
+
+func (vr *variantRepository) FindBooksWithoutVariants() ([]model.Book, error) {
+	var books []model.Book
+
+	err := vr.db.
+		Where("NOT EXISTS (SELECT 1 FROM book_variants WHERE book_variants.book_id = books.id)").
+		Find(&books).Error
+	if err != nil {
+		return nil, errors.New("failed to find books without variants")
+	}
+
+	return books, nil
+}
+
+// FindLegacyStock reads the stock books carried in the books table before
+// variants existed, keyed by book id. It is empty when the table has no
+// stock column.
+func (vr *variantRepository) FindLegacyStock(bookIds []int) (map[int]int, error) {
+	stock := make(map[int]int)
+	if len(bookIds) == 0 || !vr.db.Migrator().HasColumn(&model.Book{}, "stock") {
+		return stock, nil
+	}
+
+	var rows []struct {
+		Id    int
+		Stock *int
+	}
+
+	err := vr.db.Table("books").Select("id, stock").Where("id IN ?", bookIds).Scan(&rows).Error
+	if err != nil {
+		return nil, errors.New("failed to find legacy book stock")
+	}
+
+	for _, row := range rows {
+		if row.Stock != nil {
+			stock[row.Id] = *row.Stock
+		}
+	}
+
+	return stock, nil
+}
+
+func NewVariantRepository(db *gorm.DB) *variantRepository {
+	return &variantRepository{db: db}
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistRepository interface {
+	FindByUser(userId int) ([]model.WishlistItem, error)
+	FindItem(userId, bookId int) (*model.WishlistItem, error)
+	SaveItem(item *model.WishlistItem) error
+	DeleteItem(userId, bookId int) error
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func (wr *wishlistRepository) FindByUser(userId int) ([]model.WishlistItem, error) {
+	var items []model.WishlistItem
+
+	err := wr.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (wr *wishlistRepository) FindItem(userId, bookId int) (*model.WishlistItem, error) {
+	var item model.WishlistItem
+
+	err := wr.db.Where("user_id = ? AND book_id = ?", userId, bookId).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("wishlist item not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// SaveItem adds the book to the wishlist, or updates the chosen variant when
+// it is already there.
+func (wr *wishlistRepository) SaveItem(item *model.WishlistItem) error {
+	return wr.db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "book_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"variant_id"}),
+	}).Create(item).Error
+}
+
+func (wr *wishlistRepository) DeleteItem(userId, bookId int) error {
+	return wr.db.Where("user_id = ? AND book_id = ?", userId, bookId).Delete(&model.WishlistItem{}).Error
+}
+
+func NewWishlistRepository(db *gorm.DB) *wishlistRepository {
+	return &wishlistRepository{db: db}
+}
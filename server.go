@@ -2,10 +2,14 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mhmmmdrivaldhi/go-book-api/config"
 	"github.com/mhmmmdrivaldhi/go-book-api/controller"
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
 	"github.com/mhmmmdrivaldhi/go-book-api/middleware"
 	"github.com/mhmmmdrivaldhi/go-book-api/model"
 	"github.com/mhmmmdrivaldhi/go-book-api/repository"
@@ -19,40 +23,105 @@ type Server struct {
 	bookUsecase usecase.BookUsecase
 	userUsecase usecase.UserUsecase
 	categoryUsecase usecase.CategoryUsecase
+	authorUsecase usecase.AuthorUsecase
+	publisherUsecase usecase.PublisherUsecase
+	variantUsecase usecase.VariantUsecase
+	importUsecase usecase.ImportUsecase
+	reviewUsecase usecase.ReviewUsecase
+	cartUsecase usecase.CartUsecase
+	promotionUsecase usecase.PromotionUsecase
+	orderUsecase usecase.OrderUsecase
+	wishlistUsecase usecase.WishlistUsecase
+	readingListUsecase usecase.ReadingListUsecase
+	currencyUsecase usecase.CurrencyUsecase
+	taxUsecase usecase.TaxUsecase
+	addressUsecase usecase.AddressUsecase
+	shippingUsecase usecase.ShippingUsecase
+	shipmentUsecase usecase.ShipmentUsecase
+	returnUsecase usecase.ReturnUsecase
+	refundUsecase usecase.RefundUsecase
+	invoiceUsecase usecase.InvoiceUsecase
 	authUsecase usecase.AuthUsecase
 	jwtService  service.JwtService
+	guestCartTTL time.Duration
+	cartCookieSecure bool
 	engine *gin.Engine
 	host string
 }
 
 func (s *Server) InitRoute() {
 	auth := s.engine.Group("/api/auth")
-	controller.NewAuthController(s.authUsecase, auth)
+	controller.NewAuthController(s.authUsecase, auth, s.cartCookieSecure)
 
 	v1 := s.engine.Group("/api/v1")
+	v1.Use(middleware.CurrencyMiddleware(s.currencyUsecase.Resolve))
 
 	authMiddleware := middleware.NewAuthMiddleware(s.jwtService)
 
 	// public routes
 	controller.NewUserController(s.userUsecase, v1).Route()
 
+	// routes open to guests, which know the user when a token is sent
+	guestGroup := v1.Group("")
+	guestGroup.Use(authMiddleware.OptionalToken())
+
+	controller.NewCartController(s.cartUsecase, guestGroup, s.guestCartTTL, s.cartCookieSecure)
+
 	// routes with authentication & authorization
 	authGroup := v1.Group("")
 	authGroup.Use(authMiddleware.RequireToken())
 
 	controller.NewBookController(s.bookUsecase, authGroup)
 	controller.NewCategoryController(s.categoryUsecase, authGroup)
+	controller.NewAuthorController(s.authorUsecase, authGroup)
+	controller.NewPublisherController(s.publisherUsecase, authGroup)
+	controller.NewVariantController(s.variantUsecase, authGroup)
+	controller.NewImportController(s.importUsecase, authGroup)
+	controller.NewReviewController(s.reviewUsecase, authGroup)
+	controller.NewCouponController(s.promotionUsecase, authGroup)
+	controller.NewPromotionController(s.promotionUsecase, authGroup)
+	controller.NewOrderController(s.orderUsecase, authGroup)
+	controller.NewWishlistController(s.wishlistUsecase, authGroup)
+	controller.NewReadingListController(s.readingListUsecase, authGroup, v1)
+	controller.NewCurrencyController(s.currencyUsecase, authGroup)
+	controller.NewTaxController(s.taxUsecase, authGroup)
+	controller.NewAddressController(s.addressUsecase, authGroup)
+	controller.NewShippingController(s.shippingUsecase, authGroup)
+	controller.NewShipmentController(s.shipmentUsecase, authGroup)
+	controller.NewReturnController(s.returnUsecase, authGroup)
+	controller.NewRefundController(s.refundUsecase, authGroup)
+	controller.NewInvoiceController(s.invoiceUsecase, authGroup)
 }
 
+// expireOrdersEvery is how often orders left unpaid past their TTL are
+// looked for.
+const expireOrdersEvery = time.Minute
+
 func (s *Server) Run() {
 	s.InitRoute()
 
+	go s.expirePendingOrders()
+
 	err :=  s.engine.Run(s.host)
 	if err != nil {
 		panic(fmt.Errorf("server not running on host %s, because error %v", s.host, err.Error()))
 	}
 }
 
+// expirePendingOrders cancels unpaid orders in the background for as long as
+// the server runs.
+func (s *Server) expirePendingOrders() {
+	ticker := time.NewTicker(expireOrdersEvery)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := s.orderUsecase.ExpirePending()
+		if err != nil {
+			log.Printf("failed to expire pending orders: %v\n", err)
+		}
+	}
+}
+
 func NewServer() *Server {
 	cfg, _ := config.NewConfig()
 
@@ -65,34 +134,202 @@ func NewServer() *Server {
 		fmt.Printf("successfully connect to database %s\n", cfg.Database)
 	}
 
+	err = repository.MigrateMoneyColumns(db)
+	if err != nil {
+		panic(fmt.Errorf("failed to migrate price columns: %v", err))
+	}
+
 	db.AutoMigrate(
 		&model.User{},
 		&model.Book{},
 		&model.Category{},
+		&model.SlugRedirect{},
+		&model.Author{},
+		&model.Publisher{},
+		&model.BookAuthor{},
+		&model.BookVariant{},
+		&model.VariantPrice{},
+		&model.ExchangeRate{},
+		&model.ImportJob{},
+		&model.ImportRowError{},
+		&model.Order{},
+		&model.OrderItem{},
+		&model.OrderDiscount{},
+		&model.OrderTax{},
+		&model.TaxRule{},
+		&model.Address{},
+		&model.ShippingZone{},
+		&model.ShippingZoneCountry{},
+		&model.ShippingMethod{},
+		&model.ShippingRate{},
+		&model.Shipment{},
+		&model.ShipmentItem{},
+		&model.ShipmentEvent{},
+		&model.ReturnRequest{},
+		&model.ReturnPhoto{},
+		&model.Refund{},
+		&model.Invoice{},
+		&model.InvoiceLine{},
+		&model.InvoiceSequence{},
+		&model.Coupon{},
+		&model.CouponCategory{},
+		&model.CouponBook{},
+		&model.CouponRedemption{},
+		&model.Promotion{},
+		&model.PromotionCategory{},
+		&model.PromotionBook{},
+		&model.Review{},
+		&model.ReviewVote{},
+		&model.ReviewReport{},
+		&model.WishlistItem{},
+		&model.ReadingList{},
+		&model.ReadingListItem{},
 	)
 
 	jwtService := service.NewJwtService(cfg.ApiConfig)
 
-	bookRepository := repository.NewBookRepository(db)
-	bookUsecase := usecase.NewBookUsecase(bookRepository)
+	storageService, err := service.NewStorageService(cfg.StorageConfig)
+	if err != nil {
+		panic(fmt.Errorf("failed to configure storage: %v", err))
+	}
+
+	refundService, err := service.NewRefundService(cfg.PaymentConfig)
+	if err != nil {
+		panic(fmt.Errorf("failed to configure refunds: %v", err))
+	}
+
+	invoiceStore := service.NewInvoiceStore(cfg.InvoiceConfig)
+	returnPhotoStore := service.NewReturnPhotoStore(cfg.ReturnConfig)
+
+	slugRepository := repository.NewSlugRepository(db)
 
 	categoryRepository := repository.NewCategoryRepository(db)
-	categoryUsecase := usecase.NewCategoryUsecase(categoryRepository)
+	helper.RegisterValidations()
+
+	authorRepository := repository.NewAuthorRepository(db)
+	publisherRepository := repository.NewPublisherRepository(db)
+	publisherUsecase := usecase.NewPublisherUsecase(publisherRepository)
+
+	variantRepository := repository.NewVariantRepository(db)
+
+	currencyRepository := repository.NewCurrencyRepository(db)
+	currencyUsecase := usecase.NewCurrencyUsecase(currencyRepository, cfg.BaseCurrency)
+
+	bookRepository := repository.NewBookRepository(db)
+	bookUsecase := usecase.NewBookUsecase(bookRepository, categoryRepository, slugRepository, authorRepository, publisherRepository, variantRepository, storageService, currencyUsecase)
+	variantUsecase := usecase.NewVariantUsecase(variantRepository, bookRepository, currencyUsecase, cfg.BackfillStock)
+	authorUsecase := usecase.NewAuthorUsecase(authorRepository, bookUsecase)
+
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepository, slugRepository, bookUsecase)
+
+	importRepository := repository.NewImportRepository(db)
+	importUsecase := usecase.NewImportUsecase(importRepository, bookUsecase)
 
+	orderRepository := repository.NewOrderRepository(db)
+	reviewRepository := repository.NewReviewRepository(db)
+	reviewUsecase := usecase.NewReviewUsecase(reviewRepository, bookRepository, orderRepository)
+
+	redisClient := config.NewRedisClient()
+	cartRepository := repository.NewCartRepository(redisClient, cfg.CartTTL, cfg.GuestCartTTL)
+	couponRepository := repository.NewCouponRepository(db)
+	promotionRepository := repository.NewPromotionRepository(db)
+	taxRepository := repository.NewTaxRepository(db)
+	taxUsecase := usecase.NewTaxUsecase(taxRepository, cfg.TaxCountry)
+	addressRepository := repository.NewAddressRepository(db)
+	addressUsecase := usecase.NewAddressUsecase(addressRepository)
+	shippingRepository := repository.NewShippingRepository(db)
+	shippingUsecase := usecase.NewShippingUsecase(shippingRepository)
+	promotionUsecase := usecase.NewPromotionUsecase(couponRepository, promotionRepository, categoryRepository, bookRepository)
+	pricingUsecase := usecase.NewPricingUsecase(variantRepository, currencyUsecase, promotionUsecase, taxUsecase, shippingUsecase)
+	cartUsecase := usecase.NewCartUsecase(cartRepository, variantUsecase, pricingUsecase, addressUsecase, shippingUsecase, currencyUsecase)
 	userRepository := repository.NewUserRepository(db)
+	invoiceRepository := repository.NewInvoiceRepository(db)
+	invoiceUsecase := usecase.NewInvoiceUsecase(invoiceRepository, orderRepository, userRepository, bookRepository, invoiceStore, cfg.SellerName, cfg.SellerAddress, cfg.SellerTaxID)
+	orderUsecase := usecase.NewOrderUsecase(orderRepository, cartRepository, cartUsecase, pricingUsecase, addressUsecase, invoiceUsecase, cfg.PendingOrderTTL)
+	shipmentRepository := repository.NewShipmentRepository(db)
+	shipmentUsecase := usecase.NewShipmentUsecase(shipmentRepository, orderRepository)
+	refundRepository := repository.NewRefundRepository(db)
+	refundUsecase := usecase.NewRefundUsecase(refundRepository, orderRepository, refundService, invoiceUsecase)
+	returnRepository := repository.NewReturnRepository(db)
+	returnUsecase := usecase.NewReturnUsecase(returnRepository, orderRepository, refundUsecase, returnPhotoStore)
+
+	wishlistRepository := repository.NewWishlistRepository(db)
+	wishlistUsecase := usecase.NewWishlistUsecase(wishlistRepository, bookUsecase, variantUsecase, cartUsecase)
+
+	readingListRepository := repository.NewReadingListRepository(db)
+	readingListUsecase := usecase.NewReadingListUsecase(readingListRepository, bookUsecase)
+
+	err = currencyUsecase.BackfillCurrencies()
+	if err != nil {
+		panic(fmt.Errorf("failed to backfill price currencies: %v", err))
+	}
+
+	err = categoryUsecase.BackfillSlugs()
+	if err != nil {
+		log.Printf("failed to backfill category slugs: %v\n", err)
+	}
+
+	err = bookUsecase.BackfillSlugs()
+	if err != nil {
+		log.Printf("failed to backfill book slugs: %v\n", err)
+	}
+
+	err = bookUsecase.MigrateAuthors()
+	if err != nil {
+		panic(fmt.Errorf("failed to migrate book authors: %v", err))
+	}
+
+	err = variantUsecase.BackfillDefaultVariants()
+	if err != nil {
+		panic(fmt.Errorf("failed to backfill book variants: %v", err))
+	}
+
+	err = importUsecase.FailUnfinished()
+	if err != nil {
+		panic(fmt.Errorf("failed to close interrupted imports: %v", err))
+	}
+
+	err = reviewUsecase.RefreshRatings()
+	if err != nil {
+		panic(fmt.Errorf("failed to refresh book ratings: %v", err))
+	}
+
 	userUsecase := usecase.NewUserUsecase(userRepository)
 
-	authUsecase := usecase.NewAuthUsecase(userRepository, jwtService)
+	authUsecase := usecase.NewAuthUsecase(userRepository, jwtService, cartUsecase)
 
 
 	engine := gin.Default()
+	if cfg.StorageDriver == "local" && strings.HasPrefix(cfg.StoragePublicURL, "/") {
+		engine.Static(cfg.StoragePublicURL, cfg.StorageLocalDir)
+	}
 	host := fmt.Sprintf(":%s", cfg.AppPort)
 	return &Server{
 		bookUsecase: bookUsecase,
 		categoryUsecase: categoryUsecase,
+		authorUsecase: authorUsecase,
+		publisherUsecase: publisherUsecase,
+		variantUsecase: variantUsecase,
+		importUsecase: importUsecase,
+		reviewUsecase: reviewUsecase,
+		cartUsecase: cartUsecase,
+		promotionUsecase: promotionUsecase,
+		orderUsecase: orderUsecase,
+		wishlistUsecase: wishlistUsecase,
+		readingListUsecase: readingListUsecase,
+		currencyUsecase: currencyUsecase,
+		taxUsecase: taxUsecase,
+		addressUsecase: addressUsecase,
+		shippingUsecase: shippingUsecase,
+		shipmentUsecase: shipmentUsecase,
+		returnUsecase: returnUsecase,
+		refundUsecase: refundUsecase,
+		invoiceUsecase: invoiceUsecase,
 		userUsecase: userUsecase,
 		authUsecase: authUsecase,
 		jwtService: jwtService,
+		guestCartTTL: cfg.GuestCartTTL,
+		cartCookieSecure: cfg.CartCookieSecure,
 		engine: engine,
 		host: host,
 	}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"os"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/config"
+)
+
+// InvoiceStore keeps the rendered invoice PDFs on local disk. Unlike uploads
+// they are never served publicly, only through the owner's order.
+type InvoiceStore interface {
+	Save(key string, data []byte) error
+	Load(key string) ([]byte, error)
+}
+
+type diskInvoiceStore struct {
+	storage *localStorage
+}
+
+func (ds *diskInvoiceStore) Save(key string, data []byte) error {
+	return ds.storage.Put(key, "application/pdf", data)
+}
+
+// Load reads a stored PDF. The error wraps os.ErrNotExist when there is none.
+func (ds *diskInvoiceStore) Load(key string) ([]byte, error) {
+	path, err := ds.storage.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path)
+}
+
+func NewInvoiceStore(cfg config.InvoiceConfig) InvoiceStore {
+	return &diskInvoiceStore{storage: &localStorage{dir: cfg.InvoiceDir}}
+}
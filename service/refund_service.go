@@ -0,0 +1,47 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/config"
+)
+
+// RefundService pays money back to the customer through a payment provider.
+// Refund returns the provider's reference for the refund.
+type RefundService interface {
+	Name() string
+	Refund(orderId int, amount int64, currency, reason string) (string, error)
+}
+
+// fakeRefund stands in for a real provider during development: every refund
+// succeeds at once and nothing leaves the building.
+type fakeRefund struct{}
+
+func (fr *fakeRefund) Name() string {
+	return "fake"
+}
+
+func (fr *fakeRefund) Refund(orderId int, amount int64, currency, reason string) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("invalid refund amount %d", amount)
+	}
+
+	buf := make([]byte, 8)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return "fake_re_" + hex.EncodeToString(buf), nil
+}
+
+func NewRefundService(cfg config.PaymentConfig) (RefundService, error) {
+	switch cfg.RefundProvider {
+	case "fake":
+		return &fakeRefund{}, nil
+	default:
+		return nil, fmt.Errorf("unknown refund provider %q", cfg.RefundProvider)
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"os"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/config"
+)
+
+// ReturnPhotoStore keeps the pictures attached to returns on local disk. Like
+// invoices they are never served publicly, only to the owner of the return
+// and to admins.
+type ReturnPhotoStore interface {
+	Save(key, contentType string, data []byte) error
+	Load(key string) ([]byte, error)
+}
+
+type diskReturnPhotoStore struct {
+	storage *localStorage
+}
+
+func (ds *diskReturnPhotoStore) Save(key, contentType string, data []byte) error {
+	return ds.storage.Put(key, contentType, data)
+}
+
+// Load reads a stored photo. The error wraps os.ErrNotExist when there is none.
+func (ds *diskReturnPhotoStore) Load(key string) ([]byte, error) {
+	path, err := ds.storage.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path)
+}
+
+func NewReturnPhotoStore(cfg config.ReturnConfig) ReturnPhotoStore {
+	return &diskReturnPhotoStore{storage: &localStorage{dir: cfg.ReturnPhotoDir}}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/config"
+)
+
+// StorageService stores uploaded files under a slash separated key and
+// tells where they can be downloaded from.
+type StorageService interface {
+	Put(key, contentType string, data []byte) error
+	Delete(key string) error
+	URL(key string) string
+}
+
+type localStorage struct {
+	dir       string
+	publicURL string
+}
+
+func (ls *localStorage) Put(key, contentType string, data []byte) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (ls *localStorage) Delete(key string) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (ls *localStorage) URL(key string) string {
+	return ls.publicURL + "/" + key
+}
+
+// path maps a key inside the storage directory and refuses keys that would
+// escape it.
+func (ls *localStorage) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+
+	return filepath.Join(ls.dir, filepath.FromSlash(key)), nil
+}
+
+// s3Storage talks to any S3 compatible object store (AWS S3, MinIO, R2, ...)
+// using path-style requests signed with AWS Signature Version 4. Objects are
+// expected to be publicly readable through a bucket policy.
+type s3Storage struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+}
+
+func (ss *s3Storage) Put(key, contentType string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPut, ss.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	return ss.do(req, data)
+}
+
+func (ss *s3Storage) Delete(key string) error {
+	req, err := http.NewRequest(http.MethodDelete, ss.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+
+	return ss.do(req, nil)
+}
+
+func (ss *s3Storage) URL(key string) string {
+	return ss.publicURL + "/" + key
+}
+
+func (ss *s3Storage) objectURL(key string) string {
+	return ss.endpoint + "/" + ss.bucket + "/" + escapeKey(key)
+}
+
+func (ss *s3Storage) do(req *http.Request, payload []byte) error {
+	ss.sign(req, payload, time.Now().UTC())
+
+	res, err := ss.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("object storage responded %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// sign adds the AWS Signature Version 4 authorization headers to req.
+func (ss *s3Storage) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	headerValues := []string{req.URL.Host, payloadHash, amzDate}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		signedHeaders = []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+		headerValues = append([]string{contentType}, headerValues...)
+	}
+
+	var canonicalHeaders strings.Builder
+	for i, name := range signedHeaders {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headerValues[i]) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + ss.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+ss.secretKey), date)
+	signingKey = hmacSHA256(signingKey, ss.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		ss.accessKey, scope, strings.Join(signedHeaders, ";"), signature,
+	))
+}
+
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func NewStorageService(cfg config.StorageConfig) (StorageService, error) {
+	switch cfg.StorageDriver {
+	case "local":
+		return &localStorage{
+			dir:       cfg.StorageLocalDir,
+			publicURL: strings.TrimSuffix(cfg.StoragePublicURL, "/"),
+		}, nil
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+			return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required for s3 storage")
+		}
+
+		region := cfg.S3Region
+		if region == "" {
+			region = "us-east-1"
+		}
+
+		endpoint := strings.TrimSuffix(cfg.S3Endpoint, "/")
+		publicURL := cfg.StoragePublicURL
+		if publicURL == "" {
+			publicURL = endpoint + "/" + cfg.S3Bucket
+		}
+
+		return &s3Storage{
+			endpoint:  endpoint,
+			region:    region,
+			bucket:    cfg.S3Bucket,
+			accessKey: cfg.S3AccessKey,
+			secretKey: cfg.S3SecretKey,
+			publicURL: strings.TrimSuffix(publicURL, "/"),
+			client:    &http.Client{Timeout: 30 * time.Second},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
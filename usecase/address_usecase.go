@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type AddressUsecase interface {
+	Create(userId int, req dto.CreateAddressRequest) (*model.Address, error)
+	GetByUser(userId int) ([]model.Address, error)
+	GetById(id, userId int) (*model.Address, error)
+	GetDefault(userId int) (*model.Address, error)
+	Update(id, userId int, req dto.UpdateAddressRequest) (*model.Address, error)
+	Delete(id, userId int) error
+	SetDefault(id, userId int) (*model.Address, error)
+}
+
+var ErrAddressNotFound = errors.New("address not found")
+
+type addressUsecase struct {
+	addressRepo repository.AddressRepository
+}
+
+func (au *addressUsecase) Create(userId int, req dto.CreateAddressRequest) (*model.Address, error) {
+	return au.addressRepo.CreateAddress(&model.Address{
+		UserID: userId,
+		Label:  strings.TrimSpace(req.Label),
+		PostalAddress: model.PostalAddress{
+			RecipientName: strings.TrimSpace(req.RecipientName),
+			Phone:         strings.TrimSpace(req.Phone),
+			Line1:         strings.TrimSpace(req.Line1),
+			Line2:         strings.TrimSpace(req.Line2),
+			City:          strings.TrimSpace(req.City),
+			Region:        normalizeRegion(req.Region),
+			PostalCode:    strings.TrimSpace(req.PostalCode),
+			Country:       normalizeCountry(req.Country),
+		},
+		IsDefault: req.IsDefault,
+	})
+}
+
+func (au *addressUsecase) GetByUser(userId int) ([]model.Address, error) {
+	addresses, err := au.addressRepo.FindByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if addresses == nil {
+		addresses = []model.Address{}
+	}
+
+	return addresses, nil
+}
+
+func (au *addressUsecase) GetById(id, userId int) (*model.Address, error) {
+	return au.findOwned(id, userId)
+}
+
+// GetDefault returns the user's default address, reporting ErrAddressNotFound
+// when the address book is empty.
+func (au *addressUsecase) GetDefault(userId int) (*model.Address, error) {
+	address, err := au.addressRepo.FindDefault(userId)
+	if err != nil {
+		return nil, ErrAddressNotFound
+	}
+
+	return address, nil
+}
+
+func (au *addressUsecase) Update(id, userId int, req dto.UpdateAddressRequest) (*model.Address, error) {
+	address, err := au.findOwned(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Label != nil {
+		address.Label = strings.TrimSpace(*req.Label)
+	}
+
+	if req.RecipientName != nil {
+		address.RecipientName = strings.TrimSpace(*req.RecipientName)
+	}
+
+	if req.Phone != nil {
+		address.Phone = strings.TrimSpace(*req.Phone)
+	}
+
+	if req.Line1 != nil {
+		address.Line1 = strings.TrimSpace(*req.Line1)
+	}
+
+	if req.Line2 != nil {
+		address.Line2 = strings.TrimSpace(*req.Line2)
+	}
+
+	if req.City != nil {
+		address.City = strings.TrimSpace(*req.City)
+	}
+
+	if req.Region != nil {
+		address.Region = normalizeRegion(*req.Region)
+	}
+
+	if req.PostalCode != nil {
+		address.PostalCode = strings.TrimSpace(*req.PostalCode)
+	}
+
+	if req.Country != nil {
+		address.Country = normalizeCountry(*req.Country)
+	}
+
+	return au.addressRepo.UpdateAddress(address)
+}
+
+func (au *addressUsecase) Delete(id, userId int) error {
+	address, err := au.findOwned(id, userId)
+	if err != nil {
+		return err
+	}
+
+	return au.addressRepo.DeleteAddress(address)
+}
+
+func (au *addressUsecase) SetDefault(id, userId int) (*model.Address, error) {
+	address, err := au.findOwned(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = au.addressRepo.SetDefault(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// findOwned loads an address of the user. Addresses of other users are
+// reported as not found.
+func (au *addressUsecase) findOwned(id, userId int) (*model.Address, error) {
+	address, err := au.addressRepo.FindById(id)
+	if err != nil || address.UserID != userId {
+		return nil, ErrAddressNotFound
+	}
+
+	return address, nil
+}
+
+func NewAddressUsecase(addressRepo repository.AddressRepository) *addressUsecase {
+	return &addressUsecase{addressRepo: addressRepo}
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 
 	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
@@ -10,23 +11,30 @@ import (
 )
 
 type AuthUsecase interface {
-	Login(req dto.LoginRequest) (*dto.LoginResponse, error)
+	Login(ctx context.Context, req dto.LoginRequest, cartToken string) (*dto.LoginResponse, error)
 }
 
+// ErrInvalidCredentials is returned when no user has the email and password.
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
 type authUsecase struct {
 	userRepo repository.UserRepository
 	jwtService service.JwtService
+	cartUsecase CartUsecase
 }
 
-func (au *authUsecase) Login(req dto.LoginRequest) (*dto.LoginResponse, error) {
+// Login signs the user in. The guest cart of cartToken, if any, is merged
+// into the user's cart; a merge that fails fails the login, so the
+// guest cart and its token are kept for the next attempt.
+func (au *authUsecase) Login(ctx context.Context, req dto.LoginRequest, cartToken string) (*dto.LoginResponse, error) {
 	user, err := au.userRepo.FindByEmail(req.Email)
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, ErrInvalidCredentials
 	}
 
 	 err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, ErrInvalidCredentials
 	}
 	
 	token, err := au.jwtService.GenerateToken(user.ID, user.Email, user.Role)
@@ -34,6 +42,13 @@ func (au *authUsecase) Login(req dto.LoginRequest) (*dto.LoginResponse, error) {
 		return nil, errors.New("failed to generate token")
 	}
 
+	if cartToken != "" {
+		err = au.cartUsecase.MergeGuestCart(ctx, user.ID, cartToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &dto.LoginResponse{
 		Token: token,
 		User: dto.UserResponse{
@@ -45,9 +60,10 @@ func (au *authUsecase) Login(req dto.LoginRequest) (*dto.LoginResponse, error) {
 	}, nil
 }
 
-func NewAuthUsecase(userRepo repository.UserRepository, jwtService service.JwtService) *authUsecase {
+func NewAuthUsecase(userRepo repository.UserRepository, jwtService service.JwtService, cartUsecase CartUsecase) *authUsecase {
 	return &authUsecase{
 		userRepo: userRepo,
 		jwtService: jwtService,
+		cartUsecase: cartUsecase,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type AuthorUsecase interface {
+	Create(req dto.CreateAuthorRequest) (*model.Author, error)
+	GetAll() ([]dto.AuthorResponse, error)
+	GetById(id int) (*dto.AuthorResponse, error)
+	GetBooks(id int, filter dto.BookFilterRequest) ([]dto.BookResponse, *dto.Paging, error)
+	Update(id int, updateAuthor *dto.UpdateAuthorRequest) (*model.Author, error)
+	Delete(id int) error
+}
+
+var (
+	ErrAuthorNotFound = errors.New("author not found")
+	ErrAuthorInUse    = errors.New("author is still credited on books")
+	ErrAuthorName     = fmt.Errorf("author must name at least one author, each at most %d characters", maxAuthorName)
+)
+
+// maxAuthorName matches the size of the authors.name column.
+const maxAuthorName = 150
+
+type authorUsecase struct {
+	authorRepo  repository.AuthorRepository
+	bookUsecase BookUsecase
+}
+
+func (au *authorUsecase) Create(req dto.CreateAuthorRequest) (*model.Author, error) {
+	author := &model.Author{
+		Name: req.Name,
+		Bio:  req.Bio,
+	}
+
+	create, err := au.authorRepo.CreateAuthor(author)
+	if err != nil {
+		return nil, err
+	}
+
+	return create, nil
+}
+
+func (au *authorUsecase) GetAll() ([]dto.AuthorResponse, error) {
+	response := []dto.AuthorResponse{}
+
+	authors, err := au.authorRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, author := range authors {
+		response = append(response, toAuthorResponse(author))
+	}
+
+	return response, nil
+}
+
+func (au *authorUsecase) GetById(id int) (*dto.AuthorResponse, error) {
+	author, err := au.authorRepo.FindById(id)
+	if err != nil {
+		return nil, ErrAuthorNotFound
+	}
+
+	response := toAuthorResponse(*author)
+	return &response, nil
+}
+
+func (au *authorUsecase) GetBooks(id int, filter dto.BookFilterRequest) ([]dto.BookResponse, *dto.Paging, error) {
+	_, err := au.authorRepo.FindById(id)
+	if err != nil {
+		return nil, nil, ErrAuthorNotFound
+	}
+
+	filter.AuthorID = id
+	return au.bookUsecase.GetAll(filter)
+}
+
+func (au *authorUsecase) Update(id int, updateAuthor *dto.UpdateAuthorRequest) (*model.Author, error) {
+	author, err := au.authorRepo.FindById(id)
+	if err != nil {
+		return nil, ErrAuthorNotFound
+	}
+
+	if updateAuthor.Name != nil {
+		author.Name = *updateAuthor.Name
+	}
+
+	if updateAuthor.Bio != nil {
+		author.Bio = *updateAuthor.Bio
+	}
+
+	update, err := au.authorRepo.UpdateAuthor(id, author)
+	if err != nil {
+		return nil, err
+	}
+
+	return update, nil
+}
+
+func (au *authorUsecase) Delete(id int) error {
+	_, err := au.authorRepo.FindById(id)
+	if err != nil {
+		return ErrAuthorNotFound
+	}
+
+	count, err := au.authorRepo.CountBooks(id)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrAuthorInUse
+	}
+
+	return au.authorRepo.DeleteAuthor(id)
+}
+
+func toAuthorResponse(author model.Author) dto.AuthorResponse {
+	return dto.AuthorResponse{
+		ID:        author.ID,
+		Name:      author.Name,
+		Bio:       author.Bio,
+		CreatedAt: author.CreatedAt,
+		UpdatedAt: author.UpdatedAt,
+	}
+}
+
+func NewAuthorUsecase(authorRepo repository.AuthorRepository, bookUsecase BookUsecase) *authorUsecase {
+	return &authorUsecase{
+		authorRepo:  authorRepo,
+		bookUsecase: bookUsecase,
+	}
+}
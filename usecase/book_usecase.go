@@ -1,102 +1,352 @@
 package usecase
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
 	"github.com/mhmmmdrivaldhi/go-book-api/model"
 	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
 	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+	"github.com/mhmmmdrivaldhi/go-book-api/service"
 )
 
 type BookUsecase interface {
 	Create(req dto.CreateBookRequest) (*model.Book, error)
-	GetAll() ([]dto.BookResponse, error)
-	GetById(id int) (*dto.BookResponse, error)
+	ValidateCreate(req dto.CreateBookRequest) error
+	GetAll(filter dto.BookFilterRequest) ([]dto.BookResponse, *dto.Paging, error)
+	Export(filter dto.BookFilterRequest, fn func(row dto.BookExportRow) error) error
+	GetById(id int, currency string) (*dto.BookResponse, error)
+	GetByIds(ids []int, currency string) (map[int]dto.BookResponse, error)
+	GetBySlug(slug string, currency string) (*dto.BookResponse, error)
+	GetByIsbn(isbn string, currency string) (*dto.BookResponse, error)
 	Update(id int, reqUpdate dto.UpdateBookRequest) (*model.Book, error)
 	Delete(id int,) error
+	BackfillSlugs() error
+	MigrateAuthors() error
+	UploadCover(id int, file io.Reader, currency string) (*dto.BookResponse, error)
+	DeleteCover(id int) error
 }
 
+var (
+	ErrInvalidIsbn   = errors.New("invalid ISBN")
+	ErrDuplicateIsbn = errors.New("a book with this ISBN already exists")
+	ErrCoverTooLarge = fmt.Errorf("cover image must not be larger than %d MB", MaxCoverSize>>20)
+	ErrCoverType     = errors.New("cover image must be a JPEG or PNG")
+	ErrCoverInvalid  = errors.New("cover image could not be decoded")
+	ErrNoCover       = errors.New("book has no cover image")
+)
+
+const (
+	MaxCoverSize   = 5 << 20
+	maxCoverPixels = 40_000_000
+)
+
+// coverThumbnailWidths lists the thumbnails generated for every cover, keyed
+// by the name they are exposed under in BookResponse.
+var coverThumbnailWidths = map[string]int{
+	"small":  160,
+	"medium": 480,
+}
+
+var coverExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// SlugMovedError is returned when a slug used to belong to an entity that has
+// since been renamed, so the caller can redirect to the current slug.
+type SlugMovedError struct {
+	Slug string
+}
+
+func (e *SlugMovedError) Error() string {
+	return fmt.Sprintf("slug has moved to %s", e.Slug)
+}
+
+const defaultPageLimit = 10
+
 type bookUsecase struct {
-	bookRepo repository.BookRepository
+	bookRepo      repository.BookRepository
+	categoryRepo  repository.CategoryRepository
+	slugRepo      repository.SlugRepository
+	authorRepo    repository.AuthorRepository
+	publisherRepo repository.PublisherRepository
+	variantRepo   repository.VariantRepository
+	storage       service.StorageService
+	currencyUsecase CurrencyUsecase
 }
 
 func (bu *bookUsecase) Create(req dto.CreateBookRequest,) (*model.Book, error) {
+	books, err := bu.newBook(req)
+	if err != nil {
+		return nil, err
+	}
+
+	create, err := bu.bookRepo.CreateBook(books)
+	if err != nil {
+		return nil, err
+	}
+
+	err = bu.slugRepo.DeleteRedirect(model.SlugEntityBook, *books.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return create, nil
+}
+
+// ValidateCreate runs every check Create makes without writing anything, so
+// a request can be tried out first.
+func (bu *bookUsecase) ValidateCreate(req dto.CreateBookRequest) error {
+	_, err := bu.newBook(req)
+	return err
+}
+
+// newBook checks the request against stored data and builds the book Create
+// saves. It only reads, which lets ValidateCreate share it.
+func (bu *bookUsecase) newBook(req dto.CreateBookRequest) (*model.Book, error) {
+	_, err := bu.categoryRepo.FindById(req.CategoryID)
+	if err != nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	if req.PublisherID != nil {
+		_, err = bu.publisherRepo.FindById(*req.PublisherID)
+		if err != nil {
+			return nil, ErrPublisherNotFound
+		}
+	}
+
+	authors, err := bu.resolveAuthors(req.Authors, req.Author)
+	if err != nil {
+		return nil, err
+	}
+
 	books := &model.Book{
 		Title:       req.Title,
 		Description: req.Description,
-		Author:      req.Author,
-		Price:       req.Price,
-		Rating:      req.Rating,
+		Author:      authorDisplayName(authors),
+		Authors:     authors,
+		PublisherID: req.PublisherID,
+		Price:       model.NewMoney(req.Price, bu.currencyUsecase.BaseCurrency()),
 		CategoryID:  req.CategoryID,
 	}
 
-	create, err := bu.bookRepo.CreateBook(books)
+	if req.Isbn != "" {
+		err = bu.assignIsbn(books, req.Isbn, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	slug, err := bu.uniqueSlug(req.Title, 0)
 	if err != nil {
 		return nil, err
 	}
+	books.Slug = &slug
 
-	return create, nil
+	reqVariants := req.Variants
+	if len(reqVariants) == 0 {
+		reqVariants = []dto.CreateVariantRequest{{Format: model.FormatPaperback, Price: req.Price}}
+	}
+
+	reserved := make(map[string]bool)
+	for _, reqVariant := range reqVariants {
+		variant, err := newVariant(bu.variantRepo, books, reqVariant, reserved)
+		if err != nil {
+			return nil, err
+		}
+		books.Variants = append(books.Variants, *variant)
+	}
+
+	return books, nil
 }
 
-func (bu *bookUsecase) GetAll() ([]dto.BookResponse, error) {
-	var response []dto.BookResponse
+func (bu *bookUsecase) GetAll(filter dto.BookFilterRequest) ([]dto.BookResponse, *dto.Paging, error) {
+	response := []dto.BookResponse{}
 
-	books, err := bu.bookRepo.FindAll()
+	converter, err := bu.currencyUsecase.Converter(filter.Currency)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	repoFilter, err := bu.repositoryFilter(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var paging *dto.Paging
+	if !filter.Unpaged {
+		paging = &dto.Paging{Page: filter.Page, Limit: filter.Limit}
+		if paging.Page == 0 {
+			paging.Page = 1
+		}
+		if paging.Limit == 0 {
+			paging.Limit = defaultPageLimit
+		}
+		repoFilter.Limit = paging.Limit
+		repoFilter.Offset = (paging.Page - 1) * paging.Limit
+	}
+
+	books, total, err := bu.bookRepo.FindAll(repoFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if paging != nil {
+		paging.TotalRows = total
+		paging.TotalPages = int((total + int64(paging.Limit) - 1) / int64(paging.Limit))
+	}
+
+	categories, err := bu.categoryMap(books)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	for _, book := range books {
-		var categoryResponse *dto.CategoryResponse
-        if book.Category.ID != 0 { 
-            categoryResponse = &dto.CategoryResponse{
-                ID:   book.Category.ID,
-                Name: book.Category.Name,
-            }
-        }
-
-		bookResponse := dto.BookResponse{
-			Category: categoryResponse,
-			Id: book.Id,
-			Title: book.Title,
-			Description: book.Description,
-			Author: book.Author,
-			Price: book.Price,
-			Rating: book.Rating,
-			CreatedAt: book.CreatedAt,
-			UpdatedAt: book.UpdatedAt,
-		}
-		response = append(response, bookResponse)
+		response = append(response, bu.toBookResponse(book, categories, converter))
 	}
-	return response, nil
+	return response, paging, nil
 }
 
-func (bu *bookUsecase) GetById(id int) (*dto.BookResponse, error) {
+// Export streams every book matching the listing filters to fn, ignoring
+// pagination. Prices are those of the variants, which buyers pay, in the
+// base currency; the book's price is that of its cheapest variant.
+func (bu *bookUsecase) Export(filter dto.BookFilterRequest, fn func(row dto.BookExportRow) error) error {
+	repoFilter, err := bu.repositoryFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	return bu.bookRepo.StreamAll(repoFilter, func(row repository.BookExportRow) error {
+		contributors := []dto.BookExportContributor{}
+		if row.Contributors != nil {
+			err := json.Unmarshal([]byte(*row.Contributors), &contributors)
+			if err != nil {
+				return err
+			}
+		}
+
+		variants := []dto.BookExportVariant{}
+		if row.Variants != nil {
+			err := json.Unmarshal([]byte(*row.Variants), &variants)
+			if err != nil {
+				return err
+			}
+		}
+
+		var price int64
+		var currency string
+		for i, variant := range variants {
+			if i == 0 || variant.Price < price {
+				price = variant.Price
+				currency = variant.Currency
+			}
+		}
+
+		return fn(dto.BookExportRow{
+			Id:          row.Id,
+			Title:       row.Title,
+			Slug:        derefString(row.Slug),
+			Isbn13:      derefString(row.Isbn13),
+			Isbn10:      derefString(row.Isbn10),
+			Description: row.Description,
+			Author:      row.Author,
+			Contributors: contributors,
+			Variants:    variants,
+			CategoryID:  row.CategoryID,
+			Category:    row.CategoryName,
+			Publisher:   derefString(row.PublisherName),
+			Price:       price,
+			Currency:    currency,
+			Rating:      row.Rating,
+			RatingCount: row.RatingCount,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		})
+	})
+}
+
+func (bu *bookUsecase) GetById(id int, currency string) (*dto.BookResponse, error) {
 	book, err := bu.bookRepo.FindById(id)
 	if err != nil {
 		return nil, err
 	}
 
-	var categoryResponse *dto.CategoryResponse
-	if book.Category.ID != 0 {
-		categoryResponse = &dto.CategoryResponse{
-			ID: book.Category.ID,
-			Name: book.Category.Name,
+	return bu.bookResponse(*book, currency)
+}
+
+// GetByIds loads several books at once for lists that reference books, keyed
+// by book id. Ids of books that no longer exist are left out.
+func (bu *bookUsecase) GetByIds(ids []int, currency string) (map[int]dto.BookResponse, error) {
+	books, err := bu.bookRepo.FindByIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := bu.categoryMap(books)
+	if err != nil {
+		return nil, err
+	}
+
+	converter, err := bu.currencyUsecase.Converter(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(map[int]dto.BookResponse, len(books))
+	for _, book := range books {
+		response[book.Id] = bu.toBookResponse(book, categories, converter)
+	}
+
+	return response, nil
+}
+
+func (bu *bookUsecase) GetBySlug(slug string, currency string) (*dto.BookResponse, error) {
+	book, err := bu.bookRepo.FindBySlug(slug)
+	if err != nil {
+		redirect, redirectErr := bu.slugRepo.FindRedirect(model.SlugEntityBook, slug)
+		if redirectErr != nil {
+			return nil, err
 		}
+
+		moved, err := bu.bookRepo.FindById(redirect.EntityID)
+		if err != nil || moved.Slug == nil {
+			return nil, errors.New("book not found")
+		}
+
+		return nil, &SlugMovedError{Slug: *moved.Slug}
 	}
 
-	bookResponse := &dto.BookResponse{
-		Category: categoryResponse,
-		Id: book.Id,
-		Title: book.Title,
-		Description: book.Description,
-		Author: book.Author,
-		Price: book.Price,
-		Rating: book.Rating,
-		CreatedAt: book.CreatedAt,
-		UpdatedAt: book.UpdatedAt,
+	return bu.bookResponse(*book, currency)
+}
+
+func (bu *bookUsecase) GetByIsbn(isbn string, currency string) (*dto.BookResponse, error) {
+	isbn13, err := helper.NormalizeISBN(isbn)
+	if err != nil {
+		return nil, ErrInvalidIsbn
+	}
+
+	book, err := bu.bookRepo.FindByIsbn(isbn13)
+	if err != nil {
+		return nil, err
 	}
-	return bookResponse, nil
+
+	return bu.bookResponse(*book, currency)
 }
 
 func (bu *bookUsecase) Update(id int, reqUpdate dto.UpdateBookRequest) (*model.Book, error) {
@@ -104,38 +354,96 @@ func (bu *bookUsecase) Update(id int, reqUpdate dto.UpdateBookRequest) (*model.B
 	if err != nil {
 		return nil, err
 	}
-	
+
+	columns := map[string]interface{}{}
+
+	oldSlug := exists.Slug
 	if reqUpdate.Title != nil {
-		exists.Title = *reqUpdate.Title
+		slug, err := bu.uniqueSlug(*reqUpdate.Title, id)
+		if err != nil {
+			return nil, err
+		}
+
+		columns["title"] = *reqUpdate.Title
+		columns["slug"] = slug
+	}
+
+	if reqUpdate.Isbn != nil && *reqUpdate.Isbn == "" {
+		columns["isbn13"] = nil
+		columns["isbn10"] = nil
+	} else if reqUpdate.Isbn != nil {
+		err = bu.assignIsbn(exists, *reqUpdate.Isbn, id)
+		if err != nil {
+			return nil, err
+		}
+
+		columns["isbn13"] = exists.Isbn13
+		columns["isbn10"] = exists.Isbn10
 	}
 
 	if reqUpdate.Description != nil {
-		exists.Description = *reqUpdate.Description
+		columns["description"] = *reqUpdate.Description
 	}
 
-	if reqUpdate.Author != nil {
-		exists.Author = *reqUpdate.Author
-	
+	var authors []model.BookAuthor
+	if reqUpdate.Authors != nil || reqUpdate.Author != nil {
+		var legacyAuthor string
+		if reqUpdate.Author != nil {
+			legacyAuthor = *reqUpdate.Author
+		}
+
+		authors, err = bu.resolveAuthors(reqUpdate.Authors, legacyAuthor)
+		if err != nil {
+			return nil, err
+		}
+		columns["author"] = authorDisplayName(authors)
 	}
 
-	if reqUpdate.Price != nil {
-		exists.Price = *reqUpdate.Price
+	if reqUpdate.PublisherID != nil {
+		columns["publisher_id"] = nil
+
+		if *reqUpdate.PublisherID != 0 {
+			_, err = bu.publisherRepo.FindById(*reqUpdate.PublisherID)
+			if err != nil {
+				return nil, ErrPublisherNotFound
+			}
+			columns["publisher_id"] = *reqUpdate.PublisherID
+		}
 	}
 
-	if reqUpdate.Rating != nil {
-		exists.Rating = *reqUpdate.Rating
+	if reqUpdate.CategoryID != nil {
+		_, err = bu.categoryRepo.FindById(*reqUpdate.CategoryID)
+		if err != nil {
+			return nil, ErrCategoryNotFound
+		}
+
+		columns["category_id"] = *reqUpdate.CategoryID
 	}
 
-	updateBook, err := bu.bookRepo.UpdateBook(id, exists)
+	updateBook, err := bu.bookRepo.UpdateBook(id, columns, authors)
 	if err != nil {
 		return nil, err
 	}
 
+	if updateBook.Slug != nil && (oldSlug == nil || *oldSlug != *updateBook.Slug) {
+		if oldSlug != nil {
+			err = bu.slugRepo.CreateRedirect(model.SlugEntityBook, *oldSlug, id)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = bu.slugRepo.DeleteRedirect(model.SlugEntityBook, *updateBook.Slug)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return updateBook, nil
 }
 
 func (bu *bookUsecase) Delete(id int,) error {
-	_, err := bu.bookRepo.FindById(id)
+	book, err := bu.bookRepo.FindById(id)
 	if err != nil {
 		return errors.New("book not found")
 	}
@@ -145,9 +453,454 @@ func (bu *bookUsecase) Delete(id int,) error {
 		return errors.New("failed to delete book")
 	}
 
+	if book.CoverKey != nil {
+		bu.deleteCoverFiles(*book.CoverKey)
+	}
+
 	return  nil
 }
 
-func NewBookUsecase(bookRepo repository.BookRepository) *bookUsecase {
-	return &bookUsecase{bookRepo: bookRepo}
+// UploadCover validates the uploaded image, stores it together with its
+// thumbnails and points the book at it. The previous cover, if any, is
+// removed afterwards.
+func (bu *bookUsecase) UploadCover(id int, file io.Reader, currency string) (*dto.BookResponse, error) {
+	book, err := bu.bookRepo.FindById(id)
+	if err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxCoverSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > MaxCoverSize {
+		return nil, ErrCoverTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := coverExtensions[contentType]
+	if !ok {
+		return nil, ErrCoverType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width*config.Height > maxCoverPixels {
+		return nil, ErrCoverInvalid
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCoverInvalid
+	}
+
+	sum := sha256.Sum256(data)
+	coverKey := fmt.Sprintf("covers/%d/%s%s", id, hex.EncodeToString(sum[:8]), extension)
+
+	err = bu.storage.Put(coverKey, contentType, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store cover image: %w", err)
+	}
+
+	for name, width := range coverThumbnailWidths {
+		thumbnail, err := helper.EncodeJPEG(helper.ResizeToWidth(img, width), 85)
+		if err != nil {
+			return nil, err
+		}
+
+		err = bu.storage.Put(coverThumbnailKey(coverKey, name), "image/jpeg", thumbnail)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store cover thumbnail: %w", err)
+		}
+	}
+
+	err = bu.bookRepo.UpdateCover(id, &coverKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if book.CoverKey != nil && *book.CoverKey != coverKey {
+		bu.deleteCoverFiles(*book.CoverKey)
+	}
+
+	return bu.GetById(id, currency)
+}
+
+func (bu *bookUsecase) DeleteCover(id int) error {
+	book, err := bu.bookRepo.FindById(id)
+	if err != nil {
+		return ErrBookNotFound
+	}
+
+	if book.CoverKey == nil {
+		return ErrNoCover
+	}
+
+	err = bu.bookRepo.UpdateCover(id, nil)
+	if err != nil {
+		return err
+	}
+
+	bu.deleteCoverFiles(*book.CoverKey)
+	return nil
+}
+
+// deleteCoverFiles removes a cover and its thumbnails from storage. It runs
+// after the database no longer references them, so a failure only leaves an
+// orphaned file behind and is not reported.
+func (bu *bookUsecase) deleteCoverFiles(coverKey string) {
+	_ = bu.storage.Delete(coverKey)
+	for name := range coverThumbnailWidths {
+		_ = bu.storage.Delete(coverThumbnailKey(coverKey, name))
+	}
+}
+
+func (bu *bookUsecase) coverResponse(coverKey *string) *dto.CoverResponse {
+	if coverKey == nil {
+		return nil
+	}
+
+	return &dto.CoverResponse{
+		Original: bu.storage.URL(*coverKey),
+		Small:    bu.storage.URL(coverThumbnailKey(*coverKey, "small")),
+		Medium:   bu.storage.URL(coverThumbnailKey(*coverKey, "medium")),
+	}
+}
+
+// coverThumbnailKey derives the key of a thumbnail from the cover key, e.g.
+// covers/7/ab12.png becomes covers/7/ab12-small.jpg.
+func coverThumbnailKey(coverKey, name string) string {
+	base := coverKey[:len(coverKey)-len(path.Ext(coverKey))]
+	return base + "-" + name + ".jpg"
+}
+
+// BackfillSlugs gives a slug to every book stored before slugs existed. A
+// book that cannot be given one is logged and left for the next start.
+func (bu *bookUsecase) BackfillSlugs() error {
+	books, err := bu.bookRepo.FindWithoutSlug()
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		err = bu.backfillSlug(book)
+		if err != nil {
+			log.Printf("failed to backfill slug of book %d: %v\n", book.Id, err)
+		}
+	}
+
+	return nil
+}
+
+func (bu *bookUsecase) backfillSlug(book model.Book) error {
+	slug, err := bu.uniqueSlug(book.Title, book.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = bu.bookRepo.UpdateBook(book.Id, map[string]interface{}{"slug": slug}, nil)
+	if err != nil {
+		return err
+	}
+
+	return bu.slugRepo.DeleteRedirect(model.SlugEntityBook, slug)
+}
+
+// MigrateAuthors moves the free-text author of books created before authors
+// became entities into the authors and book_authors tables.
+func (bu *bookUsecase) MigrateAuthors() error {
+	books, err := bu.bookRepo.FindWithoutAuthors()
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		authors, err := bu.resolveAuthors(nil, book.Author)
+		if errors.Is(err, ErrAuthorName) {
+			log.Printf("book %d keeps its free-text author: %v\n", book.Id, err)
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		err = bu.bookRepo.ReplaceAuthors(book.Id, authors)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveAuthors checks the requested authors exist, or falls back to the
+// free-text author string with one author per name. Those are matched or
+// created by the repository when the book is saved, so names that would not
+// fit the authors table are refused here.
+func (bu *bookUsecase) resolveAuthors(reqAuthors []dto.BookAuthorRequest, legacyAuthor string) ([]model.BookAuthor, error) {
+	authors := []model.BookAuthor{}
+	seen := make(map[string]bool)
+
+	for _, reqAuthor := range reqAuthors {
+		author, err := bu.authorRepo.FindById(reqAuthor.AuthorID)
+		if err != nil {
+			return nil, ErrAuthorNotFound
+		}
+
+		role := reqAuthor.Role
+		if role == "" {
+			role = model.AuthorRoleAuthor
+		}
+
+		key := fmt.Sprintf("%d:%s", author.ID, role)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		authors = append(authors, model.BookAuthor{
+			AuthorID: author.ID,
+			Role:     role,
+			Position: len(authors),
+			Author:   *author,
+		})
+	}
+
+	if len(reqAuthors) > 0 {
+		return authors, nil
+	}
+
+	names := helper.SplitAuthorNames(legacyAuthor)
+	if len(names) == 0 && strings.TrimSpace(legacyAuthor) != "" {
+		return nil, ErrAuthorName
+	}
+
+	for _, name := range names {
+		if utf8.RuneCountInString(name) > maxAuthorName {
+			return nil, ErrAuthorName
+		}
+
+		authors = append(authors, model.BookAuthor{
+			Role:     model.AuthorRoleAuthor,
+			Position: len(authors),
+			Author:   model.Author{Name: name},
+		})
+	}
+
+	return authors, nil
+}
+
+// authorDisplayName keeps the legacy author column readable by joining the
+// names credited as author, or everyone credited when nobody has that role.
+func authorDisplayName(authors []model.BookAuthor) string {
+	var names []string
+	for _, author := range authors {
+		if author.Role == model.AuthorRoleAuthor {
+			names = append(names, author.Author.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		for _, author := range authors {
+			names = append(names, author.Author.Name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// assignIsbn stores both ISBN forms on the book after making sure no other
+// book already owns the same ISBN.
+func (bu *bookUsecase) assignIsbn(book *model.Book, raw string, bookId int) error {
+	isbn13, err := helper.NormalizeISBN(raw)
+	if err != nil {
+		return ErrInvalidIsbn
+	}
+
+	owner, err := bu.bookRepo.FindByIsbn(isbn13)
+	if err == nil && owner.Id != bookId {
+		return ErrDuplicateIsbn
+	} else if err != nil && !errors.Is(err, repository.ErrBookNotFound) {
+		return err
+	}
+
+	book.Isbn13 = &isbn13
+	book.Isbn10 = nil
+	if isbn10, ok := helper.ISBN13To10(isbn13); ok {
+		book.Isbn10 = &isbn10
+	}
+
+	return nil
+}
+
+// uniqueSlug derives a slug from the title that is not the current slug of
+// another book. A slug only left behind as a redirect can be taken; the book
+// taking it deletes the redirect once it is saved.
+func (bu *bookUsecase) uniqueSlug(title string, bookId int) (string, error) {
+	return helper.UniqueSlug(helper.Slugify(title), func(slug string) (bool, error) {
+		return bu.bookRepo.SlugExists(slug, bookId)
+	})
+}
+
+// repositoryFilter translates the query parameters of a book listing into a
+// repository filter, expanding the category to its descendants when asked.
+func (bu *bookUsecase) repositoryFilter(filter dto.BookFilterRequest) (repository.BookFilter, error) {
+	repoFilter := repository.BookFilter{
+		AuthorId:    filter.AuthorID,
+		PublisherId: filter.PublisherID,
+	}
+
+	if filter.CategoryID != 0 {
+		repoFilter.CategoryIds = []int{filter.CategoryID}
+
+		if filter.IncludeDescendants {
+			ids, err := bu.categoryRepo.FindDescendantIds(filter.CategoryID)
+			if err != nil {
+				return repoFilter, err
+			}
+			repoFilter.CategoryIds = ids
+		}
+	}
+
+	return repoFilter, nil
+}
+
+// categoryMap loads the categories of the books and their ancestors in one
+// query, so breadcrumbs can be resolved for a whole page of books without a
+// query per book.
+func (bu *bookUsecase) categoryMap(books []model.Book) (map[int]model.Category, error) {
+	ids := make([]int, 0, len(books))
+	for _, book := range books {
+		ids = append(ids, book.CategoryID)
+	}
+
+	categories, err := bu.categoryRepo.FindAncestors(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[int]model.Category, len(categories))
+	for _, category := range categories {
+		byId[category.ID] = category
+	}
+
+	return byId, nil
+}
+
+// categoryBreadcrumb walks from the category up to its root and returns the
+// path ordered root first, e.g. Fiction > Fantasy > Epic.
+func categoryBreadcrumb(categoryId int, categories map[int]model.Category) []dto.CategoryBreadcrumb {
+	breadcrumb := []dto.CategoryBreadcrumb{}
+	seen := make(map[int]bool)
+
+	for id := categoryId; id != 0 && !seen[id]; {
+		category, ok := categories[id]
+		if !ok {
+			break
+		}
+		seen[id] = true
+
+		breadcrumb = append([]dto.CategoryBreadcrumb{{ID: category.ID, Name: category.Name, Slug: derefString(category.Slug)}}, breadcrumb...)
+
+		id = 0
+		if category.ParentID != nil {
+			id = *category.ParentID
+		}
+	}
+
+	return breadcrumb
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// bookResponse builds the response for a single book with prices in the
+// currency.
+func (bu *bookUsecase) bookResponse(book model.Book, currency string) (*dto.BookResponse, error) {
+	categories, err := bu.categoryMap([]model.Book{book})
+	if err != nil {
+		return nil, err
+	}
+
+	converter, err := bu.currencyUsecase.Converter(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	bookResponse := bu.toBookResponse(book, categories, converter)
+	return &bookResponse, nil
+}
+
+func (bu *bookUsecase) toBookResponse(book model.Book, categories map[int]model.Category, converter *MoneyConverter) dto.BookResponse {
+	var categoryResponse *dto.CategoryResponse
+	if book.Category.ID != 0 {
+		categoryResponse = &dto.CategoryResponse{
+			ID: book.Category.ID,
+			Name: book.Category.Name,
+			Slug: derefString(book.Category.Slug),
+			ParentID: book.Category.ParentID,
+		}
+	}
+
+	authors := []dto.BookAuthorResponse{}
+	for _, author := range book.Authors {
+		authors = append(authors, dto.BookAuthorResponse{
+			ID:   author.AuthorID,
+			Name: author.Author.Name,
+			Role: author.Role,
+		})
+	}
+
+	variants := []dto.VariantResponse{}
+	for _, variant := range book.Variants {
+		variants = append(variants, toVariantResponse(variant, converter))
+	}
+
+	var publisherResponse *dto.PublisherResponse
+	if book.Publisher != nil {
+		publisherResponse = &dto.PublisherResponse{
+			ID:        book.Publisher.ID,
+			Name:      book.Publisher.Name,
+			Website:   book.Publisher.Website,
+			CreatedAt: book.Publisher.CreatedAt,
+			UpdatedAt: book.Publisher.UpdatedAt,
+		}
+	}
+
+	return dto.BookResponse{
+		Category: categoryResponse,
+		Breadcrumb: categoryBreadcrumb(book.CategoryID, categories),
+		Id: book.Id,
+		Title: book.Title,
+		Slug: derefString(book.Slug),
+		Isbn13: derefString(book.Isbn13),
+		Isbn10: derefString(book.Isbn10),
+		Description: book.Description,
+		Author: book.Author,
+		Authors: authors,
+		Publisher: publisherResponse,
+		Variants: variants,
+		Cover: bu.coverResponse(book.CoverKey),
+		Price: converter.Convert(book.Price),
+		Rating: book.Rating,
+		RatingCount: book.RatingCount,
+		CreatedAt: book.CreatedAt,
+		UpdatedAt: book.UpdatedAt,
+	}
+}
+
+func NewBookUsecase(bookRepo repository.BookRepository, categoryRepo repository.CategoryRepository, slugRepo repository.SlugRepository, authorRepo repository.AuthorRepository, publisherRepo repository.PublisherRepository, variantRepo repository.VariantRepository, storage service.StorageService, currencyUsecase CurrencyUsecase) *bookUsecase {
+	return &bookUsecase{
+		bookRepo:      bookRepo,
+		categoryRepo:  categoryRepo,
+		slugRepo:      slugRepo,
+		authorRepo:    authorRepo,
+		publisherRepo: publisherRepo,
+		variantRepo:   variantRepo,
+		storage:       storage,
+		currencyUsecase: currencyUsecase,
+	}
 }
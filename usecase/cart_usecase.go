@@ -3,164 +3,474 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"slices"
 
-	"github.com/mhmmmdrivaldhi/go-book-api/helper"
 	"github.com/mhmmmdrivaldhi/go-book-api/model"
 	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
 	"github.com/mhmmmdrivaldhi/go-book-api/repository"
 )
 
 type CartUsecase interface {
-	AddToCart(ctx context.Context, userId int, item model.Item) (*model.Cart, error)
-	GetCartFromUser(ctx context.Context, userId int) (*model.Cart, error)
-	UpdateQtyFromItem(ctx context.Context, userId int, req dto.RequestUpdateQtyFromItem) (*model.Cart, error)
-	UpdateItemFromCart(ctx context.Context, userId int, req dto.RequestUpdateItemFromCart) (*model.Cart, error)
-	RemoveItemFromCart(ctx context.Context, userId, bookId int) (*model.Cart, error)
-	ClearAllItemFromCart(ctx context.Context, userId int) error
+	AddToCart(ctx context.Context, owner model.CartOwner, item model.Item, currency string) (*model.Cart, error)
+	GetCartFromUser(ctx context.Context, owner model.CartOwner, currency string) (*model.Cart, error)
+	UpdateQtyFromItem(ctx context.Context, owner model.CartOwner, req dto.RequestUpdateQtyFromItem, currency string) (*model.Cart, error)
+	UpdateItemFromCart(ctx context.Context, owner model.CartOwner, req dto.RequestUpdateItemFromCart, currency string) (*model.Cart, error)
+	RemoveItemFromCart(ctx context.Context, owner model.CartOwner, variantId int, currency string) (*model.Cart, error)
+	ClearAllItemFromCart(ctx context.Context, owner model.CartOwner) error
+	ApplyCoupon(ctx context.Context, owner model.CartOwner, req dto.ApplyCouponRequest, currency string) (*model.Cart, error)
+	RemoveCoupon(ctx context.Context, owner model.CartOwner, currency string) (*model.Cart, error)
+	SetTaxLocation(ctx context.Context, owner model.CartOwner, req dto.SetTaxLocationRequest, currency string) (*model.Cart, error)
+	SetAddress(ctx context.Context, owner model.CartOwner, req dto.SetCartAddressRequest, currency string) (*model.Cart, error)
+	SetShippingMethod(ctx context.Context, owner model.CartOwner, req dto.SetShippingMethodRequest, currency string) (*model.Cart, error)
+	ReviewCart(ctx context.Context, owner model.CartOwner, currency string) (*model.Cart, error)
+	MergeGuestCart(ctx context.Context, userId int, token string) error
 }
 
+var (
+	// ErrGuestCart is returned for what only a signed in user's cart can do.
+	ErrGuestCart = errors.New("sign in to use your address book")
+	// ErrCartBusy is returned when the cart kept being changed by other
+	// requests while this one tried to change it.
+	ErrCartBusy = repository.ErrCartBusy
+	// ErrCartQtyLimit is returned when a line would hold more copies than
+	// a cart line can.
+	ErrCartQtyLimit = fmt.Errorf("a cart line can hold at most %d copies", maxItemQty)
+)
+
+// maxItemQty is the most copies a cart line holds, however they were added.
+const maxItemQty = 99
+
 type cartUsecase struct {
 	cartRepo repository.CartRepository
-	bookUsecase BookUsecase 
+	variantUsecase VariantUsecase
+	pricingUsecase PricingUsecase
+	addressUsecase AddressUsecase
+	shippingUsecase ShippingUsecase
+	currencyUsecase CurrencyUsecase
 }
 
-func (cu *cartUsecase) AddToCart(ctx context.Context, userId int, item model.Item) (*model.Cart, error) {
-	qty := 0
-	if qty <= 0 {
+// AddToCart adds copies of a variant to the cart, or more copies to its line.
+// A line already in the cart keeps its price; repricing moves it to the
+// variant's price in the cart currency and flags the change.
+func (cu *cartUsecase) AddToCart(ctx context.Context, owner model.CartOwner, item model.Item, currency string) (*model.Cart, error) {
+	if item.Qty <= 0 {
 		return nil, errors.New("quantity must be greater than 0")
 	}
 
-	book, err := cu.bookUsecase.GetById(item.BookId)
+	variant, err := cu.variantUsecase.GetById(item.VariantId)
 	if err != nil {
-		return nil, errors.New("book not found")
+		return nil, ErrVariantNotFound
 	}
 
-	cart, err := cu.cartRepo.GetCart(ctx, userId)
-	if err != nil {
-		return nil, err
-	}
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		qty := item.Qty
+		found := false
+		for i := range cart.Items {
+			if cart.Items[i].VariantId == item.VariantId {
+				cart.Items[i].Qty += item.Qty
+				qty = cart.Items[i].Qty
+				found = true
+				break
+			}
+		}
 
-	for i := range cart.Items {
-		if cart.Items[i].BookId == item.BookId {
-			cart.Items[i].Qty += item.Qty
-			cart.Items[i].Price = book.Price
-			break
+		if qty > maxItemQty {
+			return ErrCartQtyLimit
 		}
-	}
 
-	cart.TotalPrice = helper.CalculateTotalPrice(cart)
+		if !variant.IsDigital() && variant.Stock < qty {
+			return ErrInsufficientStock
+		}
 
-	_, err = cu.cartRepo.SetCart(ctx, userId, cart)
-	if err != nil {
-		return nil, errors.New("failed to added item to cart")
-	}
+		if !found {
+			cart.Items = append(cart.Items, itemFromVariant(variant, qty))
+		}
 
-	return cart, nil
+		return cu.reprice(owner, cart, currency)
+	})
 }
 
-func (cu *cartUsecase) GetCartFromUser(ctx context.Context, userId int) (*model.Cart, error) {
-	cart, err := cu.cartRepo.GetCart(ctx, userId)
-	if err != nil {
-		return nil, errors.New("failed to get cart")
-	}
+// GetCartFromUser loads the cart with every line checked against the
+// current price and stock of its variant. What the check finds is saved, so
+// the flags stay on the cart until the customer reviews it; a cart the check
+// leaves as it was is not written back, only kept for another TTL.
+func (cu *cartUsecase) GetCartFromUser(ctx context.Context, owner model.CartOwner, currency string) (*model.Cart, error) {
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		stored := slices.Clone(cart.Items)
+		unavailable := len(cart.UnavailableItems)
+
+		err := cu.reprice(owner, cart, currency)
+		if err != nil {
+			return err
+		}
+
+		if len(cart.UnavailableItems) == unavailable && slices.EqualFunc(stored, cart.Items, sameItem) {
+			return repository.ErrCartUnchanged
+		}
 
-	return cart, nil
+		return nil
+	})
 }
 
-func (cu *cartUsecase) UpdateQtyFromItem(ctx context.Context, userId int, req dto.RequestUpdateQtyFromItem) (*model.Cart, error) {
+func (cu *cartUsecase) UpdateQtyFromItem(ctx context.Context, owner model.CartOwner, req dto.RequestUpdateQtyFromItem, currency string) (*model.Cart, error) {
 	if req.Qty != nil && *req.Qty <= 0{
 		return nil, errors.New("quantity must be greater than 0")
 	}
 
-	cart, err := cu.cartRepo.GetCart(ctx, userId)
+	variant, err := cu.variantUsecase.GetById(*req.VariantId)
+	if err != nil {
+		return nil, ErrVariantNotFound
+	}
+
+	if !variant.IsDigital() && variant.Stock < *req.Qty {
+		return nil, ErrInsufficientStock
+	}
+
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		i := slices.IndexFunc(cart.Items, func(item model.Item) bool { return item.VariantId == *req.VariantId })
+		if i < 0 {
+			return ErrVariantNotFound
+		}
+
+		cart.Items[i].Qty = *req.Qty
+		return cu.reprice(owner, cart, currency)
+	})
+}
+
+func (cu *cartUsecase) UpdateItemFromCart(ctx context.Context, owner model.CartOwner, req dto.RequestUpdateItemFromCart, currency string) (*model.Cart, error) {
+	variant, err := cu.variantUsecase.GetById(*req.VariantId)
 	if err != nil {
-		return nil, errors.New("failed to get cart")
+		return nil, ErrVariantNotFound
 	}
 
-	for i := range cart.Items {
-		if cart.Items[i].BookId == *req.BookId {
-			cart.Items[i].Qty = *req.Qty
-			break
+	if !variant.IsDigital() && variant.Stock < *req.Qty {
+		return nil, ErrInsufficientStock
+	}
+
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		i := slices.IndexFunc(cart.Items, func(item model.Item) bool { return item.VariantId == *req.VariantId })
+		if i < 0 {
+			return ErrVariantNotFound
+		}
+
+		cart.Items[i] = itemFromVariant(variant, *req.Qty)
+		return cu.reprice(owner, cart, currency)
+	})
+}
+
+func (cu *cartUsecase) RemoveItemFromCart(ctx context.Context, owner model.CartOwner, variantId int, currency string) (*model.Cart, error) {
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		items := []model.Item{}
+		for _, item := range cart.Items {
+			if item.VariantId != variantId {
+				items = append(items, item)
+			}
 		}
+
+		cart.Items = items
+		return cu.reprice(owner, cart, currency)
+	})
+}
+
+func (cu *cartUsecase) ClearAllItemFromCart(ctx context.Context, owner model.CartOwner) error {
+	err := cu.cartRepo.ClearCart(ctx, owner)
+	if err != nil {
+		return errors.New("failed to clear all item from cart")
 	}
 
-	cart.TotalPrice = helper.CalculateTotalPrice(cart)
+	return nil
+}
 
-	_, err = cu.cartRepo.SetCart(ctx, userId, cart)
+// ApplyCoupon puts the coupon on the cart once it has checked the coupon
+// gives a discount on the current items.
+func (cu *cartUsecase) ApplyCoupon(ctx context.Context, owner model.CartOwner, req dto.ApplyCouponRequest, currency string) (*model.Cart, error) {
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		err := cu.pricingUsecase.CheckCoupon(owner.UserId, req.Code, cart, currency)
+		if err != nil {
+			return err
+		}
+
+		cart.CouponCode = normalizeCouponCode(req.Code)
+		return cu.reprice(owner, cart, currency)
+	})
+}
+
+func (cu *cartUsecase) RemoveCoupon(ctx context.Context, owner model.CartOwner, currency string) (*model.Cart, error) {
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		// the guest's coupon does not apply to the user, so it is not
+		// carried over
+		cart.CouponCode = ""
+		return cu.reprice(owner, cart, currency)
+	})
+}
+
+// SetTaxLocation sets the country and region the cart is taxed in. The
+// shipping address, which taxed the cart until now, is dropped.
+func (cu *cartUsecase) SetTaxLocation(ctx context.Context, owner model.CartOwner, req dto.SetTaxLocationRequest, currency string) (*model.Cart, error) {
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		cart.AddressId = 0
+		cart.Country = normalizeCountry(req.Country)
+		cart.Region = normalizeRegion(req.Region)
+		return cu.reprice(owner, cart, currency)
+	})
+}
+
+// SetAddress ships the cart to an address from the user's address book,
+// which also decides where the cart is taxed and which shipping methods are
+// quoted.
+func (cu *cartUsecase) SetAddress(ctx context.Context, owner model.CartOwner, req dto.SetCartAddressRequest, currency string) (*model.Cart, error) {
+	if owner.IsGuest() {
+		return nil, ErrGuestCart
+	}
+
+	address, err := cu.addressUsecase.GetById(req.AddressID, owner.UserId)
 	if err != nil {
-		return nil, errors.New("failed to update qty from item")
+		return nil, err
 	}
 
-	return cart, nil
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		cart.AddressId = address.ID
+		cart.Country = address.Country
+		cart.Region = address.Region
+		return cu.reprice(owner, cart, currency)
+	})
 }
 
-func (cu *cartUsecase) UpdateItemFromCart(ctx context.Context, userId int, req dto.RequestUpdateItemFromCart) (*model.Cart, error) {
-	cart, err := cu.cartRepo.GetCart(ctx, userId)
+// SetShippingMethod chooses how the cart is shipped. Only a method quoted
+// for the cart as it is now can be chosen.
+func (cu *cartUsecase) SetShippingMethod(ctx context.Context, owner model.CartOwner, req dto.SetShippingMethodRequest, currency string) (*model.Cart, error) {
+	_, err := cu.shippingUsecase.GetMethodById(req.ShippingMethodID, "")
 	if err != nil {
-		return nil, errors.New("failed to get cart")
+		return nil, err
 	}
 
-	book, err := cu.bookUsecase.GetById(*req.BookId)
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		cart.ShippingMethodId = req.ShippingMethodID
+		err := cu.reprice(owner, cart, currency)
+		if err != nil {
+			return err
+		}
+
+		if cart.ShippingError != "" {
+			return ErrShippingMethodUnavailable
+		}
+
+		return nil
+	})
+}
+
+// ReviewCart records that the customer has seen the price changes and
+// unavailable lines flagged on the cart, which clears them and lets the
+// cart be checked out again.
+func (cu *cartUsecase) ReviewCart(ctx context.Context, owner model.CartOwner, currency string) (*model.Cart, error) {
+	return cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		err := cu.reprice(owner, cart, currency)
+		if err != nil {
+			return err
+		}
+
+		cart.UnavailableItems = nil
+		for i := range cart.Items {
+			cart.Items[i].PreviousPrice = nil
+			if cart.Items[i].Status == model.ItemStatusPriceChanged {
+				cart.Items[i].Status = ""
+			}
+		}
+
+		return nil
+	})
+}
+
+// MergeGuestCart moves the guest cart of token into the user's cart when the
+// guest signs in, and deletes it. A book in both carts keeps the larger of
+// the two quantities rather than their sum, as it is most likely the same
+// copy added before and after signing in. Every line is capped at
+// maxItemQty and physical copies at the stock left; books no longer sold
+// are dropped. The coupon, tax location and shipping method of the user's
+// cart win; the guest's only fill in what the user's cart leaves empty, and
+// the guest's coupon only when it applies to the user. The merged cart is
+// priced again for the user, in the currency the guest was shopping in.
+func (cu *cartUsecase) MergeGuestCart(ctx context.Context, userId int, token string) error {
+	guestOwner := model.CartOwner{Token: token}
+	guest, err := cu.cartRepo.GetCart(ctx, guestOwner)
 	if err != nil {
-		return nil, errors.New("book not found")
+		return errors.New("failed to get guest cart")
+	}
+
+	if len(guest.Items) == 0 {
+		return cu.cartRepo.ClearCart(ctx, guestOwner)
 	}
 
-	for i := range cart.Items {
-		if cart.Items[i].BookId == *req.BookId {
-			cart.Items[i] = model.Item{
-				BookId: book.Id,
-				Price: book.Price * (*req.Qty),
-				Qty: *req.Qty,
+	variants := map[int]*model.BookVariant{}
+	for _, item := range guest.Items {
+		variant, err := cu.variantUsecase.GetById(item.VariantId)
+		if err == nil {
+			variants[item.VariantId] = variant
+		}
+	}
+
+	owner := model.CartOwner{UserId: userId}
+	currency := guest.Currency
+	_, err = cu.cartRepo.UpdateCart(ctx, owner, func(cart *model.Cart) error {
+		for _, item := range guest.Items {
+			variant, ok := variants[item.VariantId]
+			if !ok {
+				continue
+			}
+
+			index := -1
+			qty := item.Qty
+			for i := range cart.Items {
+				if cart.Items[i].VariantId == item.VariantId {
+					index = i
+					qty = max(qty, cart.Items[i].Qty)
+					break
+				}
+			}
+
+			qty = min(qty, maxItemQty)
+			if !variant.IsDigital() {
+				qty = min(qty, variant.Stock)
 			}
-			break
+
+			if qty <= 0 {
+				continue
+			}
+
+			if index >= 0 {
+				cart.Items[index].Qty = qty
+			} else {
+				cart.Items = append(cart.Items, itemFromVariant(variant, qty))
+			}
+		}
+
+		guestCoupon := cart.CouponCode == "" && guest.CouponCode != ""
+		if guestCoupon {
+			cart.CouponCode = guest.CouponCode
 		}
+
+		if cart.Country == "" {
+			cart.Country = guest.Country
+			cart.Region = guest.Region
+		}
+
+		if cart.ShippingMethodId == 0 {
+			cart.ShippingMethodId = guest.ShippingMethodId
+		}
+
+		cart.UserId = userId
+		err := cu.reprice(owner, cart, currency)
+		if err != nil || !guestCoupon || cart.CouponError == "" {
+			return err
+		}
+
+		cart.CouponCode = ""
+		return cu.reprice(owner, cart, currency)
+	})
+	if err != nil {
+		return err
 	}
 
-	cart.TotalPrice = helper.CalculateTotalPrice(cart)
-	_, err = cu.cartRepo.SetCart(ctx, userId, cart)
+	return cu.cartRepo.ClearCart(ctx, guestOwner)
+}
+
+// reprice checks the lines against their variants and prices the cart.
+func (cu *cartUsecase) reprice(owner model.CartOwner, cart *model.Cart, currency string) error {
+	err := cu.reconcile(cart, currency)
 	if err != nil {
-		return nil, errors.New("failed to update item from cart")
+		return err
 	}
 
-	return cart, nil
+	_, err = cu.pricingUsecase.Reprice(owner.UserId, cart, currency)
+	return err
 }
 
-func (cu *cartUsecase) RemoveItemFromCart(ctx context.Context, userId, bookId int) (*model.Cart, error) {
-	cart, err := cu.cartRepo.GetCart(ctx, userId)
+// reconcile brings every line up to date with its variant. A line whose
+// variant, or book, was deleted moves to the unavailable lines. A line
+// whose price changed since it was added takes the new price and keeps the
+// old one in PreviousPrice, unless it already holds an older one; a price
+// that changed back clears it. A change of currency is not a price change.
+// Lines with more copies than are left in stock are flagged with how many
+// are, and are left for the customer to lower.
+func (cu *cartUsecase) reconcile(cart *model.Cart, currency string) error {
+	converter, err := cu.currencyUsecase.Converter(currency)
 	if err != nil {
-		return nil, errors.New("failed to get cart")
-	} 
+		return err
+	}
 
-	items := []model.Item{}
+	items := make([]model.Item, 0, len(cart.Items))
 	for _, item := range cart.Items {
-		if item.BookId != bookId {
-			items = append(items, item)
+		variant, err := cu.variantUsecase.GetById(item.VariantId)
+		if errors.Is(err, ErrVariantNotFound) {
+			item.Status = model.ItemStatusUnavailable
+			item.PreviousPrice = nil
+			item.AvailableQty = nil
+			cart.UnavailableItems = append(cart.UnavailableItems, item)
+			continue
+		} else if err != nil {
+			return err
 		}
+
+		price := converter.VariantPrice(*variant)
+		if item.Price.Currency != price.Currency {
+			item.PreviousPrice = nil
+		} else if item.PreviousPrice != nil && *item.PreviousPrice == price {
+			item.PreviousPrice = nil
+		} else if item.PreviousPrice == nil && item.Price != price {
+			previous := item.Price
+			item.PreviousPrice = &previous
+		}
+
+		updated := itemFromVariant(variant, item.Qty)
+		updated.Price = price
+		updated.PreviousPrice = item.PreviousPrice
+
+		if updated.PreviousPrice != nil {
+			updated.Status = model.ItemStatusPriceChanged
+		}
+
+		if !variant.IsDigital() && variant.Stock < item.Qty {
+			stock := max(variant.Stock, 0)
+			updated.Status = model.ItemStatusInsufficientStock
+			updated.AvailableQty = &stock
+		}
+
+		items = append(items, updated)
 	}
 
 	cart.Items = items
-	cart.TotalPrice = helper.CalculateTotalPrice(cart)
+	return nil
+}
 
-	_, err = cu.cartRepo.SetCart(ctx, userId, cart)
-	if err != nil {
-		return nil, errors.New("failed to remove item from cart")
-	}
-	return cart, nil
+// sameItem reports whether two cart lines hold the same values.
+func sameItem(a, b model.Item) bool {
+	return reflect.DeepEqual(a, b)
 }
 
-func (cu *cartUsecase) ClearAllItemFromCart(ctx context.Context, userId int) error {
-	err := cu.cartRepo.ClearCart(ctx, userId)
-	if err != nil {
-		return errors.New("failed to clear all item from cart")
+// itemFromVariant snapshots the variant into a cart line priced per unit in
+// the base currency; repricing moves it into the cart currency.
+func itemFromVariant(variant *model.BookVariant, qty int) model.Item {
+	return model.Item{
+		VariantId: variant.ID,
+		BookId: variant.BookID,
+		Sku: variant.Sku,
+		Format: variant.Format,
+		Weight: variant.Weight,
+		Price: variant.Price,
+		Qty: qty,
 	}
-
-	return nil
 }
 
-func NewCartUsecase(cartRepo repository.CartRepository, bookUsecase BookUsecase) CartUsecase {
+func NewCartUsecase(cartRepo repository.CartRepository, variantUsecase VariantUsecase, pricingUsecase PricingUsecase, addressUsecase AddressUsecase, shippingUsecase ShippingUsecase, currencyUsecase CurrencyUsecase) CartUsecase {
 	return &cartUsecase{
 		cartRepo: cartRepo,
-		bookUsecase: bookUsecase,
+		variantUsecase: variantUsecase,
+		pricingUsecase: pricingUsecase,
+		addressUsecase: addressUsecase,
+		shippingUsecase: shippingUsecase,
+		currencyUsecase: currencyUsecase,
 	}
 }
 
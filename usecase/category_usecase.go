@@ -2,7 +2,10 @@ package usecase
 
 import (
 	"errors"
+	"log"
+	"slices"
 
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
 	"github.com/mhmmmdrivaldhi/go-book-api/model"
 	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
 	"github.com/mhmmmdrivaldhi/go-book-api/repository"
@@ -11,25 +14,58 @@ import (
 type CategoryUsecase interface {
 	Create(req dto.CreateCategoryRequest) (*model.Category, error)
 	GetAll() ([]dto.CategoryResponse, error)
+	GetTree() ([]dto.CategoryTreeResponse, error)
 	GetById(id int) (*dto.CategoryResponse, error)
+	GetBySlug(slug string) (*dto.CategoryResponse, error)
+	GetBooks(id int, filter dto.BookFilterRequest) ([]dto.BookResponse, *dto.Paging, error)
 	Update(id int, updateCategory *dto.UpdateCategoryRequest) (*model.Category, error)
-	Delete(id int) error 
+	Delete(id int, req dto.DeleteCategoryRequest) (*dto.DeleteCategoryResponse, error)
+	BackfillSlugs() error
 }
 
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryInUse    = errors.New("category still has books, choose the reassign or cascade strategy")
+	ErrCategoryCycle    = errors.New("category cannot be moved below itself or one of its descendants")
+	ErrCategoryParent   = errors.New("parent category not found")
+	ErrCategoryTarget   = errors.New("target category must be an existing category other than the deleted one")
+)
+
 type categoryUsecase struct {
 	categoryRepo repository.CategoryRepository
+	slugRepo     repository.SlugRepository
+	bookUsecase  BookUsecase
 }
 
 func (cu *categoryUsecase) Create(req dto.CreateCategoryRequest) (*model.Category, error) {
+	if req.ParentID != nil {
+		_, err := cu.categoryRepo.FindById(*req.ParentID)
+		if err != nil {
+			return nil, ErrCategoryParent
+		}
+	}
+
 	category := &model.Category{
-		Name: req.Name,
+		Name:     req.Name,
+		ParentID: req.ParentID,
 	}
 
+	slug, err := cu.uniqueSlug(req.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+	category.Slug = &slug
+
 	book, err := cu.categoryRepo.CreateCategory(category)
 	if err != nil {
 		return nil, err
 	}
 
+	err = cu.slugRepo.DeleteRedirect(model.SlugEntityCategory, slug)
+	if err != nil {
+		return nil, err
+	}
+
 	return book, nil
 }
 
@@ -41,10 +77,21 @@ func (cu *categoryUsecase) GetAll() ([]dto.CategoryResponse, error) {
 		return nil, err
 	}
 
+	counts, err := cu.categoryRepo.CountBooksByCategory()
+	if err != nil {
+		return nil, err
+	}
+
 	for _, category := range categories {
+		bookCount := counts[category.ID]
 		categoryResponse := dto.CategoryResponse{
 			ID: category.ID,
 			Name: category.Name,
+			Slug: derefString(category.Slug),
+			ParentID: category.ParentID,
+			BookCount: &bookCount,
+			CreatedAt: category.CreatedAt,
+			UpdatedAt: category.UpdatedAt,
 		}
 		response = append(response, categoryResponse)
 	}
@@ -52,15 +99,63 @@ func (cu *categoryUsecase) GetAll() ([]dto.CategoryResponse, error) {
 	return response, nil
 }
 
+func (cu *categoryUsecase) GetTree() ([]dto.CategoryTreeResponse, error) {
+	categories, err := cu.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := cu.categoryRepo.CountBooksByCategory()
+	if err != nil {
+		return nil, err
+	}
+
+	childrenOf := make(map[int][]model.Category)
+	var roots []model.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			childrenOf[*category.ParentID] = append(childrenOf[*category.ParentID], category)
+		}
+	}
+
+	var build func(nodes []model.Category) []dto.CategoryTreeResponse
+	build = func(nodes []model.Category) []dto.CategoryTreeResponse {
+		tree := []dto.CategoryTreeResponse{}
+		for _, node := range nodes {
+			tree = append(tree, dto.CategoryTreeResponse{
+				ID:        node.ID,
+				Name:      node.Name,
+				Slug:      derefString(node.Slug),
+				ParentID:  node.ParentID,
+				BookCount: counts[node.ID],
+				Children:  build(childrenOf[node.ID]),
+			})
+		}
+		return tree
+	}
+
+	return build(roots), nil
+}
+
 func (cu *categoryUsecase) GetById(id int) (*dto.CategoryResponse, error) {
 	category, err := cu.categoryRepo.FindById(id)
 	if err != nil {
 		return nil, err
 	}
 
+	bookCount, err := cu.categoryRepo.CountBooks(id)
+	if err != nil {
+		return nil, err
+	}
+
 	response := &dto.CategoryResponse{
 		ID: category.ID,
 		Name: category.Name,
+		Slug: derefString(category.Slug),
+		ParentID: category.ParentID,
+		BookCount: &bookCount,
 		CreatedAt: category.CreatedAt,
 		UpdatedAt: category.UpdatedAt,
 	}
@@ -68,14 +163,72 @@ func (cu *categoryUsecase) GetById(id int) (*dto.CategoryResponse, error) {
 	return response, nil
 }
 
+func (cu *categoryUsecase) GetBySlug(slug string) (*dto.CategoryResponse, error) {
+	category, err := cu.categoryRepo.FindBySlug(slug)
+	if err != nil {
+		redirect, redirectErr := cu.slugRepo.FindRedirect(model.SlugEntityCategory, slug)
+		if redirectErr != nil {
+			return nil, ErrCategoryNotFound
+		}
+
+		moved, err := cu.categoryRepo.FindById(redirect.EntityID)
+		if err != nil || moved.Slug == nil {
+			return nil, ErrCategoryNotFound
+		}
+
+		return nil, &SlugMovedError{Slug: *moved.Slug}
+	}
+
+	return cu.GetById(category.ID)
+}
+
+func (cu *categoryUsecase) GetBooks(id int, filter dto.BookFilterRequest) ([]dto.BookResponse, *dto.Paging, error) {
+	_, err := cu.categoryRepo.FindById(id)
+	if err != nil {
+		return nil, nil, ErrCategoryNotFound
+	}
+
+	filter.CategoryID = id
+	return cu.bookUsecase.GetAll(filter)
+}
+
 func (cu *categoryUsecase) Update(id int, updateCategory *dto.UpdateCategoryRequest) (*model.Category, error) {
 	category, err := cu.categoryRepo.FindById(id)
 	if err != nil {
-		return nil, err
+		return nil, ErrCategoryNotFound
 	}
 
+	oldSlug := category.Slug
 	if updateCategory.Name != nil {
 		category.Name = *updateCategory.Name
+
+		slug, err := cu.uniqueSlug(category.Name, id)
+		if err != nil {
+			return nil, err
+		}
+		category.Slug = &slug
+	}
+
+	if updateCategory.ParentID != nil {
+		category.ParentID = nil
+
+		if *updateCategory.ParentID != 0 {
+			_, err = cu.categoryRepo.FindById(*updateCategory.ParentID)
+			if err != nil {
+				return nil, ErrCategoryParent
+			}
+
+			descendantIds, err := cu.categoryRepo.FindDescendantIds(id)
+			if err != nil {
+				return nil, err
+			}
+
+			if slices.Contains(descendantIds, *updateCategory.ParentID) {
+				return nil, ErrCategoryCycle
+			}
+
+			category.ParentID = updateCategory.ParentID
+		}
 	}
 
 	update, err := cu.categoryRepo.UpdateCategory(id, category)
@@ -84,23 +237,113 @@ func (cu *categoryUsecase) Update(id int, updateCategory *dto.UpdateCategoryRequ
 	
 	}
 
+	if oldSlug != nil && *oldSlug != *update.Slug {
+		err = cu.slugRepo.CreateRedirect(model.SlugEntityCategory, *oldSlug, id)
+		if err != nil {
+			return nil, err
+		}
+
+		err = cu.slugRepo.DeleteRedirect(model.SlugEntityCategory, *update.Slug)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return update, nil
 }
 
-func (cu *categoryUsecase) Delete(id int) error {
+func (cu *categoryUsecase) Delete(id int, req dto.DeleteCategoryRequest) (*dto.DeleteCategoryResponse, error) {
 	_, err := cu.categoryRepo.FindById(id)
 	if err != nil {
-		return errors.New("category not found")
+		return nil, ErrCategoryNotFound
+	}
+
+	response := &dto.DeleteCategoryResponse{
+		CategoryID: id,
+		Strategy:   req.Strategy,
+	}
+	if response.Strategy == "" {
+		response.Strategy = dto.DeleteStrategyRestrict
+	}
+
+	switch response.Strategy {
+	case dto.DeleteStrategyReassign:
+		if req.TargetID == id {
+			return nil, ErrCategoryTarget
+		}
+
+		_, err = cu.categoryRepo.FindById(req.TargetID)
+		if err != nil {
+			return nil, ErrCategoryTarget
+		}
+
+		response.TargetID = req.TargetID
+		response.AffectedBooks, err = cu.categoryRepo.ReassignBooksAndDelete(id, req.TargetID)
+		if err != nil {
+			return nil, err
+		}
+	case dto.DeleteStrategyCascade:
+		response.AffectedBooks, err = cu.categoryRepo.DeleteWithBooks(id)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		err = cu.categoryRepo.DeleteCategory(id)
+		if errors.Is(err, repository.ErrCategoryHasBooks) {
+			return nil, ErrCategoryInUse
+		} else if err != nil {
+			return nil, errors.New("failed to delete category")
+		}
+	}
+
+	return response, nil
+}
+
+// BackfillSlugs gives a slug to every category stored before slugs existed.
+// A category that cannot be given one is logged and left for the next start.
+func (cu *categoryUsecase) BackfillSlugs() error {
+	categories, err := cu.categoryRepo.FindWithoutSlug()
+	if err != nil {
+		return err
 	}
 
-	err = cu.categoryRepo.DeleteCategory(id)
+	for _, category := range categories {
+		err = cu.backfillSlug(category)
+		if err != nil {
+			log.Printf("failed to backfill slug of category %d: %v\n", category.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (cu *categoryUsecase) backfillSlug(category model.Category) error {
+	slug, err := cu.uniqueSlug(category.Name, category.ID)
+	if err != nil {
+		return err
+	}
+	category.Slug = &slug
+
+	_, err = cu.categoryRepo.UpdateCategory(category.ID, &category)
 	if err != nil {
-		return errors.New("failed to delete category")
-	} 
+		return err
+	}
 
-	return err
+	return cu.slugRepo.DeleteRedirect(model.SlugEntityCategory, slug)
 }
 
-func NewCategoryUsecase(categoryRepo repository.CategoryRepository) *categoryUsecase {
-	return &categoryUsecase{categoryRepo: categoryRepo}
+// uniqueSlug derives a slug from the name that is not the current slug of
+// another category. A slug only left behind as a redirect can be taken.
+func (cu *categoryUsecase) uniqueSlug(name string, categoryId int) (string, error) {
+	return helper.UniqueSlug(helper.Slugify(name), func(slug string) (bool, error) {
+		return cu.categoryRepo.SlugExists(slug, categoryId)
+	})
+}
+
+func NewCategoryUsecase(categoryRepo repository.CategoryRepository, slugRepo repository.SlugRepository, bookUsecase BookUsecase) *categoryUsecase {
+	return &categoryUsecase{
+		categoryRepo: categoryRepo,
+		slugRepo:     slugRepo,
+		bookUsecase:  bookUsecase,
+	}
 }
\ No newline at end of file
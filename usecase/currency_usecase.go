@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type CurrencyUsecase interface {
+	BaseCurrency() string
+	GetCurrencies() (*dto.CurrencyResponse, error)
+	SetRate(code string, req dto.SetExchangeRateRequest) (*dto.ExchangeRateResponse, error)
+	DeleteRate(code string) error
+	Resolve(code string) (string, error)
+	Converter(currency string) (*MoneyConverter, error)
+	BackfillCurrencies() error
+}
+
+var (
+	ErrInvalidCurrency     = errors.New("currency must be a three letter ISO 4217 code")
+	ErrUnsupportedCurrency = errors.New("currency has no exchange rate")
+	ErrBaseCurrencyRate    = errors.New("the base currency always has a rate of 1")
+)
+
+type currencyUsecase struct {
+	currencyRepo repository.CurrencyRepository
+	baseCurrency string
+}
+
+func (cu *currencyUsecase) BaseCurrency() string {
+	return cu.baseCurrency
+}
+
+func (cu *currencyUsecase) GetCurrencies() (*dto.CurrencyResponse, error) {
+	rates, err := cu.currencyRepo.FindRates()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CurrencyResponse{
+		Base:       cu.baseCurrency,
+		Currencies: []dto.ExchangeRateResponse{{Currency: cu.baseCurrency, Rate: 1}},
+	}
+	for _, rate := range rates {
+		response.Currencies = append(response.Currencies, toExchangeRateResponse(rate))
+	}
+
+	return response, nil
+}
+
+func (cu *currencyUsecase) SetRate(code string, req dto.SetExchangeRateRequest) (*dto.ExchangeRateResponse, error) {
+	if !helper.ValidCurrencyCode(code) {
+		return nil, ErrInvalidCurrency
+	}
+
+	if code == cu.baseCurrency {
+		return nil, ErrBaseCurrencyRate
+	}
+
+	rate, err := cu.currencyRepo.SaveRate(&model.ExchangeRate{
+		Currency:  code,
+		Rate:      req.Rate,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toExchangeRateResponse(*rate)
+	return &response, nil
+}
+
+func (cu *currencyUsecase) DeleteRate(code string) error {
+	if code == cu.baseCurrency {
+		return ErrBaseCurrencyRate
+	}
+
+	_, err := cu.currencyRepo.FindRate(code)
+	if err != nil {
+		return ErrUnsupportedCurrency
+	}
+
+	return cu.currencyRepo.DeleteRate(code)
+}
+
+// Resolve turns the currency a caller asked for into the one prices are
+// shown in: the base currency when none was asked for, otherwise the
+// requested one as long as it has an exchange rate.
+func (cu *currencyUsecase) Resolve(code string) (string, error) {
+	if code == "" || code == cu.baseCurrency {
+		return cu.baseCurrency, nil
+	}
+
+	if !helper.ValidCurrencyCode(code) {
+		return "", ErrInvalidCurrency
+	}
+
+	_, err := cu.currencyRepo.FindRate(code)
+	if err != nil {
+		return "", ErrUnsupportedCurrency
+	}
+
+	return code, nil
+}
+
+// Converter loads the exchange rates once so every amount in a response can
+// be shown in the currency without a query per amount.
+func (cu *currencyUsecase) Converter(currency string) (*MoneyConverter, error) {
+	if currency == "" {
+		currency = cu.baseCurrency
+	}
+
+	rates, err := cu.currencyRepo.FindRates()
+	if err != nil {
+		return nil, err
+	}
+
+	converter := &MoneyConverter{
+		base:     cu.baseCurrency,
+		currency: currency,
+		rates:    map[string]float64{cu.baseCurrency: 1},
+	}
+	for _, rate := range rates {
+		converter.rates[rate.Currency] = rate.Rate
+	}
+
+	if _, ok := converter.rates[currency]; !ok {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	return converter, nil
+}
+
+func (cu *currencyUsecase) BackfillCurrencies() error {
+	return cu.currencyRepo.BackfillCurrency(cu.baseCurrency)
+}
+
+// MoneyConverter converts amounts into the currency a caller asked for,
+// going through the base currency. Amounts without a currency are taken to
+// be in the base currency; amounts in a currency that lost its rate are left
+// as they are.
+type MoneyConverter struct {
+	base     string
+	currency string
+	rates    map[string]float64
+}
+
+func (mc *MoneyConverter) Currency() string {
+	return mc.currency
+}
+
+// Convert converts the amount into the converter's currency.
+func (mc *MoneyConverter) Convert(money model.Money) model.Money {
+	return mc.convert(money, mc.currency)
+}
+
+// FromBase converts an amount in the base currency, such as a promotion or
+// coupon value, into the converter's currency.
+func (mc *MoneyConverter) FromBase(amount int64) model.Money {
+	return mc.convert(model.NewMoney(amount, mc.base), mc.currency)
+}
+
+// VariantPrice is the price of a variant in the converter's currency,
+// preferring a price fixed for that currency over converting the base price.
+func (mc *MoneyConverter) VariantPrice(variant model.BookVariant) model.Money {
+	for _, price := range variant.Prices {
+		if price.Currency == mc.currency {
+			return model.NewMoney(price.Amount, price.Currency)
+		}
+	}
+
+	return mc.Convert(variant.Price)
+}
+
+func (mc *MoneyConverter) convert(money model.Money, currency string) model.Money {
+	if money.Currency == "" {
+		money.Currency = mc.base
+	}
+
+	if money.Currency == currency {
+		return money
+	}
+
+	from, ok := mc.rates[money.Currency]
+	if !ok {
+		return money
+	}
+
+	return helper.ConvertMoney(money, currency, mc.rates[currency]/from)
+}
+
+func toExchangeRateResponse(rate model.ExchangeRate) dto.ExchangeRateResponse {
+	updatedAt := rate.UpdatedAt
+	return dto.ExchangeRateResponse{
+		Currency:  rate.Currency,
+		Rate:      rate.Rate,
+		UpdatedAt: &updatedAt,
+	}
+}
+
+func NewCurrencyUsecase(currencyRepo repository.CurrencyRepository, baseCurrency string) *currencyUsecase {
+	return &currencyUsecase{currencyRepo: currencyRepo, baseCurrency: baseCurrency}
+}
@@ -0,0 +1,527 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type ImportUsecase interface {
+	Start(userId int, format string, dryRun bool, file io.Reader) (*dto.ImportJobResponse, error)
+	GetById(id, userId int, role string) (*dto.ImportJobResponse, error)
+	GetErrors(id, userId int, role string) ([]model.ImportRowError, error)
+	FailUnfinished() error
+}
+
+var (
+	ErrImportNotFound    = errors.New("import job not found")
+	ErrImportFormat      = errors.New("import format must be csv or ndjson")
+	ErrImportTooLarge    = fmt.Errorf("import file must not be larger than %d MB", MaxImportSize>>20)
+	ErrInvalidImportFile = errors.New("invalid import file")
+)
+
+const (
+	MaxImportSize = 20 << 20
+
+	// importFlushEvery is how many rows are processed between progress
+	// updates written to the job.
+	importFlushEvery = 50
+
+	// maxRunningImports caps how many imports are processed at once so a
+	// burst of uploads does not starve regular requests of connections.
+	maxRunningImports = 2
+)
+
+// importColumns are the CSV headers understood by the importer. The format,
+// sku, edition, stock and page_count columns describe the single variant the
+// row creates; without a format the book gets the default paperback.
+var importColumns = []string{
+	"title", "isbn", "description", "author", "publisher_id", "price",
+	"category_id", "format", "sku", "edition", "stock", "page_count",
+}
+
+var importRequiredColumns = []string{"title", "description", "price", "category_id"}
+
+type importRow struct {
+	line   int
+	req    dto.CreateBookRequest
+	errors []dto.FieldError
+}
+
+type importUsecase struct {
+	importRepo  repository.ImportRepository
+	bookUsecase BookUsecase
+	slots       chan struct{}
+}
+
+// Start spools the upload to a temporary file, stores a queued job and
+// processes its rows in the background, reading them from the file one at a
+// time. Problems with the file as a whole are returned right away; problems
+// with single rows end up in the job's error report.
+func (iu *importUsecase) Start(userId int, format string, dryRun bool, file io.Reader) (*dto.ImportJobResponse, error) {
+	if format != model.ImportFormatCSV && format != model.ImportFormatNDJSON {
+		return nil, ErrImportFormat
+	}
+
+	spool, err := os.CreateTemp("", "book-import-*")
+	if err != nil {
+		return nil, err
+	}
+
+	started := false
+	defer func() {
+		if !started {
+			spool.Close()
+			os.Remove(spool.Name())
+		}
+	}()
+
+	size, err := io.Copy(spool, io.LimitReader(file, MaxImportSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if size > MaxImportSize {
+		return nil, ErrImportTooLarge
+	}
+
+	// The first pass only counts rows for the progress report and rejects
+	// files that cannot be read at all before a job is created.
+	totalRows := 0
+	err = readImportRows(spool, format, func(row importRow) error {
+		totalRows++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := iu.importRepo.CreateJob(&model.ImportJob{
+		UserID:    userId,
+		Format:    format,
+		DryRun:    dryRun,
+		Status:    model.ImportStatusQueued,
+		TotalRows: totalRows,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	started = true
+	go iu.run(*job, spool)
+
+	response := toImportJobResponse(*job)
+	return &response, nil
+}
+
+func (iu *importUsecase) GetById(id, userId int, role string) (*dto.ImportJobResponse, error) {
+	job, err := iu.findOwned(id, userId, role)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toImportJobResponse(*job)
+	return &response, nil
+}
+
+func (iu *importUsecase) GetErrors(id, userId int, role string) ([]model.ImportRowError, error) {
+	job, err := iu.findOwned(id, userId, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return iu.importRepo.FindRowErrors(job.ID)
+}
+
+// FailUnfinished is called on startup to close jobs that were cut off by a
+// restart.
+func (iu *importUsecase) FailUnfinished() error {
+	return iu.importRepo.FailUnfinished("import was interrupted by a server restart")
+}
+
+// findOwned loads a job the user is allowed to see: their own, or any job for
+// admins. Other users' jobs are reported as not found.
+func (iu *importUsecase) findOwned(id, userId int, role string) (*model.ImportJob, error) {
+	job, err := iu.importRepo.FindById(id)
+	if err != nil {
+		return nil, ErrImportNotFound
+	}
+
+	if role != "admin" && job.UserID != userId {
+		return nil, ErrImportNotFound
+	}
+
+	return job, nil
+}
+
+// run processes the rows of the spooled upload and removes the file when
+// done.
+func (iu *importUsecase) run(job model.ImportJob, spool *os.File) {
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	iu.slots <- struct{}{}
+	defer func() { <-iu.slots }()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("import job %d panicked: %v\n", job.ID, r)
+			iu.finish(&job, fmt.Sprintf("import stopped unexpectedly: %v", r))
+		}
+	}()
+
+	now := time.Now()
+	job.Status = model.ImportStatusRunning
+	job.StartedAt = &now
+	err := iu.importRepo.UpdateJob(&job)
+	if err != nil {
+		log.Printf("import job %d: %v\n", job.ID, err)
+	}
+
+	seenIsbn := make(map[string]int)
+	var pending []model.ImportRowError
+
+	err = readImportRows(spool, job.Format, func(row importRow) error {
+		fieldErrors := iu.processRow(row, job.DryRun, seenIsbn)
+
+		job.ProcessedRows++
+		if len(fieldErrors) == 0 {
+			job.SucceededRows++
+		} else {
+			job.FailedRows++
+			for _, fieldError := range fieldErrors {
+				pending = append(pending, model.ImportRowError{
+					JobID:   job.ID,
+					Row:     row.line,
+					Field:   fieldError.Field,
+					Rule:    fieldError.Rule,
+					Message: fieldError.Message,
+				})
+			}
+		}
+
+		if job.ProcessedRows%importFlushEvery == 0 {
+			err := iu.flush(&job, pending)
+			if err != nil {
+				return err
+			}
+			pending = nil
+		}
+
+		return nil
+	})
+	if err != nil {
+		iu.finish(&job, err.Error())
+		return
+	}
+
+	err = iu.importRepo.AddRowErrors(pending)
+	if err != nil {
+		iu.finish(&job, err.Error())
+		return
+	}
+
+	iu.finish(&job, "")
+}
+
+// processRow validates one row and, unless this is a dry run, creates the
+// book. ISBNs are deduplicated across the file as well as against the
+// catalog.
+func (iu *importUsecase) processRow(row importRow, dryRun bool, seenIsbn map[string]int) []dto.FieldError {
+	if len(row.errors) > 0 {
+		return row.errors
+	}
+
+	fieldErrors := helper.ValidateStruct(&row.req)
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+
+	if row.req.Isbn != "" {
+		isbn13, _ := helper.NormalizeISBN(row.req.Isbn)
+		if firstLine, ok := seenIsbn[isbn13]; ok {
+			return []dto.FieldError{{
+				Field:   "isbn",
+				Rule:    "unique",
+				Message: fmt.Sprintf("isbn duplicates the one on row %d", firstLine),
+			}}
+		}
+		seenIsbn[isbn13] = row.line
+	}
+
+	var err error
+	if dryRun {
+		err = iu.bookUsecase.ValidateCreate(row.req)
+	} else {
+		_, err = iu.bookUsecase.Create(row.req)
+	}
+	if err != nil {
+		return []dto.FieldError{importFieldError(err)}
+	}
+
+	return nil
+}
+
+func (iu *importUsecase) flush(job *model.ImportJob, pending []model.ImportRowError) error {
+	err := iu.importRepo.AddRowErrors(pending)
+	if err != nil {
+		return err
+	}
+
+	return iu.importRepo.UpdateJob(job)
+}
+
+// finish closes the job, as failed when message is set.
+func (iu *importUsecase) finish(job *model.ImportJob, message string) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = model.ImportStatusCompleted
+	if message != "" {
+		job.Status = model.ImportStatusFailed
+		job.Error = message
+	}
+
+	err := iu.importRepo.UpdateJob(job)
+	if err != nil {
+		log.Printf("import job %d: failed to save final status: %v\n", job.ID, err)
+	}
+}
+
+// importFieldError turns an error from the book usecase into a row error
+// pointing at the column that caused it.
+func importFieldError(err error) dto.FieldError {
+	switch {
+	case errors.Is(err, ErrCategoryNotFound):
+		return dto.FieldError{Field: "category_id", Rule: "exists", Message: err.Error()}
+	case errors.Is(err, ErrPublisherNotFound):
+		return dto.FieldError{Field: "publisher_id", Rule: "exists", Message: err.Error()}
+	case errors.Is(err, ErrAuthorNotFound):
+		return dto.FieldError{Field: "authors", Rule: "exists", Message: err.Error()}
+	case errors.Is(err, ErrAuthorName):
+		return dto.FieldError{Field: "author", Rule: "author_name", Message: err.Error()}
+	case errors.Is(err, ErrInvalidIsbn):
+		return dto.FieldError{Field: "isbn", Rule: "isbn_any", Message: err.Error()}
+	case errors.Is(err, ErrDuplicateIsbn):
+		return dto.FieldError{Field: "isbn", Rule: "unique", Message: err.Error()}
+	case errors.Is(err, ErrDuplicateSku):
+		return dto.FieldError{Field: "sku", Rule: "unique", Message: err.Error()}
+	case errors.Is(err, ErrVariantPrices):
+		return dto.FieldError{Field: "variants", Rule: "prices", Message: err.Error()}
+	default:
+		return dto.FieldError{Rule: "error", Message: err.Error()}
+	}
+}
+
+// readImportRows reads the spooled upload from the start and hands each row
+// to fn, stopping at the first error fn returns.
+func readImportRows(spool *os.File, format string, fn func(row importRow) error) error {
+	_, err := spool.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(spool)
+	if format == model.ImportFormatNDJSON {
+		return parseImportNDJSON(reader, fn)
+	}
+
+	return parseImportCSV(reader, fn)
+}
+
+func parseImportCSV(r *bufio.Reader, fn func(row importRow) error) error {
+	bom, err := r.Peek(3)
+	if err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		r.Discard(3)
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: file is empty", ErrInvalidImportFile)
+	} else if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("%w: missing column %q, expected columns are %s", ErrInvalidImportFile, name, strings.Join(importColumns, ", "))
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		var row importRow
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row = importRow{
+				line:   parseErr.StartLine,
+				errors: []dto.FieldError{{Rule: "csv", Message: parseErr.Err.Error()}},
+			}
+		} else if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		} else {
+			line, _ := reader.FieldPos(0)
+			row = csvImportRow(line, record, columns)
+		}
+
+		err = fn(row)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func csvImportRow(line int, record []string, columns map[string]int) importRow {
+	row := importRow{line: line}
+
+	value := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	number := func(name string) int {
+		raw := value(name)
+		if raw == "" {
+			return 0
+		}
+
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			row.errors = append(row.errors, dto.FieldError{
+				Field:   name,
+				Rule:    "number",
+				Message: fmt.Sprintf("%s must be a whole number", name),
+			})
+		}
+		return n
+	}
+
+	row.req = dto.CreateBookRequest{
+		Title:       value("title"),
+		Isbn:        value("isbn"),
+		Description: value("description"),
+		Author:      value("author"),
+		Price:       int64(number("price")),
+		CategoryID:  number("category_id"),
+	}
+
+	if value("publisher_id") != "" {
+		publisherId := number("publisher_id")
+		row.req.PublisherID = &publisherId
+	}
+
+	if format := strings.ToLower(value("format")); format != "" {
+		row.req.Variants = []dto.CreateVariantRequest{{
+			Sku:       value("sku"),
+			Format:    format,
+			Edition:   value("edition"),
+			Price:     row.req.Price,
+			Stock:     number("stock"),
+			PageCount: number("page_count"),
+		}}
+	}
+
+	return row
+}
+
+// parseImportNDJSON reads one JSON encoded dto.CreateBookRequest per line.
+// Blank lines are skipped.
+func parseImportNDJSON(r *bufio.Reader, fn func(row importRow) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxImportSize)
+
+	rows := 0
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		row := importRow{line: line}
+		err := json.Unmarshal(text, &row.req)
+		if err != nil {
+			row.errors = []dto.FieldError{{Rule: "json", Message: err.Error()}}
+		}
+
+		rows++
+		err = fn(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("%w: file is empty", ErrInvalidImportFile)
+	}
+
+	return nil
+}
+
+func toImportJobResponse(job model.ImportJob) dto.ImportJobResponse {
+	progress := 0.0
+	if job.TotalRows > 0 {
+		progress = math.Round(float64(job.ProcessedRows)/float64(job.TotalRows)*1000) / 10
+	} else if job.Status == model.ImportStatusCompleted {
+		progress = 100
+	}
+
+	return dto.ImportJobResponse{
+		ID:            job.ID,
+		Status:        job.Status,
+		Format:        job.Format,
+		DryRun:        job.DryRun,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		SucceededRows: job.SucceededRows,
+		FailedRows:    job.FailedRows,
+		Progress:      progress,
+		Error:         job.Error,
+		StartedAt:     job.StartedAt,
+		FinishedAt:    job.FinishedAt,
+		CreatedAt:     job.CreatedAt,
+	}
+}
+
+func NewImportUsecase(importRepo repository.ImportRepository, bookUsecase BookUsecase) *importUsecase {
+	return &importUsecase{
+		importRepo:  importRepo,
+		bookUsecase: bookUsecase,
+		slots:       make(chan struct{}, maxRunningImports),
+	}
+}
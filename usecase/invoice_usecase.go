@@ -0,0 +1,433 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+	"github.com/mhmmmdrivaldhi/go-book-api/service"
+)
+
+type InvoiceUsecase interface {
+	IssueInvoice(orderId int) (*model.Invoice, error)
+	IssueCreditNote(refund *model.Refund) (*model.Invoice, error)
+	GetByOrder(orderId, userId int, role string) ([]model.Invoice, error)
+	GetOrderInvoicePDF(orderId, userId int, role string) (*model.Invoice, []byte, error)
+	GetPDF(id, userId int, role string) (*model.Invoice, []byte, error)
+}
+
+var (
+	ErrInvoiceNotFound  = errors.New("invoice not found")
+	ErrOrderNotInvoiced = errors.New("an order is only invoiced once it is paid")
+)
+
+type invoiceUsecase struct {
+	invoiceRepo repository.InvoiceRepository
+	orderRepo   repository.OrderRepository
+	userRepo    repository.UserRepository
+	bookRepo    repository.BookRepository
+	store       service.InvoiceStore
+	seller      []string
+}
+
+// IssueInvoice issues the invoice of a paid order, or returns the one it
+// already has.
+func (iu *invoiceUsecase) IssueInvoice(orderId int) (*model.Invoice, error) {
+	order, err := iu.orderRepo.FindById(orderId)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	return iu.issueInvoice(order)
+}
+
+// IssueCreditNote documents a refund that succeeded, against the invoice of
+// its order. The tax in the refund is taken in the same proportion as each
+// of the order's taxes to its total.
+func (iu *invoiceUsecase) IssueCreditNote(refund *model.Refund) (*model.Invoice, error) {
+	order, err := iu.orderRepo.FindById(refund.OrderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	invoice, err := iu.issueInvoice(order)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &model.Invoice{
+		Kind:      model.InvoiceKindCreditNote,
+		OrderID:   order.ID,
+		RefundID:  &refund.ID,
+		InvoiceID: &invoice.ID,
+		Currency:  refund.Currency,
+		Total:     refund.Amount,
+		Lines:     creditNoteLines(refund, order),
+		IssuedAt:  time.Now(),
+	}
+	iu.copyBuyer(note, order)
+
+	for _, line := range note.Lines {
+		if line.Kind == model.InvoiceLineTax {
+			note.TotalTax += line.Amount
+		}
+	}
+
+	err = iu.invoiceRepo.CreateInvoice(note)
+	if errors.Is(err, repository.ErrInvoiceExists) {
+		return iu.invoiceRepo.FindByRefund(refund.ID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	iu.render(note)
+
+	return note, nil
+}
+
+// GetByOrder lists the invoice and credit notes of one of the user's orders.
+// Admins can list those of any order. Documents that should exist but were
+// not issued, because issuing failed after the payment or refund went
+// through, are issued now.
+func (iu *invoiceUsecase) GetByOrder(orderId, userId int, role string) ([]model.Invoice, error) {
+	order, err := iu.orderRepo.FindById(orderId)
+	if err != nil || (order.UserID != userId && role != "admin") {
+		return nil, ErrOrderNotFound
+	}
+
+	if order.PaidAt == nil {
+		return []model.Invoice{}, nil
+	}
+
+	invoices, err := iu.invoiceRepo.FindByOrder(orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := map[int]bool{}
+	hasInvoice := false
+	for _, invoice := range invoices {
+		if invoice.RefundID != nil {
+			issued[*invoice.RefundID] = true
+		}
+		if invoice.Kind == model.InvoiceKindInvoice {
+			hasInvoice = true
+		}
+	}
+
+	missing := !hasInvoice
+	if missing {
+		_, err = iu.issueInvoice(order)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range order.Refunds {
+		refund := &order.Refunds[i]
+		if refund.Status != model.RefundStatusSucceeded || issued[refund.ID] {
+			continue
+		}
+
+		missing = true
+		_, err = iu.IssueCreditNote(refund)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if missing {
+		return iu.invoiceRepo.FindByOrder(orderId)
+	}
+
+	return invoices, nil
+}
+
+// GetOrderInvoicePDF returns the invoice of one of the user's paid orders
+// with its PDF, issuing it first when needed.
+func (iu *invoiceUsecase) GetOrderInvoicePDF(orderId, userId int, role string) (*model.Invoice, []byte, error) {
+	order, err := iu.orderRepo.FindById(orderId)
+	if err != nil || (order.UserID != userId && role != "admin") {
+		return nil, nil, ErrOrderNotFound
+	}
+
+	invoice, err := iu.issueInvoice(order)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := iu.pdf(invoice)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return invoice, data, nil
+}
+
+// GetPDF returns an invoice or credit note of one of the user's orders with
+// its PDF.
+func (iu *invoiceUsecase) GetPDF(id, userId int, role string) (*model.Invoice, []byte, error) {
+	invoice, err := iu.invoiceRepo.FindById(id)
+	if err != nil {
+		return nil, nil, ErrInvoiceNotFound
+	}
+
+	order, err := iu.orderRepo.FindById(invoice.OrderID)
+	if err != nil || (order.UserID != userId && role != "admin") {
+		return nil, nil, ErrInvoiceNotFound
+	}
+
+	data, err := iu.pdf(invoice)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return invoice, data, nil
+}
+
+func (iu *invoiceUsecase) issueInvoice(order *model.Order) (*model.Invoice, error) {
+	if order.PaidAt == nil {
+		return nil, ErrOrderNotInvoiced
+	}
+
+	invoice := &model.Invoice{
+		Kind:          model.InvoiceKindInvoice,
+		OrderID:       order.ID,
+		Currency:      order.Currency,
+		Subtotal:      order.Subtotal,
+		TotalDiscount: order.TotalDiscount,
+		ShippingTotal: order.ShippingTotal,
+		TotalTax:      order.TotalTax,
+		Total:         order.TotalPrice,
+		Lines:         iu.invoiceLines(order),
+		IssuedAt:      time.Now(),
+	}
+	iu.copyBuyer(invoice, order)
+
+	err := iu.invoiceRepo.CreateInvoice(invoice)
+	if errors.Is(err, repository.ErrInvoiceExists) {
+		return iu.invoiceRepo.FindInvoiceByOrder(order.ID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	iu.render(invoice)
+
+	return invoice, nil
+}
+
+// pdf reads the stored PDF of the invoice, rendering it again when the file
+// is missing.
+func (iu *invoiceUsecase) pdf(invoice *model.Invoice) ([]byte, error) {
+	data, err := iu.store.Load(invoiceKey(invoice))
+	if err == nil {
+		return data, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return iu.render(invoice), nil
+}
+
+// render lays out the PDF of the invoice from what was copied onto it when
+// it was issued, and stores it. A PDF that cannot be stored is still
+// returned; it is rendered again on the next download.
+func (iu *invoiceUsecase) render(invoice *model.Invoice) []byte {
+	doc := helper.InvoiceDocument{
+		Invoice: *invoice,
+		Seller:  iu.seller,
+		Buyer:   invoiceBuyer(invoice),
+	}
+
+	for _, line := range invoice.Lines {
+		printed := helper.InvoiceLine{
+			Description: line.Description,
+			Qty:         line.Qty,
+			UnitPrice:   line.UnitPrice,
+			Amount:      line.Amount,
+		}
+
+		if line.Kind == model.InvoiceLineTax {
+			doc.TaxLines = append(doc.TaxLines, printed)
+		} else {
+			doc.Lines = append(doc.Lines, printed)
+		}
+	}
+
+	if invoice.InvoiceID != nil {
+		original, err := iu.invoiceRepo.FindById(*invoice.InvoiceID)
+		if err == nil {
+			doc.Corrects = original.Number
+		}
+	}
+
+	data := helper.RenderInvoice(doc)
+
+	err := iu.store.Save(invoiceKey(invoice), data)
+	if err != nil {
+		log.Printf("failed to store invoice %s: %v\n", invoice.Number, err)
+	}
+
+	return data
+}
+
+// copyBuyer copies the customer's name and email, and the address the order
+// ships to, onto the invoice.
+func (iu *invoiceUsecase) copyBuyer(invoice *model.Invoice, order *model.Order) {
+	user, err := iu.userRepo.FindById(order.UserID)
+	if err == nil {
+		invoice.BuyerName = user.Name
+		invoice.BuyerEmail = user.Email
+	}
+
+	invoice.BuyerAddress = order.ShippingAddress
+}
+
+// invoiceLines lists the books on the order by their title when it is
+// issued, followed by the taxes charged.
+func (iu *invoiceUsecase) invoiceLines(order *model.Order) []model.InvoiceLine {
+	bookIds := make([]int, 0, len(order.Items))
+	for _, item := range order.Items {
+		bookIds = append(bookIds, item.BookID)
+	}
+
+	titles := map[int]string{}
+	books, err := iu.bookRepo.FindByIds(bookIds)
+	if err == nil {
+		for _, book := range books {
+			titles[book.Id] = book.Title
+		}
+	}
+
+	lines := make([]model.InvoiceLine, 0, len(order.Items))
+	for _, item := range order.Items {
+		description := item.Sku
+		if title, ok := titles[item.BookID]; ok {
+			description = fmt.Sprintf("%s (%s)", title, item.Format)
+		}
+
+		lines = append(lines, model.InvoiceLine{
+			Kind:        model.InvoiceLineItem,
+			Description: description,
+			Qty:         item.Qty,
+			UnitPrice:   item.Price,
+			Amount:      item.Price * int64(item.Qty),
+		})
+	}
+
+	return append(lines, taxLines(order.Taxes)...)
+}
+
+// creditNoteLines is the single refunded amount with the reason it was
+// refunded for, followed by the share of each of the order's taxes in it.
+func creditNoteLines(refund *model.Refund, order *model.Order) []model.InvoiceLine {
+	description := fmt.Sprintf("Refund on order #%d", order.ID)
+	if refund.Reason != "" {
+		description = "Refund: " + refund.Reason
+	}
+
+	lines := []model.InvoiceLine{{
+		Kind:        model.InvoiceLineItem,
+		Description: description,
+		Qty:         1,
+		UnitPrice:   refund.Amount,
+		Amount:      refund.Amount,
+	}}
+
+	if order.TotalPrice <= 0 {
+		return lines
+	}
+
+	for _, line := range taxLines(order.Taxes) {
+		line.Amount = (line.Amount*refund.Amount + order.TotalPrice/2) / order.TotalPrice
+		if line.Amount > 0 {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// taxLines groups the taxes of an order by tax and rate, marking those that
+// were included in the prices.
+func taxLines(taxes []model.OrderTax) []model.InvoiceLine {
+	var lines []model.InvoiceLine
+	index := map[string]int{}
+	for _, tax := range taxes {
+		label := fmt.Sprintf("%s %s%%", tax.Name, strconv.FormatFloat(tax.Rate, 'f', -1, 64))
+		if tax.Inclusive {
+			label += " (included)"
+		}
+
+		i, ok := index[label]
+		if !ok {
+			i = len(lines)
+			index[label] = i
+			lines = append(lines, model.InvoiceLine{Kind: model.InvoiceLineTax, Description: label})
+		}
+		lines[i].Amount += tax.Amount
+	}
+
+	return lines
+}
+
+// invoiceBuyer is the customer's name and email, followed by the address the
+// order ships to, if any.
+func invoiceBuyer(invoice *model.Invoice) []string {
+	lines := []string{invoice.BuyerName, invoice.BuyerEmail}
+
+	address := invoice.BuyerAddress
+	if address.Country != "" {
+		lines = append(lines,
+			address.RecipientName,
+			address.Line1,
+			address.Line2,
+			strings.TrimSpace(address.PostalCode+" "+address.City),
+			address.Region,
+			address.Country,
+		)
+	}
+
+	return compactLines(lines)
+}
+
+func invoiceKey(invoice *model.Invoice) string {
+	return fmt.Sprintf("%d/%s.pdf", invoice.Year, invoice.Number)
+}
+
+func compactLines(lines []string) []string {
+	compact := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			compact = append(compact, line)
+		}
+	}
+
+	return compact
+}
+
+// NewInvoiceUsecase takes the seller's details as they are printed on every
+// invoice; blank ones are left out.
+func NewInvoiceUsecase(invoiceRepo repository.InvoiceRepository, orderRepo repository.OrderRepository, userRepo repository.UserRepository, bookRepo repository.BookRepository, store service.InvoiceStore, sellerName, sellerAddress, sellerTaxId string) *invoiceUsecase {
+	seller := []string{sellerName, sellerAddress}
+	if sellerTaxId != "" {
+		seller = append(seller, "Tax ID "+sellerTaxId)
+	}
+
+	return &invoiceUsecase{
+		invoiceRepo: invoiceRepo,
+		orderRepo:   orderRepo,
+		userRepo:    userRepo,
+		bookRepo:    bookRepo,
+		store:       store,
+		seller:      compactLines(seller),
+	}
+}
@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type OrderUsecase interface {
+	Checkout(ctx context.Context, userId int, currency string) (*model.Order, error)
+	GetByUser(userId int, filter dto.OrderFilterRequest) ([]model.Order, *dto.Paging, error)
+	GetById(id, userId int) (*model.Order, error)
+	MarkPaid(id int) (*model.Order, error)
+	Cancel(id, userId int, role string) (*model.Order, error)
+	ExpirePending() error
+}
+
+var (
+	ErrOrderNotFound           = errors.New("order not found")
+	ErrCartEmpty               = errors.New("cart is empty")
+	ErrCouponRejected          = errors.New("the coupon on your cart can no longer be used")
+	ErrShippingAddressRequired = errors.New("choose a shipping address for the physical items in your cart")
+	ErrShippingMethodRequired  = errors.New("choose a shipping method for the physical items in your cart")
+	ErrOrderNotPending         = errors.New("order is not awaiting payment")
+	ErrCartChanged             = errors.New("prices or availability of items in your cart changed; review your cart before checking out")
+)
+
+type orderUsecase struct {
+	orderRepo      repository.OrderRepository
+	cartRepo       repository.CartRepository
+	cartUsecase    CartUsecase
+	pricingUsecase PricingUsecase
+	addressUsecase AddressUsecase
+	invoiceUsecase InvoiceUsecase
+	pendingTTL     time.Duration
+}
+
+// Checkout turns the user's cart into a pending order. The cart is priced
+// once more and the resulting discount lines are stored on the order, so
+// later changes to a coupon do not affect orders already placed. A coupon
+// that stopped qualifying fails the checkout rather than silently charging
+// the full price. The order is charged in the currency the cart is priced in.
+// Carts with physical items need an address, the user's default one when
+// the cart has none, and a shipping method that can still ship them; both
+// are copied onto the order. A cart with lines that changed price or were
+// taken out since the customer last reviewed it is not checked out, so the
+// customer is never charged a price they have not seen.
+func (ou *orderUsecase) Checkout(ctx context.Context, userId int, currency string) (*model.Order, error) {
+	cart, err := ou.cartUsecase.GetCartFromUser(ctx, model.CartOwner{UserId: userId}, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if cart.NeedsReview() {
+		return nil, ErrCartChanged
+	}
+
+	if len(cart.Items) == 0 {
+		return nil, ErrCartEmpty
+	}
+
+	address, err := ou.shippingAddress(userId, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	coupon, err := ou.pricingUsecase.Reprice(userId, cart, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if cart.CouponError != "" {
+		return nil, fmt.Errorf("%w: %s", ErrCouponRejected, cart.CouponError)
+	}
+
+	var shippingMethod *model.ShippingQuote
+	if address != nil {
+		if cart.ShippingMethodId == 0 {
+			return nil, ErrShippingMethodRequired
+		}
+
+		if cart.ShippingError != "" {
+			return nil, ErrShippingMethodUnavailable
+		}
+
+		for i, quote := range cart.ShippingQuotes {
+			if quote.MethodId == cart.ShippingMethodId {
+				shippingMethod = &cart.ShippingQuotes[i]
+			}
+		}
+	}
+
+	order := &model.Order{
+		UserID:        userId,
+		Status:        model.OrderStatusPending,
+		Currency:      cart.Currency,
+		Subtotal:      cart.TotalPrice.Amount,
+		TotalDiscount: cart.TotalDiscount.Amount,
+		TotalTax:      cart.TotalTax.Amount,
+		ShippingTotal: cart.Shipping.Amount,
+		Country:       cart.Country,
+		Region:        cart.Region,
+		TotalPrice:    cart.GrandTotal.Amount,
+		CouponCode:    cart.CouponCode,
+	}
+
+	if shippingMethod != nil {
+		order.ShippingMethodID = &shippingMethod.MethodId
+		order.ShippingMethodName = shippingMethod.Name
+		order.ShippingAddress = address.PostalAddress
+	}
+
+	for _, item := range cart.Items {
+		order.Items = append(order.Items, model.OrderItem{
+			VariantID: item.VariantId,
+			BookID:    item.BookId,
+			Sku:       item.Sku,
+			Format:    item.Format,
+			Price:     item.Price.Amount,
+			Qty:       item.Qty,
+		})
+	}
+
+	for _, tax := range cart.Taxes {
+		order.Taxes = append(order.Taxes, model.OrderTax{
+			VariantID: tax.VariantId,
+			Name:      tax.Name,
+			Rate:      tax.Rate,
+			Inclusive: tax.Inclusive,
+			Taxable:   tax.Taxable.Amount,
+			Amount:    tax.Amount.Amount,
+		})
+	}
+
+	var claim *repository.CouponClaim
+	for _, discount := range cart.Discounts {
+		orderDiscount := model.OrderDiscount{
+			Source:      discount.Source,
+			Code:        discount.Code,
+			Label:       discount.Label,
+			Explanation: discount.Explanation,
+			Amount:      discount.Amount.Amount,
+		}
+		if discount.PromotionId != 0 {
+			promotionId := discount.PromotionId
+			orderDiscount.PromotionID = &promotionId
+		}
+		order.Discounts = append(order.Discounts, orderDiscount)
+
+		if coupon != nil && discount.Source == model.DiscountSourceCoupon {
+			claim = &repository.CouponClaim{CouponID: coupon.ID, Discount: discount.Amount.Amount}
+		}
+	}
+
+	create, err := ou.orderRepo.CreateOrder(order, claim)
+	if errors.Is(err, repository.ErrOutOfStock) {
+		return nil, ErrInsufficientStock
+	} else if errors.Is(err, repository.ErrCouponUsedUp) {
+		return nil, fmt.Errorf("%w: %s", ErrCouponRejected, ErrCouponUsedUp.Error())
+	} else if err != nil {
+		return nil, err
+	}
+
+	// the order is placed either way; a stale cart only costs the user a
+	// manual clear
+	err = ou.removeOrderedItems(ctx, userId, create, currency)
+	if err != nil {
+		log.Printf("failed to clear cart of user %d after order %d: %v\n", userId, create.ID, err)
+	}
+
+	return create, nil
+}
+
+// removeOrderedItems takes the copies that were ordered out of the cart, and
+// the coupon redeemed with them. The cart is changed through UpdateCart, so
+// copies added by another request while the order was placed stay in it.
+func (ou *orderUsecase) removeOrderedItems(ctx context.Context, userId int, order *model.Order, currency string) error {
+	ordered := map[int]int{}
+	for _, item := range order.Items {
+		ordered[item.VariantID] += item.Qty
+	}
+
+	_, err := ou.cartRepo.UpdateCart(ctx, model.CartOwner{UserId: userId}, func(cart *model.Cart) error {
+		items := make([]model.Item, 0, len(cart.Items))
+		for _, item := range cart.Items {
+			item.Qty -= ordered[item.VariantId]
+			if item.Qty > 0 {
+				items = append(items, item)
+			}
+		}
+		cart.Items = items
+
+		if cart.CouponCode == order.CouponCode {
+			cart.CouponCode = ""
+		}
+
+		_, err := ou.pricingUsecase.Reprice(userId, cart, currency)
+		return err
+	})
+
+	return err
+}
+
+// shippingAddress finds the address a cart with physical items ships to and
+// moves the cart's tax location there, in case the address was edited after
+// it was chosen. Carts with nothing to ship need no address.
+func (ou *orderUsecase) shippingAddress(userId int, cart *model.Cart) (*model.Address, error) {
+	physical := false
+	for _, item := range cart.Items {
+		physical = physical || item.NeedsShipping()
+	}
+
+	if !physical {
+		return nil, nil
+	}
+
+	var address *model.Address
+	var err error
+	if cart.AddressId != 0 {
+		address, err = ou.addressUsecase.GetById(cart.AddressId, userId)
+	} else {
+		address, err = ou.addressUsecase.GetDefault(userId)
+	}
+	if errors.Is(err, ErrAddressNotFound) {
+		return nil, ErrShippingAddressRequired
+	} else if err != nil {
+		return nil, err
+	}
+
+	cart.AddressId = address.ID
+	cart.Country = address.Country
+	cart.Region = address.Region
+
+	return address, nil
+}
+
+func (ou *orderUsecase) GetByUser(userId int, filter dto.OrderFilterRequest) ([]model.Order, *dto.Paging, error) {
+	paging := &dto.Paging{Page: filter.Page, Limit: filter.Limit}
+	if paging.Page == 0 {
+		paging.Page = 1
+	}
+	if paging.Limit == 0 {
+		paging.Limit = defaultPageLimit
+	}
+
+	orders, total, err := ou.orderRepo.FindByUser(userId, paging.Limit, (paging.Page-1)*paging.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paging.TotalRows = total
+	paging.TotalPages = int((total + int64(paging.Limit) - 1) / int64(paging.Limit))
+
+	return orders, paging, nil
+}
+
+func (ou *orderUsecase) GetById(id, userId int) (*model.Order, error) {
+	order, err := ou.orderRepo.FindById(id)
+	if err != nil || order.UserID != userId {
+		return nil, ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// MarkPaid confirms payment of a pending order, which puts any physical
+// items on it in the fulfillment queue and issues its invoice. An invoice
+// that fails to issue does not undo the payment; it is issued when the
+// order's invoices are next asked for.
+func (ou *orderUsecase) MarkPaid(id int) (*model.Order, error) {
+	_, err := ou.orderRepo.FindById(id)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	marked, err := ou.orderRepo.MarkPaid(id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if !marked {
+		return nil, ErrOrderNotPending
+	}
+
+	_, err = ou.invoiceUsecase.IssueInvoice(id)
+	if err != nil {
+		log.Printf("failed to issue invoice of order %d: %v\n", id, err)
+	}
+
+	return ou.orderRepo.FindById(id)
+}
+
+// Cancel cancels an order still awaiting payment, giving its stock back. Users
+// can cancel their own orders and admins any order.
+func (ou *orderUsecase) Cancel(id, userId int, role string) (*model.Order, error) {
+	order, err := ou.orderRepo.FindById(id)
+	if err != nil || (role != "admin" && order.UserID != userId) {
+		return nil, ErrOrderNotFound
+	}
+
+	cancelled, err := ou.orderRepo.CancelOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cancelled {
+		return nil, ErrOrderNotPending
+	}
+
+	return ou.orderRepo.FindById(id)
+}
+
+// ExpirePending cancels orders that were not paid within the pending TTL, so
+// the stock and coupon redemptions they hold become available again. An
+// order that fails to cancel is logged and tried again on the next run.
+func (ou *orderUsecase) ExpirePending() error {
+	ids, err := ou.orderRepo.FindPendingBefore(time.Now().Add(-ou.pendingTTL))
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		_, err = ou.orderRepo.CancelOrder(id)
+		if err != nil {
+			log.Printf("failed to expire order %d: %v\n", id, err)
+		}
+	}
+
+	return nil
+}
+
+func NewOrderUsecase(orderRepo repository.OrderRepository, cartRepo repository.CartRepository, cartUsecase CartUsecase, pricingUsecase PricingUsecase, addressUsecase AddressUsecase, invoiceUsecase InvoiceUsecase, pendingTTL time.Duration) *orderUsecase {
+	return &orderUsecase{
+		orderRepo:      orderRepo,
+		cartRepo:       cartRepo,
+		cartUsecase:    cartUsecase,
+		pricingUsecase: pricingUsecase,
+		addressUsecase: addressUsecase,
+		invoiceUsecase: invoiceUsecase,
+		pendingTTL:     pendingTTL,
+	}
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type PricingUsecase interface {
+	CheckCoupon(userId int, code string, cart *model.Cart, currency string) error
+	Reprice(userId int, cart *model.Cart, currency string) (*model.Coupon, error)
+}
+
+type pricingUsecase struct {
+	variantRepo      repository.VariantRepository
+	currencyUsecase  CurrencyUsecase
+	promotionUsecase PromotionUsecase
+	taxUsecase       TaxUsecase
+	shippingUsecase  ShippingUsecase
+}
+
+// CheckCoupon reports why the coupon with the given code cannot be applied
+// to the cart, or nil when it gives a discount.
+func (pu *pricingUsecase) CheckCoupon(userId int, code string, cart *model.Cart, currency string) error {
+	priced := *cart
+	priced.Items = append([]model.Item(nil), cart.Items...)
+	priced.CouponCode = normalizeCouponCode(code)
+
+	_, couponErr, err := pu.price(userId, &priced, currency)
+	if err != nil {
+		return err
+	}
+
+	return couponErr
+}
+
+// Reprice recalculates the totals and discount lines of the cart in the
+// currency and returns the coupon that was applied, if any. A coupon that
+// no longer qualifies is kept on the cart with the reason in CouponError
+// instead of failing the mutation that caused it.
+func (pu *pricingUsecase) Reprice(userId int, cart *model.Cart, currency string) (*model.Coupon, error) {
+	coupon, couponErr, err := pu.price(userId, cart, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if couponErr != nil {
+		cart.CouponError = couponErr.Error()
+	}
+
+	return coupon, nil
+}
+
+// price works out every cart total. The promotions and the coupon are taken
+// off first, tax is then worked out on what is left to pay per item, and
+// shipping is quoted last on the discounted total.
+func (pu *pricingUsecase) price(userId int, cart *model.Cart, currency string) (*model.Coupon, error, error) {
+	converter, err := pu.currencyUsecase.Converter(currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pu.localizeItems(cart, converter)
+
+	cart.TotalQty = helper.CalculateTotalQty(cart)
+	cart.TotalPrice = helper.CalculateTotalPrice(cart)
+	cart.Discounts = []model.Discount{}
+	cart.PromotionHints = nil
+	cart.CouponError = ""
+	cart.TotalDiscount = model.NewMoney(0, cart.Currency)
+	cart.Taxes = []model.TaxLine{}
+	cart.TotalTax = model.NewMoney(0, cart.Currency)
+	cart.ShippingQuotes = []model.ShippingQuote{}
+	cart.ShippingError = ""
+	cart.Shipping = model.NewMoney(0, cart.Currency)
+	cart.GrandTotal = cart.TotalPrice
+
+	if cart.Country == "" {
+		cart.Country = pu.taxUsecase.DefaultCountry()
+	}
+
+	if len(cart.Items) == 0 && cart.CouponCode == "" {
+		return nil, nil, nil
+	}
+
+	discounts, err := pu.promotionUsecase.Discounts(userId, cart.Items, cart.CouponCode, converter)
+	if err != nil {
+		return nil, nil, err
+	}
+	cart.Discounts = append(cart.Discounts, discounts.Discounts...)
+	cart.PromotionHints = discounts.Hints
+
+	cart.Taxes, err = pu.taxUsecase.Taxes(cart.Country, cart.Region, cart.Items, discounts.Remaining, cart.Currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addedTax := model.NewMoney(0, cart.Currency)
+	for _, tax := range cart.Taxes {
+		cart.TotalTax = cart.TotalTax.Add(tax.Amount)
+		if !tax.Inclusive {
+			addedTax = addedTax.Add(tax.Amount)
+		}
+	}
+
+	for _, discount := range cart.Discounts {
+		cart.TotalDiscount = cart.TotalDiscount.Add(discount.Amount)
+	}
+
+	err = pu.quoteShipping(cart, converter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cart.GrandTotal = cart.TotalPrice.Sub(cart.TotalDiscount).Add(addedTax).Add(cart.Shipping)
+
+	return discounts.Coupon, discounts.CouponErr, nil
+}
+
+// quoteShipping lists what each shipping method would charge for the cart
+// and charges the chosen one. Free shipping thresholds are checked against
+// what the items come to after discounts, before tax.
+func (pu *pricingUsecase) quoteShipping(cart *model.Cart, converter *MoneyConverter) error {
+	quotes, err := pu.shippingUsecase.Quotes(cart.Items, cart.Country, cart.TotalPrice.Sub(cart.TotalDiscount), converter)
+	if err != nil {
+		return err
+	}
+	cart.ShippingQuotes = quotes
+
+	if cart.ShippingMethodId == 0 {
+		return nil
+	}
+
+	for _, quote := range quotes {
+		if quote.MethodId == cart.ShippingMethodId {
+			cart.Shipping = quote.Amount
+			return nil
+		}
+	}
+
+	cart.ShippingError = ErrShippingMethodUnavailable.Error()
+	return nil
+}
+
+// localizeItems moves the cart into the converter's currency. Items priced
+// in another currency, or stored before prices carried one, are priced again
+// from their variant; items whose variant is gone are converted as they are.
+func (pu *pricingUsecase) localizeItems(cart *model.Cart, converter *MoneyConverter) {
+	cart.Currency = converter.Currency()
+
+	for i, item := range cart.Items {
+		if item.Price.Currency == cart.Currency {
+			continue
+		}
+
+		variant, err := pu.variantRepo.FindById(item.VariantId)
+		if err != nil {
+			cart.Items[i].Price = converter.Convert(item.Price)
+			continue
+		}
+
+		cart.Items[i].Price = converter.VariantPrice(*variant)
+	}
+}
+
+func NewPricingUsecase(variantRepo repository.VariantRepository, currencyUsecase CurrencyUsecase, promotionUsecase PromotionUsecase, taxUsecase TaxUsecase, shippingUsecase ShippingUsecase) *pricingUsecase {
+	return &pricingUsecase{
+		variantRepo:      variantRepo,
+		currencyUsecase:  currencyUsecase,
+		promotionUsecase: promotionUsecase,
+		taxUsecase:       taxUsecase,
+		shippingUsecase:  shippingUsecase,
+	}
+}
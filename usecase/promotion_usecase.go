@@ -0,0 +1,836 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type PromotionUsecase interface {
+	CreateCoupon(req dto.CreateCouponRequest) (*dto.CouponResponse, error)
+	GetCoupons(filter dto.CouponFilterRequest) ([]dto.CouponResponse, *dto.Paging, error)
+	GetCouponById(id int) (*dto.CouponResponse, error)
+	UpdateCoupon(id int, req dto.UpdateCouponRequest) (*dto.CouponResponse, error)
+	DeleteCoupon(id int) error
+	CreatePromotion(req dto.CreatePromotionRequest) (*dto.PromotionResponse, error)
+	GetPromotions(filter dto.PromotionFilterRequest) ([]dto.PromotionResponse, *dto.Paging, error)
+	GetPromotionById(id int) (*dto.PromotionResponse, error)
+	UpdatePromotion(id int, req dto.UpdatePromotionRequest) (*dto.PromotionResponse, error)
+	DeletePromotion(id int) error
+	Discounts(userId int, items []model.Item, couponCode string, converter *MoneyConverter) (*CartDiscounts, error)
+}
+
+var (
+	ErrCouponNotFound      = errors.New("coupon not found")
+	ErrDuplicateCouponCode = errors.New("a coupon with this code already exists")
+	ErrCouponValue         = errors.New("percentage coupons must have a value between 1 and 100")
+	ErrCouponWindow        = errors.New("ends_at must be after starts_at")
+	ErrCouponInactive      = errors.New("coupon is not active")
+	ErrCouponNotStarted    = errors.New("coupon is not valid yet")
+	ErrCouponExpired       = errors.New("coupon has expired")
+	ErrCouponMinSpend      = errors.New("cart does not reach the minimum spend for this coupon")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to any item in the cart")
+	ErrCouponUsedUp        = errors.New("coupon has reached its usage limit")
+	ErrPromotionNotFound   = errors.New("promotion not found")
+	ErrPromotionValue      = errors.New("value must be between 1 and 100 for percentage and buy_x_get_y promotions, and is required for fixed and bundle promotions")
+	ErrPromotionQty        = errors.New("buy_x_get_y promotions need buy_qty and get_qty, and bundles need a buy_qty of at least 2")
+	ErrPromotionWindow     = errors.New("ends_at must be after starts_at")
+)
+
+// couponRejections are the reasons a coupon gives no discount on a cart, as
+// opposed to failures looking it up.
+var couponRejections = []error{
+	ErrCouponNotFound,
+	ErrCouponInactive,
+	ErrCouponNotStarted,
+	ErrCouponExpired,
+	ErrCouponMinSpend,
+	ErrCouponNotApplicable,
+	ErrCouponUsedUp,
+}
+
+// IsCouponRejection reports whether err explains why a coupon cannot be used
+// on a cart.
+func IsCouponRejection(err error) bool {
+	for _, rejection := range couponRejections {
+		if errors.Is(err, rejection) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type promotionUsecase struct {
+	couponRepo    repository.CouponRepository
+	promotionRepo repository.PromotionRepository
+	categoryRepo repository.CategoryRepository
+	bookRepo     repository.BookRepository
+}
+
+func (pu *promotionUsecase) CreateCoupon(req dto.CreateCouponRequest) (*dto.CouponResponse, error) {
+	coupon := &model.Coupon{
+		Code:         normalizeCouponCode(req.Code),
+		Description:  req.Description,
+		Type:         req.Type,
+		Value:        req.Value,
+		MaxDiscount:  req.MaxDiscount,
+		MinSpend:     req.MinSpend,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		IsActive:     req.IsActive == nil || *req.IsActive,
+	}
+
+	categoryIds, bookIds, err := pu.checkScope(&req.CategoryIds, &req.BookIds)
+	if err != nil {
+		return nil, err
+	}
+
+	coupon.Categories = toCouponCategories(categoryIds)
+	coupon.Books = toCouponBooks(bookIds)
+
+	err = pu.validateCoupon(coupon, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	create, err := pu.couponRepo.CreateCoupon(coupon)
+	if err != nil {
+		return nil, err
+	}
+
+	return pu.toCouponResponse(*create)
+}
+
+func (pu *promotionUsecase) GetCoupons(filter dto.CouponFilterRequest) ([]dto.CouponResponse, *dto.Paging, error) {
+	paging := &dto.Paging{Page: filter.Page, Limit: filter.Limit}
+	if paging.Page == 0 {
+		paging.Page = 1
+	}
+	if paging.Limit == 0 {
+		paging.Limit = defaultPageLimit
+	}
+
+	coupons, total, err := pu.couponRepo.FindAll(paging.Limit, (paging.Page-1)*paging.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paging.TotalRows = total
+	paging.TotalPages = int((total + int64(paging.Limit) - 1) / int64(paging.Limit))
+
+	response := []dto.CouponResponse{}
+	for _, coupon := range coupons {
+		couponResponse, err := pu.toCouponResponse(coupon)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		response = append(response, *couponResponse)
+	}
+
+	return response, paging, nil
+}
+
+func (pu *promotionUsecase) GetCouponById(id int) (*dto.CouponResponse, error) {
+	coupon, err := pu.couponRepo.FindById(id)
+	if err != nil {
+		return nil, ErrCouponNotFound
+	}
+
+	return pu.toCouponResponse(*coupon)
+}
+
+func (pu *promotionUsecase) UpdateCoupon(id int, req dto.UpdateCouponRequest) (*dto.CouponResponse, error) {
+	coupon, err := pu.couponRepo.FindById(id)
+	if err != nil {
+		return nil, ErrCouponNotFound
+	}
+
+	if req.Code != nil {
+		coupon.Code = normalizeCouponCode(*req.Code)
+	}
+
+	if req.Description != nil {
+		coupon.Description = *req.Description
+	}
+
+	if req.Type != nil {
+		coupon.Type = *req.Type
+	}
+
+	if req.Value != nil {
+		coupon.Value = *req.Value
+	}
+
+	if req.MaxDiscount != nil {
+		coupon.MaxDiscount = req.MaxDiscount
+	}
+
+	if req.MinSpend != nil {
+		coupon.MinSpend = *req.MinSpend
+	}
+
+	if req.StartsAt != nil {
+		coupon.StartsAt = req.StartsAt
+	}
+
+	if req.EndsAt != nil {
+		coupon.EndsAt = req.EndsAt
+	}
+
+	if req.UsageLimit != nil {
+		coupon.UsageLimit = req.UsageLimit
+	}
+
+	if req.PerUserLimit != nil {
+		coupon.PerUserLimit = req.PerUserLimit
+	}
+
+	if req.IsActive != nil {
+		coupon.IsActive = *req.IsActive
+	}
+
+	categoryIds, bookIds, err := pu.checkScope(req.CategoryIds, req.BookIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CategoryIds != nil {
+		coupon.Categories = toCouponCategories(categoryIds)
+	}
+
+	if req.BookIds != nil {
+		coupon.Books = toCouponBooks(bookIds)
+	}
+
+	err = pu.validateCoupon(coupon, id)
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := pu.couponRepo.UpdateCoupon(coupon, req.CategoryIds != nil, req.BookIds != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return pu.toCouponResponse(*update)
+}
+
+func (pu *promotionUsecase) DeleteCoupon(id int) error {
+	_, err := pu.couponRepo.FindById(id)
+	if err != nil {
+		return ErrCouponNotFound
+	}
+
+	return pu.couponRepo.DeleteCoupon(id)
+}
+
+func (pu *promotionUsecase) CreatePromotion(req dto.CreatePromotionRequest) (*dto.PromotionResponse, error) {
+	promotion := &model.Promotion{
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        req.Type,
+		Value:       req.Value,
+		BuyQty:      req.BuyQty,
+		GetQty:      req.GetQty,
+		Priority:    req.Priority,
+		Stackable:   req.Stackable,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+
+	categoryIds, bookIds, err := pu.checkScope(&req.CategoryIds, &req.BookIds)
+	if err != nil {
+		return nil, err
+	}
+
+	promotion.Categories = toPromotionCategories(categoryIds)
+	promotion.Books = toPromotionBooks(bookIds)
+
+	err = validatePromotion(promotion)
+	if err != nil {
+		return nil, err
+	}
+
+	create, err := pu.promotionRepo.CreatePromotion(promotion)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toPromotionResponse(*create)
+	return &response, nil
+}
+
+func (pu *promotionUsecase) GetPromotions(filter dto.PromotionFilterRequest) ([]dto.PromotionResponse, *dto.Paging, error) {
+	paging := &dto.Paging{Page: filter.Page, Limit: filter.Limit}
+	if paging.Page == 0 {
+		paging.Page = 1
+	}
+	if paging.Limit == 0 {
+		paging.Limit = defaultPageLimit
+	}
+
+	promotions, total, err := pu.promotionRepo.FindAll(paging.Limit, (paging.Page-1)*paging.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paging.TotalRows = total
+	paging.TotalPages = int((total + int64(paging.Limit) - 1) / int64(paging.Limit))
+
+	response := []dto.PromotionResponse{}
+	for _, promotion := range promotions {
+		response = append(response, toPromotionResponse(promotion))
+	}
+
+	return response, paging, nil
+}
+
+func (pu *promotionUsecase) GetPromotionById(id int) (*dto.PromotionResponse, error) {
+	promotion, err := pu.promotionRepo.FindById(id)
+	if err != nil {
+		return nil, ErrPromotionNotFound
+	}
+
+	response := toPromotionResponse(*promotion)
+	return &response, nil
+}
+
+func (pu *promotionUsecase) UpdatePromotion(id int, req dto.UpdatePromotionRequest) (*dto.PromotionResponse, error) {
+	promotion, err := pu.promotionRepo.FindById(id)
+	if err != nil {
+		return nil, ErrPromotionNotFound
+	}
+
+	if req.Name != nil {
+		promotion.Name = *req.Name
+	}
+
+	if req.Description != nil {
+		promotion.Description = *req.Description
+	}
+
+	if req.Type != nil {
+		promotion.Type = *req.Type
+	}
+
+	if req.Value != nil {
+		promotion.Value = *req.Value
+	}
+
+	if req.BuyQty != nil {
+		promotion.BuyQty = *req.BuyQty
+	}
+
+	if req.GetQty != nil {
+		promotion.GetQty = *req.GetQty
+	}
+
+	if req.Priority != nil {
+		promotion.Priority = *req.Priority
+	}
+
+	if req.Stackable != nil {
+		promotion.Stackable = *req.Stackable
+	}
+
+	if req.StartsAt != nil {
+		promotion.StartsAt = req.StartsAt
+	}
+
+	if req.EndsAt != nil {
+		promotion.EndsAt = req.EndsAt
+	}
+
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+
+	categoryIds, bookIds, err := pu.checkScope(req.CategoryIds, req.BookIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CategoryIds != nil {
+		promotion.Categories = toPromotionCategories(categoryIds)
+	}
+
+	if req.BookIds != nil {
+		promotion.Books = toPromotionBooks(bookIds)
+	}
+
+	err = validatePromotion(promotion)
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := pu.promotionRepo.UpdatePromotion(promotion, req.CategoryIds != nil, req.BookIds != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toPromotionResponse(*update)
+	return &response, nil
+}
+
+func (pu *promotionUsecase) DeletePromotion(id int) error {
+	_, err := pu.promotionRepo.FindById(id)
+	if err != nil {
+		return ErrPromotionNotFound
+	}
+
+	return pu.promotionRepo.DeletePromotion(id)
+}
+
+// CartDiscounts is what the running promotions and a coupon take off a
+// cart. Remaining holds what is left to pay per variant once every discount
+// is spread over the items it was given on. A coupon that gives no discount
+// is left out with the reason in CouponErr.
+type CartDiscounts struct {
+	Discounts []model.Discount
+	Hints     []string
+	Remaining map[int]int64
+	Coupon    *model.Coupon
+	CouponErr error
+}
+
+// Discounts applies the running promotions to the items first, in the
+// engine's priority order, and then works out the coupon on what is left to
+// pay for the items in its scope, so a coupon never discounts money a
+// promotion already took off. Promotion and coupon amounts are set in the
+// base currency and converted into the items' currency before they are
+// applied.
+func (pu *promotionUsecase) Discounts(userId int, items []model.Item, couponCode string, converter *MoneyConverter) (*CartDiscounts, error) {
+	scope := &pricingScope{couponRepo: pu.couponRepo, items: items}
+
+	promotions, err := pu.promotionRepo.FindActive(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []helper.PricingRule
+	for _, promotion := range promotions {
+		matches, err := scope.matcher(promotionCategoryIds(promotion), promotionBookIds(promotion))
+		if err != nil {
+			return nil, err
+		}
+
+		value := int64(promotion.Value)
+		if promotion.Type == model.PromotionTypeFixed || promotion.Type == model.PromotionTypeBundle {
+			value = converter.FromBase(value).Amount
+		}
+
+		rules = append(rules, helper.PricingRule{Promotion: promotion, Value: value, Matches: matches})
+	}
+
+	result := helper.ApplyPromotions(items, rules, converter.Currency())
+	discounts := &CartDiscounts{
+		Discounts: result.Discounts,
+		Hints:     result.Hints,
+		Remaining: result.Remaining,
+	}
+
+	if couponCode == "" {
+		return discounts, nil
+	}
+
+	coupon, discount, err := pu.evaluateCoupon(userId, couponCode, items, result.Remaining, scope, converter)
+	if IsCouponRejection(err) {
+		discounts.CouponErr = err
+	} else if err != nil {
+		return nil, err
+	} else {
+		discounts.Discounts = append(discounts.Discounts, *discount)
+		helper.AllocateDiscount(discounts.Remaining, discount.VariantIds, discount.Amount.Amount)
+		discounts.Coupon = coupon
+	}
+
+	return discounts, nil
+}
+
+// evaluateCoupon checks the coupon against the cart and the user's usage and
+// works out the discount line it gives on the amounts left to pay.
+func (pu *promotionUsecase) evaluateCoupon(userId int, code string, items []model.Item, remaining map[int]int64, scope *pricingScope, converter *MoneyConverter) (*model.Coupon, *model.Discount, error) {
+	coupon, err := pu.couponRepo.FindByCode(code)
+	if err != nil {
+		return nil, nil, ErrCouponNotFound
+	}
+
+	if !coupon.IsActive {
+		return nil, nil, ErrCouponInactive
+	}
+
+	now := time.Now()
+	if coupon.StartsAt != nil && now.Before(*coupon.StartsAt) {
+		return nil, nil, ErrCouponNotStarted
+	}
+	if coupon.EndsAt != nil && !now.Before(*coupon.EndsAt) {
+		return nil, nil, ErrCouponExpired
+	}
+
+	matches, err := scope.matcher(couponCategoryIds(*coupon), couponBookIds(*coupon))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var subtotal int64
+	variantIds := []int{}
+	for _, item := range items {
+		if matches(item) && remaining[item.VariantId] > 0 {
+			subtotal += remaining[item.VariantId]
+			variantIds = append(variantIds, item.VariantId)
+		}
+	}
+
+	if subtotal == 0 {
+		return nil, nil, ErrCouponNotApplicable
+	}
+
+	if subtotal < converter.FromBase(int64(coupon.MinSpend)).Amount {
+		return nil, nil, ErrCouponMinSpend
+	}
+
+	if coupon.UsageLimit != nil || coupon.PerUserLimit != nil {
+		total, byUser, err := pu.couponRepo.CountRedemptions(coupon.ID, userId)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if coupon.UsageLimit != nil && total >= int64(*coupon.UsageLimit) {
+			return nil, nil, ErrCouponUsedUp
+		}
+		if coupon.PerUserLimit != nil && byUser >= int64(*coupon.PerUserLimit) {
+			return nil, nil, ErrCouponUsedUp
+		}
+	}
+
+	currency := converter.Currency()
+	matching := helper.FormatMoney(model.NewMoney(subtotal, currency))
+
+	amount := converter.FromBase(int64(coupon.Value)).Amount
+	explanation := fmt.Sprintf("%s off %s of matching items", helper.FormatMoney(model.NewMoney(amount, currency)), matching)
+	if coupon.Type == model.CouponTypePercentage {
+		amount = subtotal * int64(coupon.Value) / 100
+		explanation = fmt.Sprintf("%d%% off %s of matching items", coupon.Value, matching)
+	}
+	if coupon.MaxDiscount != nil {
+		maxDiscount := converter.FromBase(int64(*coupon.MaxDiscount))
+		if amount > maxDiscount.Amount {
+			amount = maxDiscount.Amount
+			explanation += fmt.Sprintf(", capped at %s", helper.FormatMoney(maxDiscount))
+		}
+	}
+	if amount > subtotal {
+		amount = subtotal
+	}
+
+	return coupon, &model.Discount{
+		Source:      model.DiscountSourceCoupon,
+		Code:        coupon.Code,
+		Label:       couponLabel(coupon, converter),
+		Explanation: explanation,
+		Amount:      model.NewMoney(amount, currency),
+		VariantIds:  variantIds,
+	}, nil
+}
+
+// checkScope checks the category and book ids of a promotion or coupon scope
+// exist and drops duplicates. A nil list is passed through untouched.
+func (pu *promotionUsecase) checkScope(categoryIds, bookIds *[]int) ([]int, []int, error) {
+	var categories, books []int
+
+	if categoryIds != nil {
+		categories = uniqueInts(*categoryIds)
+		for _, categoryId := range categories {
+			_, err := pu.categoryRepo.FindById(categoryId)
+			if err != nil {
+				return nil, nil, ErrCategoryNotFound
+			}
+		}
+	}
+
+	if bookIds != nil {
+		books = uniqueInts(*bookIds)
+		found, err := pu.bookRepo.FindByIds(books)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(found) != len(books) {
+			return nil, nil, ErrBookNotFound
+		}
+	}
+
+	return categories, books, nil
+}
+
+// pricingScope answers which cart items fall in the scope of a promotion or
+// coupon, loading the categories of the books in the cart at most once.
+type pricingScope struct {
+	couponRepo     repository.CouponRepository
+	items          []model.Item
+	bookCategories map[int]int
+}
+
+func (ps *pricingScope) matcher(categoryIds, bookIds []int) (func(item model.Item) bool, error) {
+	if len(categoryIds) == 0 && len(bookIds) == 0 {
+		return func(item model.Item) bool { return true }, nil
+	}
+
+	books := map[int]bool{}
+	for _, bookId := range bookIds {
+		books[bookId] = true
+	}
+
+	categories := map[int]bool{}
+	if len(categoryIds) > 0 {
+		descendantIds, err := ps.couponRepo.FindDescendantCategoryIds(categoryIds)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, categoryId := range descendantIds {
+			categories[categoryId] = true
+		}
+
+		if ps.bookCategories == nil {
+			var cartBookIds []int
+			for _, item := range ps.items {
+				cartBookIds = append(cartBookIds, item.BookId)
+			}
+
+			ps.bookCategories, err = ps.couponRepo.FindBookCategories(cartBookIds)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	bookCategories := ps.bookCategories
+	return func(item model.Item) bool {
+		if books[item.BookId] {
+			return true
+		}
+
+		categoryId, ok := bookCategories[item.BookId]
+		return ok && categories[categoryId]
+	}, nil
+}
+
+func (pu *promotionUsecase) validateCoupon(coupon *model.Coupon, excludeId int) error {
+	if coupon.Type == model.CouponTypePercentage && coupon.Value > 100 {
+		return ErrCouponValue
+	}
+
+	if coupon.StartsAt != nil && coupon.EndsAt != nil && !coupon.EndsAt.After(*coupon.StartsAt) {
+		return ErrCouponWindow
+	}
+
+	exists, err := pu.couponRepo.CodeExists(coupon.Code, excludeId)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrDuplicateCouponCode
+	}
+
+	return nil
+}
+
+func (pu *promotionUsecase) toCouponResponse(coupon model.Coupon) (*dto.CouponResponse, error) {
+	total, _, err := pu.couponRepo.CountRedemptions(coupon.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CouponResponse{
+		ID:           coupon.ID,
+		Code:         coupon.Code,
+		Description:  coupon.Description,
+		Type:         coupon.Type,
+		Value:        coupon.Value,
+		MaxDiscount:  coupon.MaxDiscount,
+		MinSpend:     coupon.MinSpend,
+		StartsAt:     coupon.StartsAt,
+		EndsAt:       coupon.EndsAt,
+		UsageLimit:   coupon.UsageLimit,
+		PerUserLimit: coupon.PerUserLimit,
+		TimesUsed:    total,
+		IsActive:     coupon.IsActive,
+		CategoryIds:  couponCategoryIds(coupon),
+		BookIds:      couponBookIds(coupon),
+		CreatedAt:    coupon.CreatedAt,
+		UpdatedAt:    coupon.UpdatedAt,
+	}
+
+	return response, nil
+}
+
+func validatePromotion(promotion *model.Promotion) error {
+	switch promotion.Type {
+	case model.PromotionTypeBuyXGetY:
+		if promotion.Value == 0 {
+			promotion.Value = 100
+		}
+		if promotion.BuyQty < 1 || promotion.GetQty < 1 {
+			return ErrPromotionQty
+		}
+	case model.PromotionTypeBundle:
+		if promotion.BuyQty < 2 {
+			return ErrPromotionQty
+		}
+	}
+
+	switch promotion.Type {
+	case model.PromotionTypePercentage, model.PromotionTypeBuyXGetY:
+		if promotion.Value < 1 || promotion.Value > 100 {
+			return ErrPromotionValue
+		}
+	default:
+		if promotion.Value < 1 {
+			return ErrPromotionValue
+		}
+	}
+
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return ErrPromotionWindow
+	}
+
+	return nil
+}
+
+func toPromotionResponse(promotion model.Promotion) dto.PromotionResponse {
+	return dto.PromotionResponse{
+		ID:          promotion.ID,
+		Name:        promotion.Name,
+		Description: promotion.Description,
+		Type:        promotion.Type,
+		Value:       promotion.Value,
+		BuyQty:      promotion.BuyQty,
+		GetQty:      promotion.GetQty,
+		Priority:    promotion.Priority,
+		Stackable:   promotion.Stackable,
+		StartsAt:    promotion.StartsAt,
+		EndsAt:      promotion.EndsAt,
+		IsActive:    promotion.IsActive,
+		CategoryIds: promotionCategoryIds(promotion),
+		BookIds:     promotionBookIds(promotion),
+		CreatedAt:   promotion.CreatedAt,
+		UpdatedAt:   promotion.UpdatedAt,
+	}
+}
+
+func promotionCategoryIds(promotion model.Promotion) []int {
+	ids := []int{}
+	for _, category := range promotion.Categories {
+		ids = append(ids, category.CategoryID)
+	}
+	return ids
+}
+
+func promotionBookIds(promotion model.Promotion) []int {
+	ids := []int{}
+	for _, book := range promotion.Books {
+		ids = append(ids, book.BookID)
+	}
+	return ids
+}
+
+func couponCategoryIds(coupon model.Coupon) []int {
+	ids := []int{}
+	for _, category := range coupon.Categories {
+		ids = append(ids, category.CategoryID)
+	}
+	return ids
+}
+
+func couponBookIds(coupon model.Coupon) []int {
+	ids := []int{}
+	for _, book := range coupon.Books {
+		ids = append(ids, book.BookID)
+	}
+	return ids
+}
+
+func toPromotionCategories(categoryIds []int) []model.PromotionCategory {
+	categories := []model.PromotionCategory{}
+	for _, categoryId := range categoryIds {
+		categories = append(categories, model.PromotionCategory{CategoryID: categoryId})
+	}
+	return categories
+}
+
+func toPromotionBooks(bookIds []int) []model.PromotionBook {
+	books := []model.PromotionBook{}
+	for _, bookId := range bookIds {
+		books = append(books, model.PromotionBook{BookID: bookId})
+	}
+	return books
+}
+
+func toCouponCategories(categoryIds []int) []model.CouponCategory {
+	categories := []model.CouponCategory{}
+	for _, categoryId := range categoryIds {
+		categories = append(categories, model.CouponCategory{CategoryID: categoryId})
+	}
+	return categories
+}
+
+func toCouponBooks(bookIds []int) []model.CouponBook {
+	books := []model.CouponBook{}
+	for _, bookId := range bookIds {
+		books = append(books, model.CouponBook{BookID: bookId})
+	}
+	return books
+}
+
+// couponLabel describes the coupon on a discount line, preferring the
+// description merchandising gave it.
+func couponLabel(coupon *model.Coupon, converter *MoneyConverter) string {
+	if coupon.Description != "" {
+		return coupon.Description
+	}
+
+	if coupon.Type == model.CouponTypePercentage {
+		return fmt.Sprintf("%d%% off with %s", coupon.Value, coupon.Code)
+	}
+
+	return fmt.Sprintf("%s off with %s", helper.FormatMoney(converter.FromBase(int64(coupon.Value))), coupon.Code)
+}
+
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func uniqueInts(values []int) []int {
+	seen := map[int]bool{}
+	unique := []int{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	return unique
+}
+
+func NewPromotionUsecase(couponRepo repository.CouponRepository, promotionRepo repository.PromotionRepository, categoryRepo repository.CategoryRepository, bookRepo repository.BookRepository) *promotionUsecase {
+	return &promotionUsecase{
+		couponRepo:    couponRepo,
+		promotionRepo: promotionRepo,
+		categoryRepo:  categoryRepo,
+		bookRepo:      bookRepo,
+	}
+}
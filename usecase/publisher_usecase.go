@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type PublisherUsecase interface {
+	Create(req dto.CreatePublisherRequest) (*model.Publisher, error)
+	GetAll() ([]dto.PublisherResponse, error)
+	GetById(id int) (*dto.PublisherResponse, error)
+	Update(id int, updatePublisher *dto.UpdatePublisherRequest) (*model.Publisher, error)
+	Delete(id int) error
+}
+
+var (
+	ErrPublisherNotFound = errors.New("publisher not found")
+	ErrPublisherInUse    = errors.New("publisher still has books")
+)
+
+type publisherUsecase struct {
+	publisherRepo repository.PublisherRepository
+}
+
+func (pu *publisherUsecase) Create(req dto.CreatePublisherRequest) (*model.Publisher, error) {
+	publisher := &model.Publisher{
+		Name:    req.Name,
+		Website: req.Website,
+	}
+
+	create, err := pu.publisherRepo.CreatePublisher(publisher)
+	if err != nil {
+		return nil, err
+	}
+
+	return create, nil
+}
+
+func (pu *publisherUsecase) GetAll() ([]dto.PublisherResponse, error) {
+	response := []dto.PublisherResponse{}
+
+	publishers, err := pu.publisherRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, publisher := range publishers {
+		response = append(response, dto.PublisherResponse{
+			ID:        publisher.ID,
+			Name:      publisher.Name,
+			Website:   publisher.Website,
+			CreatedAt: publisher.CreatedAt,
+			UpdatedAt: publisher.UpdatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+func (pu *publisherUsecase) GetById(id int) (*dto.PublisherResponse, error) {
+	publisher, err := pu.publisherRepo.FindById(id)
+	if err != nil {
+		return nil, ErrPublisherNotFound
+	}
+
+	return &dto.PublisherResponse{
+		ID:        publisher.ID,
+		Name:      publisher.Name,
+		Website:   publisher.Website,
+		CreatedAt: publisher.CreatedAt,
+		UpdatedAt: publisher.UpdatedAt,
+	}, nil
+}
+
+func (pu *publisherUsecase) Update(id int, updatePublisher *dto.UpdatePublisherRequest) (*model.Publisher, error) {
+	publisher, err := pu.publisherRepo.FindById(id)
+	if err != nil {
+		return nil, ErrPublisherNotFound
+	}
+
+	if updatePublisher.Name != nil {
+		publisher.Name = *updatePublisher.Name
+	}
+
+	if updatePublisher.Website != nil {
+		publisher.Website = *updatePublisher.Website
+	}
+
+	update, err := pu.publisherRepo.UpdatePublisher(id, publisher)
+	if err != nil {
+		return nil, err
+	}
+
+	return update, nil
+}
+
+func (pu *publisherUsecase) Delete(id int) error {
+	_, err := pu.publisherRepo.FindById(id)
+	if err != nil {
+		return ErrPublisherNotFound
+	}
+
+	count, err := pu.publisherRepo.CountBooks(id)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrPublisherInUse
+	}
+
+	return pu.publisherRepo.DeletePublisher(id)
+}
+
+func NewPublisherUsecase(publisherRepo repository.PublisherRepository) *publisherUsecase {
+	return &publisherUsecase{publisherRepo: publisherRepo}
+}
@@ -0,0 +1,253 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type ReadingListUsecase interface {
+	Create(userId int, req dto.CreateReadingListRequest, currency string) (*dto.ReadingListResponse, error)
+	GetByUser(userId int, currency string) ([]dto.ReadingListResponse, error)
+	GetById(id, userId int, currency string) (*dto.ReadingListResponse, error)
+	GetShared(token string, currency string) (*dto.ReadingListResponse, error)
+	Update(id, userId int, req dto.UpdateReadingListRequest, currency string) (*dto.ReadingListResponse, error)
+	Delete(id, userId int) error
+	AddBook(id, userId int, req dto.AddReadingListBookRequest, currency string) (*dto.ReadingListResponse, error)
+	RemoveBook(id, userId, bookId int, currency string) (*dto.ReadingListResponse, error)
+	RotateShareToken(id, userId int, currency string) (*dto.ReadingListResponse, error)
+}
+
+var ErrReadingListNotFound = errors.New("reading list not found")
+
+type readingListUsecase struct {
+	readingListRepo repository.ReadingListRepository
+	bookUsecase     BookUsecase
+}
+
+func (rlu *readingListUsecase) Create(userId int, req dto.CreateReadingListRequest, currency string) (*dto.ReadingListResponse, error) {
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	create, err := rlu.readingListRepo.CreateList(&model.ReadingList{
+		UserID:      userId,
+		Name:        req.Name,
+		Description: req.Description,
+		IsPublic:    req.IsPublic,
+		ShareToken:  token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rlu.toReadingListResponse(*create, true, currency)
+}
+
+func (rlu *readingListUsecase) GetByUser(userId int, currency string) ([]dto.ReadingListResponse, error) {
+	lists, err := rlu.readingListRepo.FindByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.ReadingListResponse{}
+	for _, list := range lists {
+		listResponse, err := rlu.toReadingListResponse(list, true, currency)
+		if err != nil {
+			return nil, err
+		}
+		response = append(response, *listResponse)
+	}
+
+	return response, nil
+}
+
+func (rlu *readingListUsecase) GetById(id, userId int, currency string) (*dto.ReadingListResponse, error) {
+	list, err := rlu.findOwned(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return rlu.toReadingListResponse(*list, true, currency)
+}
+
+// GetShared opens a list through its share link. Private lists are reported
+// as not found so a leaked token stops working once the owner unpublishes.
+func (rlu *readingListUsecase) GetShared(token string, currency string) (*dto.ReadingListResponse, error) {
+	list, err := rlu.readingListRepo.FindByShareToken(token)
+	if err != nil || !list.IsPublic {
+		return nil, ErrReadingListNotFound
+	}
+
+	return rlu.toReadingListResponse(*list, false, currency)
+}
+
+func (rlu *readingListUsecase) Update(id, userId int, req dto.UpdateReadingListRequest, currency string) (*dto.ReadingListResponse, error) {
+	list, err := rlu.findOwned(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		list.Name = *req.Name
+	}
+
+	if req.Description != nil {
+		list.Description = *req.Description
+	}
+
+	if req.IsPublic != nil {
+		list.IsPublic = *req.IsPublic
+	}
+
+	update, err := rlu.readingListRepo.UpdateList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	return rlu.toReadingListResponse(*update, true, currency)
+}
+
+func (rlu *readingListUsecase) Delete(id, userId int) error {
+	_, err := rlu.findOwned(id, userId)
+	if err != nil {
+		return err
+	}
+
+	return rlu.readingListRepo.DeleteList(id)
+}
+
+func (rlu *readingListUsecase) AddBook(id, userId int, req dto.AddReadingListBookRequest, currency string) (*dto.ReadingListResponse, error) {
+	_, err := rlu.findOwned(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = rlu.bookUsecase.GetById(req.BookID, currency)
+	if err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	err = rlu.readingListRepo.AddItem(&model.ReadingListItem{
+		ReadingListID: id,
+		BookID:        req.BookID,
+		Note:          req.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rlu.GetById(id, userId, currency)
+}
+
+func (rlu *readingListUsecase) RemoveBook(id, userId, bookId int, currency string) (*dto.ReadingListResponse, error) {
+	_, err := rlu.findOwned(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rlu.readingListRepo.DeleteItem(id, bookId)
+	if err != nil {
+		return nil, err
+	}
+
+	return rlu.GetById(id, userId, currency)
+}
+
+// RotateShareToken replaces the share token so links handed out earlier stop
+// working.
+func (rlu *readingListUsecase) RotateShareToken(id, userId int, currency string) (*dto.ReadingListResponse, error) {
+	list, err := rlu.findOwned(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	list.ShareToken, err = newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := rlu.readingListRepo.UpdateList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	return rlu.toReadingListResponse(*update, true, currency)
+}
+
+// findOwned loads a list of the user. Lists of other users are reported as
+// not found.
+func (rlu *readingListUsecase) findOwned(id, userId int) (*model.ReadingList, error) {
+	list, err := rlu.readingListRepo.FindById(id)
+	if err != nil || list.UserID != userId {
+		return nil, ErrReadingListNotFound
+	}
+
+	return list, nil
+}
+
+// toReadingListResponse resolves the books on the list. The share token is
+// only included for the owner.
+func (rlu *readingListUsecase) toReadingListResponse(list model.ReadingList, owner bool, currency string) (*dto.ReadingListResponse, error) {
+	var bookIds []int
+	for _, item := range list.Items {
+		bookIds = append(bookIds, item.BookID)
+	}
+
+	books, err := rlu.bookUsecase.GetByIds(bookIds, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ReadingListResponse{
+		ID:          list.ID,
+		UserID:      list.UserID,
+		Name:        list.Name,
+		Description: list.Description,
+		IsPublic:    list.IsPublic,
+		Books:       []dto.ReadingListBookResponse{},
+		CreatedAt:   list.CreatedAt,
+		UpdatedAt:   list.UpdatedAt,
+	}
+
+	if owner {
+		response.ShareToken = list.ShareToken
+	}
+
+	for _, item := range list.Items {
+		book, ok := books[item.BookID]
+		if !ok {
+			continue
+		}
+
+		response.Books = append(response.Books, dto.ReadingListBookResponse{
+			Book:    book,
+			Note:    item.Note,
+			AddedAt: item.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+func newShareToken() (string, error) {
+	buf := make([]byte, 18)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func NewReadingListUsecase(readingListRepo repository.ReadingListRepository, bookUsecase BookUsecase) *readingListUsecase {
+	return &readingListUsecase{
+		readingListRepo: readingListRepo,
+		bookUsecase:     bookUsecase,
+	}
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+	"github.com/mhmmmdrivaldhi/go-book-api/service"
+)
+
+type RefundUsecase interface {
+	RefundOrder(orderId int, req dto.RefundOrderRequest) (*model.Order, error)
+	Issue(order *model.Order, returnId *int, amount int64, reason string) (*model.Refund, error)
+}
+
+var (
+	ErrOrderNotRefundable = errors.New("only paid orders can be refunded")
+	ErrRefundExceedsOrder = errors.New("refund exceeds the amount left to refund on the order")
+	ErrReturnRefunded     = errors.New("return has already been refunded")
+	ErrRefundFailed       = errors.New("refund provider declined the refund")
+)
+
+type refundUsecase struct {
+	refundRepo     repository.RefundRepository
+	orderRepo      repository.OrderRepository
+	refundService  service.RefundService
+	invoiceUsecase InvoiceUsecase
+}
+
+// RefundOrder pays back part or all of an order outside of a return.
+func (ru *refundUsecase) RefundOrder(orderId int, req dto.RefundOrderRequest) (*model.Order, error) {
+	order, err := ru.orderRepo.FindById(orderId)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	amount := order.TotalPrice - order.TotalRefunded
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+
+	_, err = ru.Issue(order, nil, amount, strings.TrimSpace(req.Reason))
+	if err != nil {
+		return nil, err
+	}
+
+	return ru.orderRepo.FindById(orderId)
+}
+
+// Issue refunds the amount, in the order's currency, through the refund
+// provider. The refund is reserved against the order before the provider is
+// called and released again when the provider declines it. A refund that
+// went through gets a credit note.
+func (ru *refundUsecase) Issue(order *model.Order, returnId *int, amount int64, reason string) (*model.Refund, error) {
+	if !refundableStatuses[order.Status] {
+		return nil, ErrOrderNotRefundable
+	}
+
+	if amount <= 0 || amount > order.TotalPrice-order.TotalRefunded {
+		return nil, ErrRefundExceedsOrder
+	}
+
+	refund := &model.Refund{
+		OrderID:  order.ID,
+		ReturnID: returnId,
+		Amount:   amount,
+		Reason:   reason,
+		Provider: ru.refundService.Name(),
+	}
+
+	err := ru.refundRepo.Reserve(refund)
+	if errors.Is(err, repository.ErrRefundExceedsOrder) {
+		return nil, ErrRefundExceedsOrder
+	} else if errors.Is(err, repository.ErrReturnRefunded) {
+		return nil, ErrReturnRefunded
+	} else if err != nil {
+		return nil, err
+	}
+
+	ref, providerErr := ru.refundService.Refund(order.ID, refund.Amount, refund.Currency, reason)
+	if providerErr != nil {
+		refund.Error = truncate(providerErr.Error(), 255)
+		err = ru.refundRepo.Fail(refund)
+		if err != nil {
+			log.Printf("failed to release declined refund %d of order %d: %v\n", refund.ID, order.ID, err)
+		}
+
+		return nil, fmt.Errorf("%w: %s", ErrRefundFailed, providerErr.Error())
+	}
+
+	refund.ProviderRef = ref
+	err = ru.refundRepo.Complete(refund)
+	if err != nil {
+		// the money has left; the pending refund keeps it counted against
+		// the order until someone reconciles it with the provider reference
+		log.Printf("failed to record refund %d of order %d paid as %s: %v\n", refund.ID, order.ID, ref, err)
+		return nil, err
+	}
+
+	_, err = ru.invoiceUsecase.IssueCreditNote(refund)
+	if err != nil {
+		log.Printf("failed to issue credit note for refund %d of order %d: %v\n", refund.ID, order.ID, err)
+	}
+
+	return refund, nil
+}
+
+// refundableStatuses are the order states in which money has been taken and
+// not yet fully paid back.
+var refundableStatuses = map[string]bool{
+	model.OrderStatusPaid:      true,
+	model.OrderStatusShipped:   true,
+	model.OrderStatusDelivered: true,
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	return value[:length]
+}
+
+func NewRefundUsecase(refundRepo repository.RefundRepository, orderRepo repository.OrderRepository, refundService service.RefundService, invoiceUsecase InvoiceUsecase) *refundUsecase {
+	return &refundUsecase{
+		refundRepo:     refundRepo,
+		orderRepo:      orderRepo,
+		refundService:  refundService,
+		invoiceUsecase: invoiceUsecase,
+	}
+}
@@ -0,0 +1,349 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+	"github.com/mhmmmdrivaldhi/go-book-api/service"
+)
+
+type ReturnUsecase interface {
+	Create(orderId, userId int, req dto.CreateReturnRequest) (*model.ReturnRequest, error)
+	GetAll(userId int, role string, filter dto.ReturnFilterRequest) ([]model.ReturnRequest, *dto.Paging, error)
+	GetById(id, userId int, role string) (*model.ReturnRequest, error)
+	UploadPhoto(id, userId int, file io.Reader) (*model.ReturnRequest, error)
+	GetPhoto(id, photoId, userId int, role string) ([]byte, error)
+	Approve(id int, req dto.ReviewReturnRequest) (*model.ReturnRequest, error)
+	Reject(id int, req dto.ReviewReturnRequest) (*model.ReturnRequest, error)
+	Receive(id int) (*model.ReturnRequest, error)
+	Refund(id int, req dto.RefundReturnRequest) (*model.ReturnRequest, error)
+}
+
+var (
+	ErrReturnNotFound      = errors.New("return not found")
+	ErrOrderNotReturnable  = errors.New("only paid orders can be returned")
+	ErrReturnItem          = errors.New("order item not found on the order")
+	ErrReturnExceedsOrder  = errors.New("return exceeds the quantity left to return")
+	ErrReturnState         = errors.New("return is not in a state that allows this")
+	ErrTooManyReturnPhotos = fmt.Errorf("a return can have at most %d photos", maxReturnPhotos)
+	ErrPhotoTooLarge       = fmt.Errorf("photo must not be larger than %d MB", MaxReturnPhotoSize>>20)
+	ErrPhotoType           = errors.New("photo must be a JPEG or PNG")
+	ErrReturnPhotoNotFound = errors.New("return photo not found")
+	ErrRefundExceedsReturn = errors.New("refund exceeds what was paid for the returned copies")
+)
+
+const (
+	MaxReturnPhotoSize = 5 << 20
+	maxReturnPhotos    = 5
+
+	// returnPhotoPath is where the return controller serves a photo from.
+	returnPhotoPath = "/api/v1/return/%d/photos/%d"
+)
+
+type returnUsecase struct {
+	returnRepo    repository.ReturnRepository
+	orderRepo     repository.OrderRepository
+	refundUsecase RefundUsecase
+	photoStore    service.ReturnPhotoStore
+}
+
+// Create asks to return copies of an item of one of the user's paid orders.
+func (ru *returnUsecase) Create(orderId, userId int, req dto.CreateReturnRequest) (*model.ReturnRequest, error) {
+	order, err := ru.orderRepo.FindById(orderId)
+	if err != nil || order.UserID != userId {
+		return nil, ErrOrderNotFound
+	}
+
+	if !refundableStatuses[order.Status] {
+		return nil, ErrOrderNotReturnable
+	}
+
+	if findOrderItem(order, req.OrderItemID) == nil {
+		return nil, ErrReturnItem
+	}
+
+	create, err := ru.returnRepo.CreateReturn(&model.ReturnRequest{
+		OrderID:     order.ID,
+		OrderItemID: req.OrderItemID,
+		UserID:      userId,
+		Qty:         req.Qty,
+		Reason:      req.Reason,
+		Comment:     strings.TrimSpace(req.Comment),
+		Status:      model.ReturnStatusRequested,
+	})
+	if errors.Is(err, repository.ErrReturnExceedsOrder) {
+		return nil, ErrReturnExceedsOrder
+	} else if err != nil {
+		return nil, err
+	}
+
+	return ru.withPhotoURLs(create), nil
+}
+
+// GetAll lists the user's returns. Admins see the returns of every user.
+func (ru *returnUsecase) GetAll(userId int, role string, filter dto.ReturnFilterRequest) ([]model.ReturnRequest, *dto.Paging, error) {
+	paging := &dto.Paging{Page: filter.Page, Limit: filter.Limit}
+	if paging.Page == 0 {
+		paging.Page = 1
+	}
+	if paging.Limit == 0 {
+		paging.Limit = defaultPageLimit
+	}
+
+	if role == "admin" {
+		userId = 0
+	}
+
+	returns, total, err := ru.returnRepo.FindAll(userId, filter.Status, paging.Limit, (paging.Page-1)*paging.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if returns == nil {
+		returns = []model.ReturnRequest{}
+	}
+
+	for i := range returns {
+		ru.withPhotoURLs(&returns[i])
+	}
+
+	paging.TotalRows = total
+	paging.TotalPages = int((total + int64(paging.Limit) - 1) / int64(paging.Limit))
+
+	return returns, paging, nil
+}
+
+func (ru *returnUsecase) GetById(id, userId int, role string) (*model.ReturnRequest, error) {
+	ret, err := ru.returnRepo.FindById(id)
+	if err != nil || (ret.UserID != userId && role != "admin") {
+		return nil, ErrReturnNotFound
+	}
+
+	return ru.withPhotoURLs(ret), nil
+}
+
+// UploadPhoto attaches a JPEG or PNG picture to a return that has not been
+// reviewed yet.
+func (ru *returnUsecase) UploadPhoto(id, userId int, file io.Reader) (*model.ReturnRequest, error) {
+	ret, err := ru.returnRepo.FindById(id)
+	if err != nil || ret.UserID != userId {
+		return nil, ErrReturnNotFound
+	}
+
+	if ret.Status != model.ReturnStatusRequested {
+		return nil, ErrReturnState
+	}
+
+	count, err := ru.returnRepo.CountPhotos(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if count >= maxReturnPhotos {
+		return nil, ErrTooManyReturnPhotos
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxReturnPhotoSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > MaxReturnPhotoSize {
+		return nil, ErrPhotoTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := coverExtensions[contentType]
+	if !ok {
+		return nil, ErrPhotoType
+	}
+
+	sum := sha256.Sum256(data)
+	key := fmt.Sprintf("returns/%d/%s%s", id, hex.EncodeToString(sum[:8]), extension)
+
+	err = ru.photoStore.Save(key, contentType, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store return photo: %w", err)
+	}
+
+	err = ru.returnRepo.AddPhoto(&model.ReturnPhoto{ReturnID: id, Key: key})
+	if err != nil {
+		return nil, err
+	}
+
+	return ru.GetById(id, userId, "")
+}
+
+// GetPhoto reads a photo of a return for its owner or an admin.
+func (ru *returnUsecase) GetPhoto(id, photoId, userId int, role string) ([]byte, error) {
+	ret, err := ru.returnRepo.FindById(id)
+	if err != nil || (ret.UserID != userId && role != "admin") {
+		return nil, ErrReturnNotFound
+	}
+
+	for _, photo := range ret.Photos {
+		if photo.ID != photoId {
+			continue
+		}
+
+		data, err := ru.photoStore.Load(photo.Key)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrReturnPhotoNotFound
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load return photo: %w", err)
+		}
+
+		return data, nil
+	}
+
+	return nil, ErrReturnPhotoNotFound
+}
+
+func (ru *returnUsecase) Approve(id int, req dto.ReviewReturnRequest) (*model.ReturnRequest, error) {
+	return ru.review(id, model.ReturnStatusApproved, req.Note)
+}
+
+func (ru *returnUsecase) Reject(id int, req dto.ReviewReturnRequest) (*model.ReturnRequest, error) {
+	return ru.review(id, model.ReturnStatusRejected, req.Note)
+}
+
+// Receive records that the returned copies arrived. Physical copies go back
+// into stock of the variant they were sold as.
+func (ru *returnUsecase) Receive(id int) (*model.ReturnRequest, error) {
+	ret, err := ru.returnRepo.FindById(id)
+	if err != nil {
+		return nil, ErrReturnNotFound
+	}
+
+	if ret.Status != model.ReturnStatusApproved {
+		return nil, ErrReturnState
+	}
+
+	order, err := ru.orderRepo.FindById(ret.OrderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	item := findOrderItem(order, ret.OrderItemID)
+	if item == nil {
+		return nil, ErrReturnItem
+	}
+
+	restockVariantId := 0
+	if item.NeedsShipping() {
+		restockVariantId = item.VariantID
+	}
+
+	received, err := ru.returnRepo.Receive(ret, restockVariantId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !received {
+		return nil, ErrReturnState
+	}
+
+	return ru.withPhotoURLs(ret), nil
+}
+
+// Refund pays back a received return, by default the full value paid for
+// the returned copies and otherwise part of it.
+func (ru *returnUsecase) Refund(id int, req dto.RefundReturnRequest) (*model.ReturnRequest, error) {
+	ret, err := ru.returnRepo.FindById(id)
+	if err != nil {
+		return nil, ErrReturnNotFound
+	}
+
+	if ret.Status != model.ReturnStatusReceived {
+		return nil, ErrReturnState
+	}
+
+	order, err := ru.orderRepo.FindById(ret.OrderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	item := findOrderItem(order, ret.OrderItemID)
+	if item == nil {
+		return nil, ErrReturnItem
+	}
+
+	amount := helper.ReturnValue(*order, *item, ret.Qty)
+	if req.Amount != nil {
+		if *req.Amount > amount {
+			return nil, ErrRefundExceedsReturn
+		}
+		amount = *req.Amount
+	}
+
+	_, err = ru.refundUsecase.Issue(order, &ret.ID, amount, fmt.Sprintf("return #%d: %s", ret.ID, ret.Reason))
+	if err != nil {
+		return nil, err
+	}
+
+	return ru.GetById(id, 0, "admin")
+}
+
+// review approves or rejects a return that is still waiting for a decision.
+func (ru *returnUsecase) review(id int, status, note string) (*model.ReturnRequest, error) {
+	ret, err := ru.returnRepo.FindById(id)
+	if err != nil {
+		return nil, ErrReturnNotFound
+	}
+
+	if ret.Status != model.ReturnStatusRequested {
+		return nil, ErrReturnState
+	}
+
+	reviewed, err := ru.returnRepo.Review(ret, status, strings.TrimSpace(note))
+	if err != nil {
+		return nil, err
+	}
+
+	if !reviewed {
+		return nil, ErrReturnState
+	}
+
+	return ru.withPhotoURLs(ret), nil
+}
+
+func (ru *returnUsecase) withPhotoURLs(ret *model.ReturnRequest) *model.ReturnRequest {
+	if ret.Photos == nil {
+		ret.Photos = []model.ReturnPhoto{}
+	}
+
+	for i := range ret.Photos {
+		ret.Photos[i].URL = fmt.Sprintf(returnPhotoPath, ret.ID, ret.Photos[i].ID)
+	}
+
+	return ret
+}
+
+func findOrderItem(order *model.Order, orderItemId int) *model.OrderItem {
+	for i := range order.Items {
+		if order.Items[i].ID == orderItemId {
+			return &order.Items[i]
+		}
+	}
+
+	return nil
+}
+
+func NewReturnUsecase(returnRepo repository.ReturnRepository, orderRepo repository.OrderRepository, refundUsecase RefundUsecase, photoStore service.ReturnPhotoStore) *returnUsecase {
+	return &returnUsecase{
+		returnRepo:    returnRepo,
+		orderRepo:     orderRepo,
+		refundUsecase: refundUsecase,
+		photoStore:    photoStore,
+	}
+}
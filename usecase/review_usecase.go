@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type ReviewUsecase interface {
+	Create(bookId, userId int, req dto.CreateReviewRequest) (*dto.ReviewResponse, error)
+	GetByBook(bookId int, filter dto.ReviewFilterRequest) ([]dto.ReviewResponse, *dto.Paging, error)
+	Update(bookId, id, userId int, req dto.UpdateReviewRequest) (*dto.ReviewResponse, error)
+	Delete(bookId, id, userId int, role string) error
+	Vote(id, userId int, req dto.ReviewVoteRequest) (*dto.ReviewResponse, error)
+	RemoveVote(id, userId int) (*dto.ReviewResponse, error)
+	Report(id, userId int, req dto.ReportReviewRequest) error
+	GetModerationQueue(filter dto.ModerationFilterRequest) ([]dto.ReviewModerationResponse, *dto.Paging, error)
+	Moderate(id, moderatorId int, req dto.ModerateReviewRequest) (*dto.ReviewModerationResponse, error)
+	RefreshRatings() error
+}
+
+var (
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrReviewExists    = errors.New("you have already reviewed this book")
+	ErrReviewForbidden = errors.New("you can only change your own review")
+	ErrOwnReviewVote   = errors.New("you cannot vote on your own review")
+	ErrReviewReported  = errors.New("you have already reported this review")
+)
+
+type reviewUsecase struct {
+	reviewRepo repository.ReviewRepository
+	bookRepo   repository.BookRepository
+	orderRepo  repository.OrderRepository
+}
+
+func (ru *reviewUsecase) Create(bookId, userId int, req dto.CreateReviewRequest) (*dto.ReviewResponse, error) {
+	_, err := ru.bookRepo.FindById(bookId)
+	if err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	_, err = ru.reviewRepo.FindByUserAndBook(userId, bookId)
+	if err == nil {
+		return nil, ErrReviewExists
+	} else if !errors.Is(err, repository.ErrReviewNotFound) {
+		return nil, err
+	}
+
+	verified, err := ru.orderRepo.HasPurchased(userId, bookId)
+	if err != nil {
+		return nil, err
+	}
+
+	create, err := ru.reviewRepo.CreateReview(&model.Review{
+		UserID:           userId,
+		BookID:           bookId,
+		Stars:            req.Stars,
+		Title:            req.Title,
+		Body:             req.Body,
+		VerifiedPurchase: verified,
+		Status:           model.ReviewStatusPending,
+	})
+	if errors.Is(err, repository.ErrReviewExists) {
+		return nil, ErrReviewExists
+	} else if err != nil {
+		return nil, err
+	}
+
+	response := toReviewResponse(*create)
+	return &response, nil
+}
+
+func (ru *reviewUsecase) GetByBook(bookId int, filter dto.ReviewFilterRequest) ([]dto.ReviewResponse, *dto.Paging, error) {
+	_, err := ru.bookRepo.FindById(bookId)
+	if err != nil {
+		return nil, nil, ErrBookNotFound
+	}
+
+	paging := &dto.Paging{Page: filter.Page, Limit: filter.Limit}
+	if paging.Page == 0 {
+		paging.Page = 1
+	}
+	if paging.Limit == 0 {
+		paging.Limit = defaultPageLimit
+	}
+
+	reviews, total, err := ru.reviewRepo.FindByBook(repository.ReviewFilter{
+		BookId: bookId,
+		Status: model.ReviewStatusApproved,
+		Sort:   filter.Sort,
+		Limit:  paging.Limit,
+		Offset: (paging.Page - 1) * paging.Limit,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paging.TotalRows = total
+	paging.TotalPages = int((total + int64(paging.Limit) - 1) / int64(paging.Limit))
+
+	response := []dto.ReviewResponse{}
+	for _, review := range reviews {
+		response = append(response, toReviewResponse(review))
+	}
+
+	return response, paging, nil
+}
+
+func (ru *reviewUsecase) Update(bookId, id, userId int, req dto.UpdateReviewRequest) (*dto.ReviewResponse, error) {
+	review, err := ru.reviewRepo.FindById(id)
+	if err != nil || review.BookID != bookId {
+		return nil, ErrReviewNotFound
+	}
+
+	if review.UserID != userId {
+		return nil, ErrReviewForbidden
+	}
+
+	if req.Stars != nil {
+		review.Stars = *req.Stars
+	}
+
+	if req.Title != nil {
+		review.Title = *req.Title
+	}
+
+	if req.Body != nil {
+		review.Body = *req.Body
+	}
+
+	// an edited review goes back through moderation
+	review.Status = model.ReviewStatusPending
+	review.RejectReason = ""
+
+	// the purchase may have been made after the review was first written
+	review.VerifiedPurchase, err = ru.orderRepo.HasPurchased(userId, bookId)
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := ru.reviewRepo.UpdateReview(review)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toReviewResponse(*update)
+	return &response, nil
+}
+
+func (ru *reviewUsecase) Delete(bookId, id, userId int, role string) error {
+	review, err := ru.reviewRepo.FindById(id)
+	if err != nil || review.BookID != bookId {
+		return ErrReviewNotFound
+	}
+
+	if review.UserID != userId && role != "admin" {
+		return ErrReviewForbidden
+	}
+
+	return ru.reviewRepo.DeleteReview(review)
+}
+
+// Vote records whether the user found the review helpful. Voting again
+// replaces the earlier vote.
+func (ru *reviewUsecase) Vote(id, userId int, req dto.ReviewVoteRequest) (*dto.ReviewResponse, error) {
+	review, err := ru.findPublished(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.UserID == userId {
+		return nil, ErrOwnReviewVote
+	}
+
+	err = ru.reviewRepo.SaveVote(&model.ReviewVote{
+		ReviewID: id,
+		UserID:   userId,
+		Helpful:  *req.Helpful,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ru.reviewResponse(id)
+}
+
+func (ru *reviewUsecase) RemoveVote(id, userId int) (*dto.ReviewResponse, error) {
+	_, err := ru.findPublished(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ru.reviewRepo.DeleteVote(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return ru.reviewResponse(id)
+}
+
+// Report flags a review as abusive, which puts it in the reported moderation
+// queue until an admin looks at it.
+func (ru *reviewUsecase) Report(id, userId int, req dto.ReportReviewRequest) error {
+	_, err := ru.findPublished(id)
+	if err != nil {
+		return err
+	}
+
+	exists, err := ru.reviewRepo.ReportExists(id, userId)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrReviewReported
+	}
+
+	err = ru.reviewRepo.CreateReport(&model.ReviewReport{
+		ReviewID: id,
+		UserID:   userId,
+		Reason:   req.Reason,
+	})
+	if errors.Is(err, repository.ErrReportExists) {
+		return ErrReviewReported
+	}
+
+	return err
+}
+
+func (ru *reviewUsecase) GetModerationQueue(filter dto.ModerationFilterRequest) ([]dto.ReviewModerationResponse, *dto.Paging, error) {
+	queue := filter.Queue
+	if queue == "" {
+		queue = dto.ModerationQueuePending
+	}
+
+	paging := &dto.Paging{Page: filter.Page, Limit: filter.Limit}
+	if paging.Page == 0 {
+		paging.Page = 1
+	}
+	if paging.Limit == 0 {
+		paging.Limit = defaultPageLimit
+	}
+
+	reviews, total, err := ru.reviewRepo.FindForModeration(queue, paging.Limit, (paging.Page-1)*paging.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paging.TotalRows = total
+	paging.TotalPages = int((total + int64(paging.Limit) - 1) / int64(paging.Limit))
+
+	response := []dto.ReviewModerationResponse{}
+	for _, review := range reviews {
+		response = append(response, toReviewModerationResponse(review))
+	}
+
+	return response, paging, nil
+}
+
+func (ru *reviewUsecase) Moderate(id, moderatorId int, req dto.ModerateReviewRequest) (*dto.ReviewModerationResponse, error) {
+	review, err := ru.reviewRepo.FindById(id)
+	if err != nil {
+		return nil, ErrReviewNotFound
+	}
+
+	now := time.Now()
+	review.Status = req.Status
+	review.RejectReason = ""
+	if req.Status == model.ReviewStatusRejected {
+		review.RejectReason = req.Reason
+	}
+	review.ModeratedBy = &moderatorId
+	review.ModeratedAt = &now
+
+	moderated, err := ru.reviewRepo.Moderate(review)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toReviewModerationResponse(*moderated)
+	return &response, nil
+}
+
+// findPublished loads a review that is visible to other users.
+func (ru *reviewUsecase) findPublished(id int) (*model.Review, error) {
+	review, err := ru.reviewRepo.FindById(id)
+	if err != nil || review.Status != model.ReviewStatusApproved {
+		return nil, ErrReviewNotFound
+	}
+
+	return review, nil
+}
+
+func (ru *reviewUsecase) reviewResponse(id int) (*dto.ReviewResponse, error) {
+	review, err := ru.reviewRepo.FindById(id)
+	if err != nil {
+		return nil, ErrReviewNotFound
+	}
+
+	response := toReviewResponse(*review)
+	return &response, nil
+}
+
+// RefreshRatings recomputes every book's rating from its reviews. It runs on
+// startup so ratings typed in by sellers before reviews existed are replaced.
+func (ru *reviewUsecase) RefreshRatings() error {
+	return ru.reviewRepo.RefreshAllBookRatings()
+}
+
+func toReviewResponse(review model.Review) dto.ReviewResponse {
+	return dto.ReviewResponse{
+		ID:               review.ID,
+		BookID:           review.BookID,
+		UserID:           review.UserID,
+		UserName:         review.User.Name,
+		Stars:            review.Stars,
+		Title:            review.Title,
+		Body:             review.Body,
+		VerifiedPurchase: review.VerifiedPurchase,
+		Status:           review.Status,
+		RejectReason:     review.RejectReason,
+		HelpfulCount:     review.HelpfulCount,
+		UnhelpfulCount:   review.UnhelpfulCount,
+		CreatedAt:        review.CreatedAt,
+		UpdatedAt:        review.UpdatedAt,
+	}
+}
+
+func toReviewModerationResponse(review model.Review) dto.ReviewModerationResponse {
+	reports := []dto.ReviewReportResponse{}
+	for _, report := range review.Reports {
+		reports = append(reports, dto.ReviewReportResponse{
+			ID:         report.ID,
+			UserID:     report.UserID,
+			Reason:     report.Reason,
+			ResolvedAt: report.ResolvedAt,
+			CreatedAt:  report.CreatedAt,
+		})
+	}
+
+	return dto.ReviewModerationResponse{
+		ReviewResponse: toReviewResponse(review),
+		ModeratedBy:    review.ModeratedBy,
+		ModeratedAt:    review.ModeratedAt,
+		Reports:        reports,
+	}
+}
+
+func NewReviewUsecase(reviewRepo repository.ReviewRepository, bookRepo repository.BookRepository, orderRepo repository.OrderRepository) *reviewUsecase {
+	return &reviewUsecase{
+		reviewRepo: reviewRepo,
+		bookRepo:   bookRepo,
+		orderRepo:  orderRepo,
+	}
+}
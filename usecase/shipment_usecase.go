@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type ShipmentUsecase interface {
+	Create(orderId int, req dto.CreateShipmentRequest) (*model.Shipment, error)
+	GetByOrder(orderId, userId int, role string) ([]model.Shipment, error)
+	Update(id int, req dto.UpdateShipmentRequest) (*model.Shipment, error)
+	AddEvent(id int, req dto.CreateShipmentEventRequest) (*model.Shipment, error)
+	GetQueue(filter dto.FulfillmentQueueFilterRequest) ([]dto.FulfillmentQueueEntry, *dto.Paging, error)
+}
+
+var (
+	ErrShipmentNotFound  = errors.New("shipment not found")
+	ErrOrderNotPaid      = errors.New("only paid orders can be fulfilled")
+	ErrShipmentItems     = errors.New("shipment items must be physical items of the order, each listed once")
+	ErrOverShipped       = errors.New("shipment exceeds the quantity left to ship")
+	ErrShipmentDelivered = errors.New("shipment has already been delivered")
+	ErrShipmentStatus    = errors.New("shipment cannot go back to an earlier status")
+)
+
+type shipmentUsecase struct {
+	shipmentRepo repository.ShipmentRepository
+	orderRepo    repository.OrderRepository
+}
+
+// Create packs part of a paid order's physical items into a new shipment,
+// which starts out pending until the carrier reports it shipped.
+func (su *shipmentUsecase) Create(orderId int, req dto.CreateShipmentRequest) (*model.Shipment, error) {
+	order, err := su.orderRepo.FindById(orderId)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	orderItems := make(map[int]model.OrderItem)
+	for _, item := range order.Items {
+		orderItems[item.ID] = item
+	}
+
+	shipment := &model.Shipment{
+		OrderID:        order.ID,
+		Carrier:        strings.TrimSpace(req.Carrier),
+		TrackingNumber: strings.TrimSpace(req.TrackingNumber),
+		Status:         model.ShipmentStatusPending,
+		Events: []model.ShipmentEvent{{
+			Status:      model.ShipmentStatusPending,
+			Description: "shipment created",
+			OccurredAt:  time.Now(),
+		}},
+	}
+
+	seen := make(map[int]bool)
+	for _, reqItem := range req.Items {
+		orderItem, ok := orderItems[reqItem.OrderItemID]
+		if !ok || !orderItem.NeedsShipping() || seen[reqItem.OrderItemID] {
+			return nil, ErrShipmentItems
+		}
+		seen[reqItem.OrderItemID] = true
+
+		shipment.Items = append(shipment.Items, model.ShipmentItem{OrderItemID: reqItem.OrderItemID, Qty: reqItem.Qty})
+	}
+
+	create, err := su.shipmentRepo.CreateShipment(shipment)
+	if errors.Is(err, repository.ErrOrderNotPaid) {
+		return nil, ErrOrderNotPaid
+	} else if errors.Is(err, repository.ErrOverShipped) {
+		return nil, ErrOverShipped
+	} else if err != nil {
+		return nil, err
+	}
+
+	return create, nil
+}
+
+// GetByOrder lists the shipments of an order. Orders of other users are
+// reported as not found, except to admins.
+func (su *shipmentUsecase) GetByOrder(orderId, userId int, role string) ([]model.Shipment, error) {
+	order, err := su.orderRepo.FindById(orderId)
+	if err != nil || (order.UserID != userId && role != "admin") {
+		return nil, ErrOrderNotFound
+	}
+
+	shipments, err := su.shipmentRepo.FindByOrder(order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if shipments == nil {
+		shipments = []model.Shipment{}
+	}
+
+	return shipments, nil
+}
+
+func (su *shipmentUsecase) Update(id int, req dto.UpdateShipmentRequest) (*model.Shipment, error) {
+	shipment, err := su.shipmentRepo.FindById(id)
+	if err != nil {
+		return nil, ErrShipmentNotFound
+	}
+
+	if req.Carrier != nil {
+		shipment.Carrier = strings.TrimSpace(*req.Carrier)
+	}
+
+	if req.TrackingNumber != nil {
+		shipment.TrackingNumber = strings.TrimSpace(*req.TrackingNumber)
+	}
+
+	return su.shipmentRepo.UpdateShipment(shipment)
+}
+
+// AddEvent records a status reported by the carrier. The order follows its
+// shipments to shipped and delivered. A shipment only moves forward, and
+// takes no events once delivered.
+func (su *shipmentUsecase) AddEvent(id int, req dto.CreateShipmentEventRequest) (*model.Shipment, error) {
+	_, err := su.shipmentRepo.FindById(id)
+	if err != nil {
+		return nil, ErrShipmentNotFound
+	}
+
+	event := &model.ShipmentEvent{
+		Status:      req.Status,
+		Location:    strings.TrimSpace(req.Location),
+		Description: strings.TrimSpace(req.Description),
+		OccurredAt:  time.Now(),
+	}
+
+	if req.OccurredAt != nil {
+		event.OccurredAt = *req.OccurredAt
+	}
+
+	shipment, err := su.shipmentRepo.AddEvent(id, event)
+	if errors.Is(err, repository.ErrShipmentDelivered) {
+		return nil, ErrShipmentDelivered
+	} else if errors.Is(err, repository.ErrShipmentStatus) {
+		return nil, ErrShipmentStatus
+	} else if err != nil {
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+// GetQueue lists the paid orders waiting for physical items to be shipped,
+// longest waiting first, with what is left to ship on each.
+func (su *shipmentUsecase) GetQueue(filter dto.FulfillmentQueueFilterRequest) ([]dto.FulfillmentQueueEntry, *dto.Paging, error) {
+	paging := &dto.Paging{Page: filter.Page, Limit: filter.Limit}
+	if paging.Page == 0 {
+		paging.Page = 1
+	}
+	if paging.Limit == 0 {
+		paging.Limit = defaultPageLimit
+	}
+
+	now := time.Now()
+
+	var paidAfter, paidBefore *time.Time
+	if filter.MaxAgeHours > 0 {
+		after := now.Add(-time.Duration(filter.MaxAgeHours) * time.Hour)
+		paidAfter = &after
+	}
+	if filter.MinAgeHours > 0 {
+		before := now.Add(-time.Duration(filter.MinAgeHours) * time.Hour)
+		paidBefore = &before
+	}
+
+	orders, total, err := su.shipmentRepo.FindQueue(paidAfter, paidBefore, paging.Limit, (paging.Page-1)*paging.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var orderIds []int
+	for _, order := range orders {
+		orderIds = append(orderIds, order.ID)
+	}
+
+	shipped, err := su.shipmentRepo.ShippedQuantities(orderIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := []dto.FulfillmentQueueEntry{}
+	for _, order := range orders {
+		entry := dto.FulfillmentQueueEntry{Order: order, Unfulfilled: []dto.UnfulfilledItem{}}
+		if order.PaidAt != nil {
+			entry.AgeHours = int(now.Sub(*order.PaidAt).Hours())
+		}
+
+		for _, item := range order.Items {
+			left := item.Qty - shipped[item.ID]
+			if !item.NeedsShipping() || left <= 0 {
+				continue
+			}
+
+			entry.Unfulfilled = append(entry.Unfulfilled, dto.UnfulfilledItem{
+				OrderItemID: item.ID,
+				VariantID:   item.VariantID,
+				Sku:         item.Sku,
+				Qty:         left,
+			})
+		}
+
+		entries = append(entries, entry)
+	}
+
+	paging.TotalRows = total
+	paging.TotalPages = int((total + int64(paging.Limit) - 1) / int64(paging.Limit))
+
+	return entries, paging, nil
+}
+
+func NewShipmentUsecase(shipmentRepo repository.ShipmentRepository, orderRepo repository.OrderRepository) *shipmentUsecase {
+	return &shipmentUsecase{
+		shipmentRepo: shipmentRepo,
+		orderRepo:    orderRepo,
+	}
+}
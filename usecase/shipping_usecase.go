@@ -0,0 +1,355 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type ShippingUsecase interface {
+	CreateZone(req dto.CreateShippingZoneRequest) (*model.ShippingZone, error)
+	GetZones() ([]model.ShippingZone, error)
+	GetZoneById(id int) (*model.ShippingZone, error)
+	UpdateZone(id int, req dto.UpdateShippingZoneRequest) (*model.ShippingZone, error)
+	DeleteZone(id int) error
+	CreateMethod(req dto.CreateShippingMethodRequest) (*model.ShippingMethod, error)
+	GetMethods(role string) ([]model.ShippingMethod, error)
+	GetMethodById(id int, role string) (*model.ShippingMethod, error)
+	UpdateMethod(id int, req dto.UpdateShippingMethodRequest) (*model.ShippingMethod, error)
+	DeleteMethod(id int) error
+	Quotes(items []model.Item, country string, subtotal model.Money, converter *MoneyConverter) ([]model.ShippingQuote, error)
+}
+
+var (
+	ErrShippingZoneNotFound      = errors.New("shipping zone not found")
+	ErrDuplicateShippingZone     = errors.New("a shipping zone with this name already exists")
+	ErrShippingZoneInUse         = errors.New("shipping zone is still used by shipping methods")
+	ErrShippingMethodNotFound    = errors.New("shipping method not found")
+	ErrShippingRates             = errors.New("weight based shipping methods need at least one weight bracket and brackets must have distinct weights")
+	ErrShippingMethodUnavailable = errors.New("the chosen shipping method cannot ship this cart")
+)
+
+type shippingUsecase struct {
+	shippingRepo repository.ShippingRepository
+}
+
+func (su *shippingUsecase) CreateZone(req dto.CreateShippingZoneRequest) (*model.ShippingZone, error) {
+	zone := &model.ShippingZone{
+		Name:      strings.TrimSpace(req.Name),
+		Countries: zoneCountries(req.Countries),
+	}
+
+	err := su.checkZoneName(zone.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return su.shippingRepo.CreateZone(zone)
+}
+
+func (su *shippingUsecase) GetZones() ([]model.ShippingZone, error) {
+	zones, err := su.shippingRepo.FindAllZones()
+	if err != nil {
+		return nil, err
+	}
+
+	if zones == nil {
+		zones = []model.ShippingZone{}
+	}
+
+	return zones, nil
+}
+
+func (su *shippingUsecase) GetZoneById(id int) (*model.ShippingZone, error) {
+	zone, err := su.shippingRepo.FindZoneById(id)
+	if err != nil {
+		return nil, ErrShippingZoneNotFound
+	}
+
+	return zone, nil
+}
+
+func (su *shippingUsecase) UpdateZone(id int, req dto.UpdateShippingZoneRequest) (*model.ShippingZone, error) {
+	zone, err := su.shippingRepo.FindZoneById(id)
+	if err != nil {
+		return nil, ErrShippingZoneNotFound
+	}
+
+	if req.Name != nil {
+		zone.Name = strings.TrimSpace(*req.Name)
+
+		err = su.checkZoneName(zone.Name, zone.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Countries != nil {
+		zone.Countries = zoneCountries(*req.Countries)
+	}
+
+	return su.shippingRepo.UpdateZone(zone, req.Countries != nil)
+}
+
+// DeleteZone refuses to delete a zone methods still deliver to, since those
+// methods would silently start delivering everywhere.
+func (su *shippingUsecase) DeleteZone(id int) error {
+	_, err := su.shippingRepo.FindZoneById(id)
+	if err != nil {
+		return ErrShippingZoneNotFound
+	}
+
+	count, err := su.shippingRepo.CountMethodsInZone(id)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrShippingZoneInUse
+	}
+
+	return su.shippingRepo.DeleteZone(id)
+}
+
+func (su *shippingUsecase) CreateMethod(req dto.CreateShippingMethodRequest) (*model.ShippingMethod, error) {
+	method := &model.ShippingMethod{
+		Name:          strings.TrimSpace(req.Name),
+		ZoneID:        req.ZoneID,
+		Type:          req.Type,
+		FlatRate:      req.FlatRate,
+		Rates:         shippingRates(req.Rates),
+		FreeOver:      req.FreeOver,
+		EstimatedDays: strings.TrimSpace(req.EstimatedDays),
+		IsActive:      true,
+	}
+
+	if req.IsActive != nil {
+		method.IsActive = *req.IsActive
+	}
+
+	err := su.validateMethod(method)
+	if err != nil {
+		return nil, err
+	}
+
+	return su.shippingRepo.CreateMethod(method)
+}
+
+// GetMethods lists the shipping methods customers can choose from. Admins
+// also see the inactive ones.
+func (su *shippingUsecase) GetMethods(role string) ([]model.ShippingMethod, error) {
+	methods, err := su.shippingRepo.FindAllMethods(role != "admin")
+	if err != nil {
+		return nil, err
+	}
+
+	if methods == nil {
+		methods = []model.ShippingMethod{}
+	}
+
+	return methods, nil
+}
+
+func (su *shippingUsecase) GetMethodById(id int, role string) (*model.ShippingMethod, error) {
+	method, err := su.shippingRepo.FindMethodById(id)
+	if err != nil || (!method.IsActive && role != "admin") {
+		return nil, ErrShippingMethodNotFound
+	}
+
+	return method, nil
+}
+
+func (su *shippingUsecase) UpdateMethod(id int, req dto.UpdateShippingMethodRequest) (*model.ShippingMethod, error) {
+	method, err := su.shippingRepo.FindMethodById(id)
+	if err != nil {
+		return nil, ErrShippingMethodNotFound
+	}
+
+	if req.Name != nil {
+		method.Name = strings.TrimSpace(*req.Name)
+	}
+
+	if req.ZoneID != nil {
+		method.ZoneID = req.ZoneID
+		if *req.ZoneID == 0 {
+			method.ZoneID = nil
+		}
+	}
+
+	if req.Type != nil {
+		method.Type = *req.Type
+	}
+
+	if req.FlatRate != nil {
+		method.FlatRate = *req.FlatRate
+	}
+
+	if req.Rates != nil {
+		method.Rates = shippingRates(*req.Rates)
+	}
+
+	if req.FreeOver != nil {
+		method.FreeOver = req.FreeOver
+		if *req.FreeOver == 0 {
+			method.FreeOver = nil
+		}
+	}
+
+	if req.EstimatedDays != nil {
+		method.EstimatedDays = strings.TrimSpace(*req.EstimatedDays)
+	}
+
+	if req.IsActive != nil {
+		method.IsActive = *req.IsActive
+	}
+
+	replaceRates := req.Rates != nil || method.Type != model.ShippingTypeWeight
+	err = su.validateMethod(method)
+	if err != nil {
+		return nil, err
+	}
+
+	return su.shippingRepo.UpdateMethod(method, replaceRates)
+}
+
+func (su *shippingUsecase) DeleteMethod(id int) error {
+	_, err := su.shippingRepo.FindMethodById(id)
+	if err != nil {
+		return ErrShippingMethodNotFound
+	}
+
+	return su.shippingRepo.DeleteMethod(id)
+}
+
+// Quotes prices every active shipping method that can take the physical
+// items of a cart to the country, in the converter's currency. Subtotal is
+// what the items come to after discounts and decides free shipping. Carts
+// with nothing to ship get no quotes.
+func (su *shippingUsecase) Quotes(items []model.Item, country string, subtotal model.Money, converter *MoneyConverter) ([]model.ShippingQuote, error) {
+	quotes := []model.ShippingQuote{}
+
+	weight := 0
+	physical := false
+	for _, item := range items {
+		if item.NeedsShipping() {
+			physical = true
+			weight += item.Weight * item.Qty
+		}
+	}
+
+	if !physical {
+		return quotes, nil
+	}
+
+	methods, err := su.shippingRepo.FindAllMethods(true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, method := range methods {
+		if !helper.ShipsTo(method, normalizeCountry(country)) {
+			continue
+		}
+
+		cost, ok := helper.ShippingCost(method, weight)
+		if !ok {
+			continue
+		}
+
+		quote := model.ShippingQuote{
+			MethodId:      method.ID,
+			Name:          method.Name,
+			EstimatedDays: method.EstimatedDays,
+			Amount:        converter.FromBase(cost),
+		}
+
+		if method.FreeOver != nil && subtotal.Amount >= converter.FromBase(*method.FreeOver).Amount {
+			quote.Amount = model.NewMoney(0, converter.Currency())
+			quote.Free = true
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, nil
+}
+
+// validateMethod checks the zone exists and that weight methods have
+// distinct brackets. Flat methods drop any brackets sent along.
+func (su *shippingUsecase) validateMethod(method *model.ShippingMethod) error {
+	if method.ZoneID != nil {
+		zone, err := su.shippingRepo.FindZoneById(*method.ZoneID)
+		if err != nil {
+			return ErrShippingZoneNotFound
+		}
+		method.Zone = zone
+	} else {
+		method.Zone = nil
+	}
+
+	if method.Type != model.ShippingTypeWeight {
+		method.Rates = nil
+		return nil
+	}
+
+	if len(method.Rates) == 0 {
+		return ErrShippingRates
+	}
+
+	seen := make(map[int]bool)
+	for _, rate := range method.Rates {
+		if seen[rate.MaxWeight] {
+			return ErrShippingRates
+		}
+		seen[rate.MaxWeight] = true
+	}
+
+	return nil
+}
+
+func (su *shippingUsecase) checkZoneName(name string, excludeId int) error {
+	exists, err := su.shippingRepo.ZoneNameExists(name, excludeId)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrDuplicateShippingZone
+	}
+
+	return nil
+}
+
+func zoneCountries(countries []string) []model.ShippingZoneCountry {
+	zoneCountries := []model.ShippingZoneCountry{}
+	seen := make(map[string]bool)
+
+	for _, country := range countries {
+		country = normalizeCountry(country)
+		if seen[country] {
+			continue
+		}
+		seen[country] = true
+
+		zoneCountries = append(zoneCountries, model.ShippingZoneCountry{Country: country})
+	}
+
+	return zoneCountries
+}
+
+func shippingRates(reqRates []dto.ShippingRateRequest) []model.ShippingRate {
+	rates := []model.ShippingRate{}
+	for _, reqRate := range reqRates {
+		rates = append(rates, model.ShippingRate{MaxWeight: reqRate.MaxWeight, Price: reqRate.Price})
+	}
+
+	return rates
+}
+
+func NewShippingUsecase(shippingRepo repository.ShippingRepository) *shippingUsecase {
+	return &shippingUsecase{shippingRepo: shippingRepo}
+}
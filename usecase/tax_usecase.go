@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type TaxUsecase interface {
+	Create(req dto.CreateTaxRuleRequest) (*model.TaxRule, error)
+	GetAll(filter dto.TaxRuleFilterRequest) ([]model.TaxRule, error)
+	GetById(id int) (*model.TaxRule, error)
+	Update(id int, req dto.UpdateTaxRuleRequest) (*model.TaxRule, error)
+	Delete(id int) error
+	DefaultCountry() string
+	Taxes(country, region string, items []model.Item, net map[int]int64, currency string) ([]model.TaxLine, error)
+}
+
+var (
+	ErrTaxRuleNotFound  = errors.New("tax rule not found")
+	ErrDuplicateTaxRule = errors.New("a tax rule for this country, region and product class already exists")
+)
+
+type taxUsecase struct {
+	taxRepo        repository.TaxRepository
+	defaultCountry string
+}
+
+func (tu *taxUsecase) Create(req dto.CreateTaxRuleRequest) (*model.TaxRule, error) {
+	rule := &model.TaxRule{
+		Country:      normalizeCountry(req.Country),
+		Region:       normalizeRegion(req.Region),
+		ProductClass: req.ProductClass,
+		Name:         req.Name,
+		Rate:         req.Rate,
+		Inclusive:    req.Inclusive,
+	}
+
+	err := tu.checkScope(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return tu.taxRepo.CreateRule(rule)
+}
+
+func (tu *taxUsecase) GetAll(filter dto.TaxRuleFilterRequest) ([]model.TaxRule, error) {
+	rules, err := tu.taxRepo.FindAll(normalizeCountry(filter.Country))
+	if err != nil {
+		return nil, err
+	}
+
+	if rules == nil {
+		rules = []model.TaxRule{}
+	}
+
+	return rules, nil
+}
+
+func (tu *taxUsecase) GetById(id int) (*model.TaxRule, error) {
+	rule, err := tu.taxRepo.FindById(id)
+	if err != nil {
+		return nil, ErrTaxRuleNotFound
+	}
+
+	return rule, nil
+}
+
+func (tu *taxUsecase) Update(id int, req dto.UpdateTaxRuleRequest) (*model.TaxRule, error) {
+	rule, err := tu.taxRepo.FindById(id)
+	if err != nil {
+		return nil, ErrTaxRuleNotFound
+	}
+
+	if req.Country != nil {
+		rule.Country = normalizeCountry(*req.Country)
+	}
+
+	if req.Region != nil {
+		rule.Region = normalizeRegion(*req.Region)
+	}
+
+	if req.ProductClass != nil {
+		rule.ProductClass = *req.ProductClass
+	}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+
+	if req.Rate != nil {
+		rule.Rate = *req.Rate
+	}
+
+	if req.Inclusive != nil {
+		rule.Inclusive = *req.Inclusive
+	}
+
+	err = tu.checkScope(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return tu.taxRepo.UpdateRule(rule)
+}
+
+func (tu *taxUsecase) Delete(id int) error {
+	_, err := tu.taxRepo.FindById(id)
+	if err != nil {
+		return ErrTaxRuleNotFound
+	}
+
+	return tu.taxRepo.DeleteRule(id)
+}
+
+// DefaultCountry is where carts are taxed until the customer says otherwise.
+func (tu *taxUsecase) DefaultCountry() string {
+	return tu.defaultCountry
+}
+
+// Taxes works out the tax line of every cart item shipped to the country and
+// region, taxing what is left to pay for the item after discounts. Items no
+// rule applies to are not taxed and get no line.
+func (tu *taxUsecase) Taxes(country, region string, items []model.Item, net map[int]int64, currency string) ([]model.TaxLine, error) {
+	lines := []model.TaxLine{}
+	if len(items) == 0 {
+		return lines, nil
+	}
+
+	rules, err := tu.taxRepo.FindByCountry(normalizeCountry(country))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		rule := helper.MatchTaxRule(rules, normalizeRegion(region), model.TaxClassOf(item.Format))
+		if rule == nil {
+			continue
+		}
+
+		taxable := net[item.VariantId]
+		lines = append(lines, model.TaxLine{
+			VariantId: item.VariantId,
+			Name:      rule.Name,
+			Rate:      rule.Rate,
+			Inclusive: rule.Inclusive,
+			Taxable:   model.NewMoney(taxable, currency),
+			Amount:    model.NewMoney(helper.CalculateTax(taxable, *rule), currency),
+		})
+	}
+
+	return lines, nil
+}
+
+// checkScope makes sure no other rule covers the same country, region and
+// product class, which would make the matching rule ambiguous.
+func (tu *taxUsecase) checkScope(rule *model.TaxRule) error {
+	exists, err := tu.taxRepo.ScopeExists(rule.Country, rule.Region, rule.ProductClass, rule.ID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrDuplicateTaxRule
+	}
+
+	return nil
+}
+
+func normalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
+
+func normalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
+
+func NewTaxUsecase(taxRepo repository.TaxRepository, defaultCountry string) *taxUsecase {
+	return &taxUsecase{taxRepo: taxRepo, defaultCountry: defaultCountry}
+}
@@ -0,0 +1,331 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/helper"
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type VariantUsecase interface {
+	Create(bookId int, req dto.CreateVariantRequest) (*model.BookVariant, error)
+	GetByBook(bookId int, currency string) ([]dto.VariantResponse, error)
+	GetById(id int) (*model.BookVariant, error)
+	Update(bookId, id int, req dto.UpdateVariantRequest) (*model.BookVariant, error)
+	Delete(bookId, id int) error
+	BackfillDefaultVariants() error
+}
+
+var (
+	ErrBookNotFound      = errors.New("book not found")
+	ErrVariantNotFound   = errors.New("book variant not found")
+	ErrDuplicateSku      = errors.New("a book variant with this sku already exists")
+	ErrLastVariant       = errors.New("a book must keep at least one variant")
+	ErrInsufficientStock = errors.New("not enough stock for the requested quantity")
+	ErrVariantPrices     = errors.New("prices must list each currency other than the base currency at most once")
+)
+
+var formatSkuCodes = map[string]string{
+	model.FormatHardcover: "HC",
+	model.FormatPaperback: "PB",
+	model.FormatEbook:     "EB",
+	model.FormatAudiobook: "AB",
+}
+
+type variantUsecase struct {
+	variantRepo     repository.VariantRepository
+	bookRepo        repository.BookRepository
+	currencyUsecase CurrencyUsecase
+	backfillStock   int
+}
+
+func (vu *variantUsecase) Create(bookId int, req dto.CreateVariantRequest) (*model.BookVariant, error) {
+	book, err := vu.bookRepo.FindById(bookId)
+	if err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	variant, err := newVariant(vu.variantRepo, book, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	variant.BookID = bookId
+
+	create, err := vu.variantRepo.CreateVariant(variant)
+	if err != nil {
+		return nil, err
+	}
+
+	return create, nil
+}
+
+func (vu *variantUsecase) GetByBook(bookId int, currency string) ([]dto.VariantResponse, error) {
+	_, err := vu.bookRepo.FindById(bookId)
+	if err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	variants, err := vu.variantRepo.FindByBook(bookId)
+	if err != nil {
+		return nil, err
+	}
+
+	converter, err := vu.currencyUsecase.Converter(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.VariantResponse{}
+	for _, variant := range variants {
+		response = append(response, toVariantResponse(variant, converter))
+	}
+
+	return response, nil
+}
+
+func (vu *variantUsecase) GetById(id int) (*model.BookVariant, error) {
+	variant, err := vu.variantRepo.FindById(id)
+	if errors.Is(err, repository.ErrVariantNotFound) {
+		return nil, ErrVariantNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+func (vu *variantUsecase) Update(bookId, id int, req dto.UpdateVariantRequest) (*model.BookVariant, error) {
+	variant, err := vu.variantRepo.FindById(id)
+	if err != nil || variant.BookID != bookId {
+		return nil, ErrVariantNotFound
+	}
+
+	if req.Sku != nil {
+		sku := strings.ToUpper(*req.Sku)
+		exists, err := vu.variantRepo.SkuExists(sku, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			return nil, ErrDuplicateSku
+		}
+		variant.Sku = sku
+	}
+
+	if req.Format != nil {
+		variant.Format = *req.Format
+	}
+
+	if req.Edition != nil {
+		variant.Edition = *req.Edition
+	}
+
+	if req.Price != nil {
+		variant.Price = model.NewMoney(*req.Price, vu.currencyUsecase.BaseCurrency())
+	}
+
+	if req.Prices != nil {
+		prices, err := variantPrices(*req.Prices, vu.currencyUsecase.BaseCurrency())
+		if err != nil {
+			return nil, err
+		}
+
+		err = vu.variantRepo.ReplacePrices(id, prices)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Stock != nil {
+		variant.Stock = *req.Stock
+	}
+
+	if req.PageCount != nil {
+		variant.PageCount = *req.PageCount
+	}
+
+	if req.Weight != nil {
+		variant.Weight = *req.Weight
+	}
+
+	update, err := vu.variantRepo.UpdateVariant(id, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	return update, nil
+}
+
+func (vu *variantUsecase) Delete(bookId, id int) error {
+	variant, err := vu.variantRepo.FindById(id)
+	if err != nil || variant.BookID != bookId {
+		return ErrVariantNotFound
+	}
+
+	variants, err := vu.variantRepo.FindByBook(bookId)
+	if err != nil {
+		return err
+	}
+
+	if len(variants) <= 1 {
+		return ErrLastVariant
+	}
+
+	return vu.variantRepo.DeleteVariant(id)
+}
+
+// BackfillDefaultVariants gives every book stored before variants existed a
+// paperback variant carrying the book price, so it stays on sale. The
+// variant takes over the stock of the book's legacy stock column when there
+// is one, and the configured backfill stock otherwise.
+func (vu *variantUsecase) BackfillDefaultVariants() error {
+	books, err := vu.variantRepo.FindBooksWithoutVariants()
+	if err != nil {
+		return err
+	}
+
+	bookIds := make([]int, 0, len(books))
+	for _, book := range books {
+		bookIds = append(bookIds, book.Id)
+	}
+
+	legacyStock, err := vu.variantRepo.FindLegacyStock(bookIds)
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		stock, ok := legacyStock[book.Id]
+		if !ok {
+			stock = vu.backfillStock
+		}
+
+		variant, err := newVariant(vu.variantRepo, &book, dto.CreateVariantRequest{
+			Format: model.FormatPaperback,
+			Price:  book.Price.Amount,
+			Stock:  stock,
+		}, nil)
+		if err != nil {
+			return err
+		}
+		variant.BookID = book.Id
+		variant.Price = book.Price
+
+		_, err = vu.variantRepo.CreateVariant(variant)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newVariant builds a variant from the request, generating a SKU from the
+// book title and format when none is given, e.g. DUNE-HC. The price is in the
+// currency of the book price. reserved holds SKUs handed out earlier in the
+// same batch that are not stored yet.
+func newVariant(variantRepo repository.VariantRepository, book *model.Book, req dto.CreateVariantRequest, reserved map[string]bool) (*model.BookVariant, error) {
+	sku := strings.ToUpper(req.Sku)
+	if sku == "" {
+		base := strings.ToUpper(helper.Slugify(book.Title))
+		if runes := []rune(base); len(runes) > 40 {
+			base = strings.TrimSuffix(string(runes[:40]), "-")
+		}
+
+		var err error
+		sku, err = helper.UniqueSlug(base+"-"+formatSkuCodes[req.Format], func(candidate string) (bool, error) {
+			if reserved[candidate] {
+				return true, nil
+			}
+			return variantRepo.SkuExists(candidate, 0)
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		exists, err := variantRepo.SkuExists(sku, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists || reserved[sku] {
+			return nil, ErrDuplicateSku
+		}
+	}
+
+	prices, err := variantPrices(req.Prices, book.Price.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if reserved != nil {
+		reserved[sku] = true
+	}
+
+	return &model.BookVariant{
+		Sku:       sku,
+		Format:    req.Format,
+		Edition:   req.Edition,
+		Price:     model.NewMoney(req.Price, book.Price.Currency),
+		Prices:    prices,
+		Stock:     req.Stock,
+		PageCount: req.PageCount,
+		Weight:    req.Weight,
+	}, nil
+}
+
+// variantPrices turns the prices fixed per currency into rows, refusing the
+// base currency, whose price is the variant price itself, and duplicates.
+func variantPrices(reqPrices []dto.PriceRequest, baseCurrency string) ([]model.VariantPrice, error) {
+	prices := []model.VariantPrice{}
+	seen := make(map[string]bool)
+
+	for _, reqPrice := range reqPrices {
+		if reqPrice.Currency == baseCurrency || seen[reqPrice.Currency] {
+			return nil, ErrVariantPrices
+		}
+		seen[reqPrice.Currency] = true
+
+		prices = append(prices, model.VariantPrice{Currency: reqPrice.Currency, Amount: reqPrice.Amount})
+	}
+
+	return prices, nil
+}
+
+func toVariantResponse(variant model.BookVariant, converter *MoneyConverter) dto.VariantResponse {
+	prices := []model.Money{}
+	for _, price := range variant.Prices {
+		prices = append(prices, model.NewMoney(price.Amount, price.Currency))
+	}
+
+	return dto.VariantResponse{
+		ID:        variant.ID,
+		BookID:    variant.BookID,
+		Sku:       variant.Sku,
+		Format:    variant.Format,
+		Edition:   variant.Edition,
+		Price:     converter.VariantPrice(variant),
+		Prices:    prices,
+		Stock:     variant.Stock,
+		InStock:   variant.IsDigital() || variant.Stock > 0,
+		PageCount: variant.PageCount,
+		Weight:    variant.Weight,
+		CreatedAt: variant.CreatedAt,
+		UpdatedAt: variant.UpdatedAt,
+	}
+}
+
+// NewVariantUsecase takes the stock given to the variants of books that had
+// none, when they carry no stock of their own.
+func NewVariantUsecase(variantRepo repository.VariantRepository, bookRepo repository.BookRepository, currencyUsecase CurrencyUsecase, backfillStock int) *variantUsecase {
+	return &variantUsecase{
+		variantRepo:     variantRepo,
+		bookRepo:        bookRepo,
+		currencyUsecase: currencyUsecase,
+		backfillStock:   backfillStock,
+	}
+}
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// legacyVariantRepo serves books stored before variants existed and keeps
+// the variants created for them in memory.
+type legacyVariantRepo struct {
+	repository.VariantRepository
+	books       []model.Book
+	legacyStock map[int]int
+	created     []model.BookVariant
+}
+
+func (r *legacyVariantRepo) FindBooksWithoutVariants() ([]model.Book, error) {
+	return r.books, nil
+}
+
+func (r *legacyVariantRepo) FindLegacyStock(bookIds []int) (map[int]int, error) {
+	return r.legacyStock, nil
+}
+
+func (r *legacyVariantRepo) SkuExists(sku string, excludeId int) (bool, error) {
+	for _, variant := range r.created {
+		if variant.Sku == sku {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *legacyVariantRepo) CreateVariant(variant *model.BookVariant) (*model.BookVariant, error) {
+	r.created = append(r.created, *variant)
+	return variant, nil
+}
+
+func TestBackfillDefaultVariantsKeepsCatalogueBuyable(t *testing.T) {
+	tests := []struct {
+		name          string
+		legacyStock   map[int]int
+		backfillStock int
+		want          map[int]int
+	}{
+		{
+			name:          "no legacy stock column",
+			legacyStock:   map[int]int{},
+			backfillStock: 100,
+			want:          map[int]int{1: 100, 2: 100},
+		},
+		{
+			name:          "legacy stock column",
+			legacyStock:   map[int]int{1: 7, 2: 3},
+			backfillStock: 100,
+			want:          map[int]int{1: 7, 2: 3},
+		},
+		{
+			name:          "legacy stock for some books",
+			legacyStock:   map[int]int{2: 12},
+			backfillStock: 50,
+			want:          map[int]int{1: 50, 2: 12},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &legacyVariantRepo{
+				books: []model.Book{
+					{Id: 1, Title: "Dune", Price: model.NewMoney(150000, "IDR")},
+					{Id: 2, Title: "Dune", Price: model.NewMoney(90000, "IDR")},
+				},
+				legacyStock: tt.legacyStock,
+			}
+
+			err := NewVariantUsecase(repo, nil, nil, tt.backfillStock).BackfillDefaultVariants()
+			if err != nil {
+				t.Fatalf("BackfillDefaultVariants error: %v", err)
+			}
+
+			if len(repo.created) != len(repo.books) {
+				t.Fatalf("created %d variants, want one per book (%d)", len(repo.created), len(repo.books))
+			}
+
+			for _, variant := range repo.created {
+				if variant.Stock != tt.want[variant.BookID] {
+					t.Errorf("book %d variant stock = %d, want %d", variant.BookID, variant.Stock, tt.want[variant.BookID])
+				}
+
+				// The cart and checkout refuse physical variants with less
+				// stock than the quantity ordered.
+				if !variant.IsDigital() && variant.Stock < 1 {
+					t.Errorf("book %d cannot be bought after the backfill", variant.BookID)
+				}
+			}
+
+			if repo.created[0].Sku == repo.created[1].Sku {
+				t.Errorf("books with the same title got the same sku %q", repo.created[0].Sku)
+			}
+		})
+	}
+}
+
+// recordingConnector is a database/sql connector that answers every query
+// with a single row and records every statement it is sent, so what a
+// repository writes can be checked without a database.
+type recordingConnector struct {
+	columns    []string
+	row        []driver.Value
+	statements []recordedStatement
+}
+
+type recordedStatement struct {
+	query string
+	args  []driver.Value
+}
+
+func (c *recordingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &recordingConn{connector: c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver {
+	return nil
+}
+
+type recordingConn struct {
+	connector *recordingConnector
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{connector: c.connector, query: query}, nil
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *recordingConn) Commit() error {
+	return nil
+}
+
+func (c *recordingConn) Rollback() error {
+	return nil
+}
+
+type recordingStmt struct {
+	connector *recordingConnector
+	query     string
+}
+
+func (s *recordingStmt) Close() error {
+	return nil
+}
+
+func (s *recordingStmt) NumInput() int {
+	return -1
+}
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.connector.statements = append(s.connector.statements, recordedStatement{s.query, args})
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.connector.statements = append(s.connector.statements, recordedStatement{s.query, args})
+	return &recordingRows{columns: s.connector.columns, row: s.connector.row}, nil
+}
+
+type recordingRows struct {
+	columns []string
+	row     []driver.Value
+	read    bool
+}
+
+func (r *recordingRows) Columns() []string {
+	return r.columns
+}
+
+func (r *recordingRows) Close() error {
+	return nil
+}
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+
+	r.read = true
+	copy(dest, r.row)
+	return nil
+}
+
+// storedVariantRepo finds a variant kept in memory and saves it through the
+// real repository.
+type storedVariantRepo struct {
+	repository.VariantRepository
+	variant model.BookVariant
+}
+
+func (r *storedVariantRepo) FindById(id int) (*model.BookVariant, error) {
+	variant := r.variant
+	return &variant, nil
+}
+
+func TestUpdateVariantSavesWeight(t *testing.T) {
+	connector := &recordingConnector{
+		columns: []string{"id", "book_id", "weight"},
+		row:     []driver.Value{int64(1), int64(1), int64(200)},
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(connector)}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+
+	repo := &storedVariantRepo{
+		VariantRepository: repository.NewVariantRepository(db),
+		variant:           model.BookVariant{ID: 1, BookID: 1, Weight: 200},
+	}
+
+	weight := 750
+	variant, err := NewVariantUsecase(repo, nil, nil, 0).Update(1, 1, dto.UpdateVariantRequest{Weight: &weight})
+	if err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+
+	if variant.Weight != weight {
+		t.Errorf("returned weight = %d, want %d", variant.Weight, weight)
+	}
+
+	i := slices.IndexFunc(connector.statements, func(statement recordedStatement) bool {
+		return strings.HasPrefix(statement.query, "UPDATE")
+	})
+	if i < 0 {
+		t.Fatalf("no UPDATE among %v", connector.statements)
+	}
+
+	update := connector.statements[i]
+	if !strings.Contains(update.query, `"weight"`) || !slices.Contains(update.args, driver.Value(int64(weight))) {
+		t.Errorf("UPDATE does not save the weight: %s %v", update.query, update.args)
+	}
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mhmmmdrivaldhi/go-book-api/model"
+	"github.com/mhmmmdrivaldhi/go-book-api/model/dto"
+	"github.com/mhmmmdrivaldhi/go-book-api/repository"
+)
+
+type WishlistUsecase interface {
+	GetByUser(userId int, currency string) ([]dto.WishlistItemResponse, error)
+	Add(userId int, req dto.AddWishlistRequest, currency string) ([]dto.WishlistItemResponse, error)
+	Remove(userId, bookId int) error
+	MoveToCart(ctx context.Context, userId, bookId int, req dto.MoveToCartRequest, currency string) (*model.Cart, error)
+}
+
+var ErrWishlistItemNotFound = errors.New("book is not on your wishlist")
+
+type wishlistUsecase struct {
+	wishlistRepo   repository.WishlistRepository
+	bookUsecase    BookUsecase
+	variantUsecase VariantUsecase
+	cartUsecase    CartUsecase
+}
+
+func (wu *wishlistUsecase) GetByUser(userId int, currency string) ([]dto.WishlistItemResponse, error) {
+	items, err := wu.wishlistRepo.FindByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var bookIds []int
+	for _, item := range items {
+		bookIds = append(bookIds, item.BookID)
+	}
+
+	books, err := wu.bookUsecase.GetByIds(bookIds, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.WishlistItemResponse{}
+	for _, item := range items {
+		book, ok := books[item.BookID]
+		if !ok {
+			continue
+		}
+
+		response = append(response, dto.WishlistItemResponse{
+			Book:      book,
+			VariantID: item.VariantID,
+			AddedAt:   item.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+func (wu *wishlistUsecase) Add(userId int, req dto.AddWishlistRequest, currency string) ([]dto.WishlistItemResponse, error) {
+	_, err := wu.bookUsecase.GetById(req.BookID, currency)
+	if err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	if req.VariantID != nil {
+		variant, err := wu.variantUsecase.GetById(*req.VariantID)
+		if err != nil || variant.BookID != req.BookID {
+			return nil, ErrVariantNotFound
+		}
+	}
+
+	err = wu.wishlistRepo.SaveItem(&model.WishlistItem{
+		UserID:    userId,
+		BookID:    req.BookID,
+		VariantID: req.VariantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return wu.GetByUser(userId, currency)
+}
+
+func (wu *wishlistUsecase) Remove(userId, bookId int) error {
+	_, err := wu.wishlistRepo.FindItem(userId, bookId)
+	if err != nil {
+		return ErrWishlistItemNotFound
+	}
+
+	return wu.wishlistRepo.DeleteItem(userId, bookId)
+}
+
+// MoveToCart puts the wishlisted book in the cart and takes it off the
+// wishlist. The variant is, in order: the one in the request, the one saved
+// with the wishlist item, or the first variant that is in stock.
+func (wu *wishlistUsecase) MoveToCart(ctx context.Context, userId, bookId int, req dto.MoveToCartRequest, currency string) (*model.Cart, error) {
+	item, err := wu.wishlistRepo.FindItem(userId, bookId)
+	if err != nil {
+		return nil, ErrWishlistItemNotFound
+	}
+
+	variantId := 0
+	switch {
+	case req.VariantID != nil:
+		variantId = *req.VariantID
+	case item.VariantID != nil:
+		variantId = *item.VariantID
+	default:
+		variants, err := wu.variantUsecase.GetByBook(bookId, currency)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, variant := range variants {
+			if variant.InStock {
+				variantId = variant.ID
+				break
+			}
+		}
+
+		if variantId == 0 {
+			return nil, ErrInsufficientStock
+		}
+	}
+
+	variant, err := wu.variantUsecase.GetById(variantId)
+	if err != nil || variant.BookID != bookId {
+		return nil, ErrVariantNotFound
+	}
+
+	qty := req.Qty
+	if qty == 0 {
+		qty = 1
+	}
+
+	cart, err := wu.cartUsecase.AddToCart(ctx, model.CartOwner{UserId: userId}, model.Item{VariantId: variantId, Qty: qty}, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	err = wu.wishlistRepo.DeleteItem(userId, bookId)
+	if err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+func NewWishlistUsecase(wishlistRepo repository.WishlistRepository, bookUsecase BookUsecase, variantUsecase VariantUsecase, cartUsecase CartUsecase) *wishlistUsecase {
+	return &wishlistUsecase{
+		wishlistRepo:   wishlistRepo,
+		bookUsecase:    bookUsecase,
+		variantUsecase: variantUsecase,
+		cartUsecase:    cartUsecase,
+	}
+}